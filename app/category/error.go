@@ -0,0 +1,46 @@
+package category
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+)
+
+const (
+	CategoryNotFoundErrCode   = 30001
+	InvalidCategoryRequest    = 30002
+	CategorySlugIsRequired    = 30003
+	InvalidCategorySlug       = 30004
+	CategoryNameIsRequired    = 30005
+	ParentCategoryNotFound    = 30006
+	CategorySlugAlreadyExists = 30007
+	CategoryHasChildren       = 30008
+	CategoryIsInUse           = 30009
+	CategoryCycle             = 30010
+)
+
+// Errors returned by repositories, they are mapped to bags by the service.
+var (
+	ErrSlugAlreadyExists = errors.New("category slug already exists")
+	ErrParentNotFound    = errors.New("parent category not found")
+	ErrCycle             = errors.New("category would be its own ancestor")
+	ErrHasChildren       = errors.New("category has children")
+	ErrInUse             = errors.New("category is in use by products")
+)
+
+func init() {
+	cerr.RegisterStatus(http.StatusNotFound,
+		CategoryNotFoundErrCode,
+	)
+	cerr.RegisterStatus(http.StatusUnprocessableEntity,
+		InvalidCategoryRequest,
+		ParentCategoryNotFound,
+		CategoryCycle,
+	)
+	cerr.RegisterStatus(http.StatusConflict,
+		CategorySlugAlreadyExists,
+		CategoryHasChildren,
+		CategoryIsInUse,
+	)
+}
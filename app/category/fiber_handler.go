@@ -0,0 +1,118 @@
+package category
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus"
+)
+
+type Handler interface {
+	SetupRoutes(fr fiber.Router)
+	GetCategory(c *fiber.Ctx) error
+	ListCategories(c *fiber.Ctx) error
+	CreateCategory(c *fiber.Ctx) error
+	UpdateCategory(c *fiber.Ctx) error
+	DeleteCategory(c *fiber.Ctx) error
+}
+
+type handler struct {
+	logger  *logrus.Logger
+	service Service
+}
+
+type NewHandlerOpts struct {
+	L *logrus.Logger
+	S Service
+}
+
+func NewHandler(opts *NewHandlerOpts) Handler {
+	return &handler{
+		logger:  opts.L,
+		service: opts.S,
+	}
+}
+
+func (h *handler) GetCategory(c *fiber.Ctx) error {
+	categoryID := c.Params("id")
+	h.logger.Infof("Get Category request arrived! Category ID: %s", categoryID)
+
+	category, err := h.service.GetCategory(c.Context(), categoryID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(category)
+}
+
+func (h *handler) ListCategories(c *fiber.Ctx) error {
+	h.logger.Infof("List Categories request arrived!")
+
+	categories, err := h.service.ListCategories(c.Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(categories)
+}
+
+func (h *handler) CreateCategory(c *fiber.Ctx) error {
+	h.logger.Infof("Create Category request arrived!")
+
+	var req CreateCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	category, err := h.service.CreateCategory(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(category)
+}
+
+func (h *handler) UpdateCategory(c *fiber.Ctx) error {
+	categoryID := c.Params("id")
+	h.logger.Infof("Update Category request arrived! Category ID: %s", categoryID)
+
+	var req UpdateCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	category, err := h.service.UpdateCategory(c.Context(), categoryID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(category)
+}
+
+func (h *handler) DeleteCategory(c *fiber.Ctx) error {
+	categoryID := c.Params("id")
+	h.logger.Infof("Delete Category request arrived! Category ID: %s", categoryID)
+
+	if err := h.service.DeleteCategory(c.Context(), categoryID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *handler) SetupRoutes(fr fiber.Router) {
+	categoriesGroup := fr.Group("/categories")
+
+	categoriesGroup.Get("/", h.ListCategories)
+	categoriesGroup.Get("/:id", h.GetCategory)
+	categoriesGroup.Post("/", h.CreateCategory)
+	categoriesGroup.Put("/:id", h.UpdateCategory)
+	categoriesGroup.Delete("/:id", h.DeleteCategory)
+}
@@ -0,0 +1,29 @@
+package category
+
+import "time"
+
+// Category is a node of the product taxonomy. Products refer to their
+// category by its slug, which is why the slug can not change once created.
+type Category struct {
+	ID        string
+	Slug      string
+	Name      string
+	ParentID  *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RootCategories are seeded by the categories migration, they are the
+// product types that used to be hard-coded.
+var RootCategories = []Category{
+	{Slug: "clothing", Name: "Clothing"},
+	{Slug: "shoes", Name: "Shoes"},
+	{Slug: "bag", Name: "Bag"},
+	{Slug: "watch", Name: "Watch"},
+	{Slug: "wallet", Name: "Wallet"},
+	{Slug: "glasses", Name: "Glasses"},
+	{Slug: "hat", Name: "Hat"},
+	{Slug: "jacket", Name: "Jacket"},
+	{Slug: "pants", Name: "Pants"},
+	{Slug: "shirt", Name: "Shirt"},
+}
@@ -0,0 +1,13 @@
+package category
+
+import "context"
+
+//go:generate mockgen -source=repository.go -destination=mock_repository.go -package=category
+type Repository interface {
+	GetCategoryByID(ctx context.Context, id string) (*Category, error)
+	ListCategories(ctx context.Context) ([]Category, error)
+	ExistingCategories(ctx context.Context, slugs []string) (map[string]bool, error)
+	CreateCategory(ctx context.Context, category *Category) (*Category, error)
+	UpdateCategory(ctx context.Context, category *Category) (*Category, error)
+	DeleteCategory(ctx context.Context, id string) error
+}
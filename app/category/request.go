@@ -0,0 +1,49 @@
+package category
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pact-cdc-example/product-service/pkg/validation"
+)
+
+const maxSlugLength = 255
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type CreateCategoryRequest struct {
+	Slug     string  `json:"slug"`
+	Name     string  `json:"name"`
+	ParentID *string `json:"parent_id"`
+}
+
+func (c CreateCategoryRequest) Validate() error {
+	v := validation.New()
+
+	if c.Slug == "" {
+		v.Add("slug", CategorySlugIsRequired, "Category slug is required.")
+	} else {
+		v.Check(len(c.Slug) <= maxSlugLength && slugPattern.MatchString(c.Slug), "slug",
+			InvalidCategorySlug,
+			"Category slug must consist of lowercase letters and digits separated by hyphens.")
+	}
+	v.Check(strings.TrimSpace(c.Name) != "", "name",
+		CategoryNameIsRequired, "Category name is required.")
+
+	return v.Err(InvalidCategoryRequest, "Category request is invalid.")
+}
+
+// UpdateCategoryRequest renames or moves a category, its slug is immutable.
+type UpdateCategoryRequest struct {
+	Name     string  `json:"name"`
+	ParentID *string `json:"parent_id"`
+}
+
+func (u UpdateCategoryRequest) Validate() error {
+	v := validation.New()
+
+	v.Check(strings.TrimSpace(u.Name) != "", "name",
+		CategoryNameIsRequired, "Category name is required.")
+
+	return v.Err(InvalidCategoryRequest, "Category request is invalid.")
+}
@@ -0,0 +1,62 @@
+package category
+
+import "time"
+
+type CategoryResponse struct {
+	ID        string             `json:"id"`
+	Slug      string             `json:"slug"`
+	Name      string             `json:"name"`
+	ParentID  *string            `json:"parent_id,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Children  []CategoryResponse `json:"children,omitempty"`
+}
+
+func NewCategoryResponse(category *Category) *CategoryResponse {
+	if category == nil {
+		return nil
+	}
+
+	return &CategoryResponse{
+		ID:        category.ID,
+		Slug:      category.Slug,
+		Name:      category.Name,
+		ParentID:  category.ParentID,
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+	}
+}
+
+type CategoryTreeResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+}
+
+// NewCategoryTreeResponse nests the categories under their parents. The
+// order of the categories is kept among siblings.
+func NewCategoryTreeResponse(categories []Category) *CategoryTreeResponse {
+	children := make(map[string][]*Category, len(categories))
+	var roots []*Category
+	for i := range categories {
+		c := &categories[i]
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var build func(nodes []*Category) []CategoryResponse
+	build = func(nodes []*Category) []CategoryResponse {
+		responses := make([]CategoryResponse, 0, len(nodes))
+		for _, node := range nodes {
+			response := NewCategoryResponse(node)
+			response.Children = build(children[node.ID])
+			responses = append(responses, *response)
+		}
+
+		return responses
+	}
+
+	return &CategoryTreeResponse{Categories: build(roots)}
+}
@@ -0,0 +1,37 @@
+package category_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/app/category"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCategoryTreeResponse(t *testing.T) {
+	clothing, shoes := "clothing-id", "shoes-id"
+	tree := category.NewCategoryTreeResponse([]category.Category{
+		{ID: "belt-id", Slug: "belt", ParentID: &clothing},
+		{ID: clothing, Slug: "clothing"},
+		{ID: "leather-belt-id", Slug: "leather-belt", ParentID: strPtr("belt-id")},
+		{ID: "scarf-id", Slug: "scarf", ParentID: &clothing},
+		{ID: shoes, Slug: "shoes"},
+	})
+
+	assert.Equal(t, []string{"clothing", "shoes"}, slugs(tree.Categories))
+	assert.Equal(t, []string{"belt", "scarf"}, slugs(tree.Categories[0].Children))
+	assert.Equal(t, []string{"leather-belt"}, slugs(tree.Categories[0].Children[0].Children))
+	assert.Empty(t, tree.Categories[1].Children)
+}
+
+func slugs(categories []category.CategoryResponse) []string {
+	s := make([]string, 0, len(categories))
+	for _, c := range categories {
+		s = append(s, c.Slug)
+	}
+
+	return s
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,133 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus"
+)
+
+type Service interface {
+	GetCategory(ctx context.Context, id string) (*CategoryResponse, error)
+	ListCategories(ctx context.Context) (*CategoryTreeResponse, error)
+	CreateCategory(ctx context.Context, req CreateCategoryRequest) (*CategoryResponse, error)
+	UpdateCategory(
+		ctx context.Context, id string, req UpdateCategoryRequest) (*CategoryResponse, error)
+	DeleteCategory(ctx context.Context, id string) error
+	// ExistingCategories reports which of the given slugs belong to a
+	// category, it is how product types are validated.
+	ExistingCategories(ctx context.Context, slugs []string) (map[string]bool, error)
+}
+
+type service struct {
+	logger     *logrus.Logger
+	repository Repository
+}
+
+type NewServiceOpts struct {
+	L *logrus.Logger
+	R Repository
+}
+
+func NewService(opts *NewServiceOpts) Service {
+	return &service{
+		logger:     opts.L,
+		repository: opts.R,
+	}
+}
+
+func (s *service) GetCategory(ctx context.Context, id string) (*CategoryResponse, error) {
+	category, err := s.repository.GetCategoryByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, categoryNotFound()
+	}
+
+	if err != nil {
+		s.logger.WithField("category_id", id).Errorf("could not get category: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewCategoryResponse(category), nil
+}
+
+func (s *service) ListCategories(ctx context.Context) (*CategoryTreeResponse, error) {
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		s.logger.Errorf("could not list categories: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewCategoryTreeResponse(categories), nil
+}
+
+func (s *service) CreateCategory(
+	ctx context.Context, req CreateCategoryRequest) (*CategoryResponse, error) {
+	category, err := s.repository.CreateCategory(ctx, &Category{
+		ID:       uuid.New().String(),
+		Slug:     req.Slug,
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	})
+	if err != nil {
+		return nil, s.repositoryError(err, "could not create category")
+	}
+
+	return NewCategoryResponse(category), nil
+}
+
+func (s *service) UpdateCategory(
+	ctx context.Context, id string, req UpdateCategoryRequest) (*CategoryResponse, error) {
+	category, err := s.repository.UpdateCategory(ctx, &Category{
+		ID:       id,
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	})
+	if err != nil {
+		return nil, s.repositoryError(err, "could not update category")
+	}
+
+	return NewCategoryResponse(category), nil
+}
+
+func (s *service) DeleteCategory(ctx context.Context, id string) error {
+	if err := s.repository.DeleteCategory(ctx, id); err != nil {
+		return s.repositoryError(err, "could not delete category")
+	}
+
+	return nil
+}
+
+func (s *service) ExistingCategories(ctx context.Context, slugs []string) (map[string]bool, error) {
+	return s.repository.ExistingCategories(ctx, slugs)
+}
+
+func (s *service) repositoryError(err error, message string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return categoryNotFound()
+	case errors.Is(err, ErrSlugAlreadyExists):
+		return cerr.Bag{Code: CategorySlugAlreadyExists,
+			Message: "Another category with the same slug already exists."}
+	case errors.Is(err, ErrParentNotFound):
+		return cerr.Bag{Code: ParentCategoryNotFound, Message: "Parent category not found."}
+	case errors.Is(err, ErrCycle):
+		return cerr.Bag{Code: CategoryCycle,
+			Message: "A category can not be moved under itself or its descendants."}
+	case errors.Is(err, ErrHasChildren):
+		return cerr.Bag{Code: CategoryHasChildren,
+			Message: "Category has child categories and can not be deleted."}
+	case errors.Is(err, ErrInUse):
+		return cerr.Bag{Code: CategoryIsInUse,
+			Message: "Category is the type of products and can not be deleted."}
+	}
+
+	s.logger.Errorf("%s: %v", message, err)
+	return cerr.Processing()
+}
+
+func categoryNotFound() cerr.Bag {
+	return cerr.Bag{Code: CategoryNotFoundErrCode, Message: "Category not found."}
+}
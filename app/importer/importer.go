@@ -0,0 +1,377 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus"
+)
+
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+
+	DefaultBatchSize = 500
+)
+
+// Summary counts the rows of an import by outcome.
+type Summary struct {
+	Rows     int
+	Imported int
+	Rejected int
+}
+
+type Importer interface {
+	// Import reads the products in the given format from r and creates them in
+	// batches. Every rejected row is written to rejects as csv with its line
+	// number and error codes.
+	Import(ctx context.Context, r io.Reader, format Format, rejects io.Writer) (*Summary, error)
+}
+
+type importer struct {
+	repository product.Repository
+	categories product.CategoryChecker
+	policy     *product.PricingPolicy
+	logger     *logrus.Logger
+	batchSize  int
+}
+
+type NewImporterOpts struct {
+	R product.Repository
+	// C validates the product types against the categories.
+	C product.CategoryChecker
+	// P is the pricing policy the imported products must comply with, imports
+	// can not override it.
+	P *product.PricingPolicy
+	L *logrus.Logger
+	// BatchSize is the number of products written at once, DefaultBatchSize
+	// when zero.
+	BatchSize int
+}
+
+func New(opts *NewImporterOpts) Importer {
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	return &importer{
+		repository: opts.R,
+		categories: opts.C,
+		policy:     opts.P,
+		logger:     opts.L,
+		batchSize:  batchSize,
+	}
+}
+
+// row is a product read from the input along with the line it starts on.
+type row struct {
+	line int
+	req  product.CreateProductRequest
+	err  error
+}
+
+type run struct {
+	ctx     context.Context
+	summary Summary
+	rejects *csv.Writer
+	codes   map[string]struct{}
+	// types caches the product types looked up so far.
+	types map[string]bool
+	batch []*product.Product
+	lines []int
+}
+
+func (i *importer) Import(
+	ctx context.Context, r io.Reader, format Format, rejects io.Writer) (*Summary, error) {
+	var read func(r io.Reader, fn func(row) error) error
+	switch format {
+	case CSV:
+		read = readCSV
+	case NDJSON:
+		read = readNDJSON
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+
+	state := &run{
+		ctx:     ctx,
+		rejects: csv.NewWriter(rejects),
+		codes:   make(map[string]struct{}),
+		types:   make(map[string]bool),
+	}
+	if err := state.rejects.Write([]string{"line", "field", "code", "message"}); err != nil {
+		return nil, err
+	}
+
+	err := read(r, func(row row) error {
+		state.summary.Rows++
+		return i.add(state, row)
+	})
+	if err == nil {
+		err = i.flush(state)
+	}
+
+	state.rejects.Flush()
+	if err == nil {
+		err = state.rejects.Error()
+	}
+
+	return &state.summary, err
+}
+
+func (i *importer) add(state *run, row row) error {
+	if row.err != nil {
+		return state.reject(row.line, row.err)
+	}
+
+	if err := i.lookupType(state, row.req.Type); err != nil {
+		return err
+	}
+
+	if err := row.req.Validate(state.types); err != nil {
+		return state.reject(row.line, err)
+	}
+
+	// there is no admin behind an import to override the pricing policy.
+	if row.req.OverridePricingPolicy {
+		return state.reject(row.line, pricingOverrideNotAllowed())
+	}
+
+	p := product.NewProduct(uuid.New().String(), row.req)
+	if err := i.policy.Check(p, nil); err != nil {
+		return state.reject(row.line, err)
+	}
+
+	if _, ok := state.codes[row.req.Code]; ok {
+		return state.reject(row.line, codeAlreadyExists())
+	}
+	state.codes[row.req.Code] = struct{}{}
+
+	state.batch = append(state.batch, p)
+	state.lines = append(state.lines, row.line)
+
+	if len(state.batch) >= i.batchSize {
+		return i.flush(state)
+	}
+
+	return nil
+}
+
+// lookupType caches whether the product type is a category, so that every
+// type is looked up only once per import.
+func (i *importer) lookupType(state *run, productType string) error {
+	if _, ok := state.types[productType]; ok || productType == "" {
+		return nil
+	}
+
+	existing, err := i.categories.ExistingCategories(state.ctx, []string{productType})
+	if err != nil {
+		return err
+	}
+
+	state.types[productType] = existing[productType]
+	return nil
+}
+
+// flush writes the pending batch at once. When the batch is refused, e.g.
+// because a code already exists or a category is deleted meanwhile, the
+// products are written one by one so that only the conflicting rows are
+// rejected. The prices of the products are recorded in their price history
+// along with them, changed by no api key holder.
+func (i *importer) flush(state *run) error {
+	if len(state.batch) == 0 {
+		return nil
+	}
+
+	batch, lines := state.batch, state.lines
+	state.batch, state.lines = nil, nil
+
+	_, err := i.repository.CreateProducts(state.ctx, batch, "")
+	if err == nil {
+		state.summary.Imported += len(batch)
+		return nil
+	}
+
+	if !errors.Is(err, product.ErrProductCodeAlreadyExists) &&
+		!errors.Is(err, product.ErrProductTypeNotFound) {
+		return err
+	}
+
+	for j, p := range batch {
+		_, err = i.repository.CreateProduct(state.ctx, p, "")
+		switch {
+		case errors.Is(err, product.ErrProductCodeAlreadyExists):
+			if err = state.reject(lines[j], codeAlreadyExists()); err != nil {
+				return err
+			}
+		case errors.Is(err, product.ErrProductTypeNotFound):
+			state.types[string(p.Type)] = false
+			if err = state.reject(lines[j], typeNotFound()); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			state.summary.Imported++
+		}
+	}
+
+	return nil
+}
+
+func (s *run) reject(line int, err error) error {
+	s.summary.Rejected++
+
+	var bag cerr.Bag
+	if !errors.As(err, &bag) {
+		bag = cerr.Bag{Code: cerr.BodyParserErrCode, Message: err.Error()}
+	}
+
+	if len(bag.Errors) == 0 {
+		return s.rejects.Write([]string{
+			strconv.Itoa(line), "", strconv.Itoa(int(bag.Code)), bag.Message,
+		})
+	}
+
+	for _, fieldErr := range bag.Errors {
+		if err = s.rejects.Write([]string{
+			strconv.Itoa(line), fieldErr.Field, strconv.Itoa(int(fieldErr.Code)), fieldErr.Message,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func codeAlreadyExists() cerr.Bag {
+	return cerr.Bag{Code: product.ProductCodeAlreadyExists,
+		Message: "Another product with the same code already exists."}
+}
+
+func pricingOverrideNotAllowed() cerr.Bag {
+	return cerr.Bag{Code: product.PricingOverrideNotAllowed,
+		Message: "Imports can not override the pricing policy."}
+}
+
+func typeNotFound() cerr.Bag {
+	return cerr.Bag{Code: product.InvalidProductRequest, Message: "Product request is invalid.",
+		Errors: []cerr.FieldError{{
+			Field: "type", Code: product.InvalidProductType, Message: "Invalid product type."}}}
+}
+
+// readCSV reads products from csv with a header row naming the columns after
+// the json fields of product.CreateProductRequest.
+func readCSV(r io.Reader, fn func(row) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("could not read csv header: %w", err)
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if err = fn(row{line: parseErr.StartLine, err: parseErr.Err}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if err = fn(row{line: line, req: parseCSVRecord(header, record)}); err != nil {
+			return err
+		}
+	}
+}
+
+func parseCSVRecord(header, record []string) product.CreateProductRequest {
+	var req product.CreateProductRequest
+	for i, column := range header {
+		if i >= len(record) {
+			break
+		}
+
+		column = strings.TrimSpace(strings.ToLower(column))
+		value := strings.TrimSpace(record[i])
+		switch column {
+		case "name":
+			req.Name = value
+		case "code":
+			req.Code = value
+		case "color":
+			req.Color = value
+		case "currency":
+			req.Currency = value
+		case "buying_price":
+			req.BuyingPrice = json.Number(value)
+		case "selling_price":
+			req.SellingPrice = json.Number(value)
+		case "image_url":
+			req.ImageURL = value
+		case "type":
+			req.Type = value
+		case "provider":
+			req.Provider = value
+		case "creator":
+			req.Creator = value
+		case "distributor":
+			req.Distributor = value
+		}
+	}
+
+	return req
+}
+
+// readNDJSON reads one product.CreateProductRequest json document per line,
+// blank lines are skipped.
+func readNDJSON(r io.Reader, fn func(row) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		data := scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+
+		var req product.CreateProductRequest
+		err := json.Unmarshal(data, &req)
+		if err != nil {
+			err = fmt.Errorf("invalid json: %w", err)
+		}
+
+		if err = fn(row{line: line, req: req, err: err}); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,187 @@
+package importer_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/app/importer"
+	"github.com/pact-cdc-example/product-service/app/persistence"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCSV(t *testing.T) {
+	input := strings.NewReader(`name,code,type,buying_price,selling_price
+Runner,SKU-1,shoes,50,100
+Broken,,unknown,5,1
+Runner Copy,SKU-1,shoes,50,100
+Tote,SKU-2,bag,10.5,20.25
+`)
+	repository := persistence.NewMemoryRepository()
+
+	var rejects bytes.Buffer
+	summary, err := newImporter(repository).Import(
+		context.Background(), input, importer.CSV, &rejects)
+
+	require.NoError(t, err)
+	assert.Equal(t, importer.Summary{Rows: 4, Imported: 2, Rejected: 2}, *summary)
+	assert.Equal(t, `line,field,code,message
+3,code,20019,Product code is required.
+3,type,20005,Invalid product type.
+3,selling_price,20016,Selling price can not be lower than buying price.
+4,,20018,Another product with the same code already exists.
+`, rejects.String())
+
+	imported, err := repository.GetProductByCode(context.Background(), "SKU-2")
+	require.NoError(t, err)
+	assert.Equal(t, money.New(2025, product.DefaultCurrency), imported.SellingPrice)
+
+	history, err := repository.ListScheduledPrices(context.Background(), imported.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1, "imported prices are recorded in the price history")
+	assert.Equal(t, imported.SellingPrice, history[0].Price)
+}
+
+func TestImportCSVCurrencies(t *testing.T) {
+	input := strings.NewReader(`name,code,type,currency,buying_price,selling_price
+Runner,SKU-1,shoes,jpy,5000,9800
+Tote,SKU-2,bag,EUR,10.555,20
+`)
+	repository := persistence.NewMemoryRepository()
+
+	var rejects bytes.Buffer
+	summary, err := newImporter(repository).Import(
+		context.Background(), input, importer.CSV, &rejects)
+
+	require.NoError(t, err)
+	assert.Equal(t, importer.Summary{Rows: 2, Imported: 1, Rejected: 1}, *summary)
+	assert.Contains(t, rejects.String(), "\n3,buying_price,20032,")
+
+	imported, err := repository.GetProductByCode(context.Background(), "SKU-1")
+	require.NoError(t, err)
+	assert.Equal(t, money.New(9800, "JPY"), imported.SellingPrice)
+}
+
+func TestImportNDJSON(t *testing.T) {
+	input := strings.NewReader(`{"name":"Runner","code":"SKU-1","type":"shoes"}
+
+{"name":
+{"name":"Tote","code":"SKU-2","type":"bag"}
+`)
+
+	var rejects bytes.Buffer
+	summary, err := newImporter(persistence.NewMemoryRepository()).Import(
+		context.Background(), input, importer.NDJSON, &rejects)
+
+	require.NoError(t, err)
+	assert.Equal(t, importer.Summary{Rows: 3, Imported: 2, Rejected: 1}, *summary)
+	assert.Contains(t, rejects.String(), "\n3,,10001,invalid json")
+}
+
+func TestImportRejectsCodesExistingInRepository(t *testing.T) {
+	repository := persistence.NewMemoryRepository()
+	_, err := repository.CreateProduct(context.Background(), &product.Product{
+		ID: "existing", Name: "Existing", Code: "SKU-1", Type: product.Shoes,
+	}, "")
+	require.NoError(t, err)
+
+	input := strings.NewReader(`name,code,type
+Tote,SKU-2,bag
+Runner,SKU-1,shoes
+`)
+
+	var rejects bytes.Buffer
+	summary, err := newImporter(repository).Import(
+		context.Background(), input, importer.CSV, &rejects)
+
+	require.NoError(t, err)
+	assert.Equal(t, importer.Summary{Rows: 2, Imported: 1, Rejected: 1}, *summary)
+	assert.Contains(t, rejects.String(), "\n3,,20018,")
+}
+
+func TestImportEnforcesPricingPolicy(t *testing.T) {
+	policy, err := product.NewPricingPolicy(&product.NewPricingPolicyOpts{
+		DefaultMinMarginPercent: "20",
+	})
+	require.NoError(t, err)
+
+	input := strings.NewReader(`{"name":"Runner","code":"SKU-1","type":"shoes","buying_price":"50","selling_price":"100"}
+{"name":"Tote","code":"SKU-2","type":"bag","buying_price":"50","selling_price":"55"}
+{"name":"Hat","code":"SKU-3","type":"hat","buying_price":"50","selling_price":"55","override_pricing_policy":true}
+`)
+	repository := persistence.NewMemoryRepository()
+	logger, _ := test.NewNullLogger()
+
+	var rejects bytes.Buffer
+	summary, err := importer.New(&importer.NewImporterOpts{
+		R: repository,
+		C: persistence.NewMemoryCategoryRepository(repository),
+		P: policy,
+		L: logger,
+	}).Import(context.Background(), input, importer.NDJSON, &rejects)
+
+	require.NoError(t, err)
+	assert.Equal(t, importer.Summary{Rows: 3, Imported: 1, Rejected: 2}, *summary)
+	assert.Contains(t, rejects.String(), "\n2,selling_price,20040,")
+	assert.Contains(t, rejects.String(), "\n3,,20044,")
+}
+
+func TestImportRejectsTypesOfDeletedCategories(t *testing.T) {
+	repository := persistence.NewMemoryRepository()
+	input := strings.NewReader(`name,code,type
+Tote,SKU-1,bag
+Runner,SKU-2,shoes
+`)
+
+	var rejects bytes.Buffer
+	summary, err := newImporter(&deletedCategoryRepository{repository, product.Bag}).Import(
+		context.Background(), input, importer.CSV, &rejects)
+
+	require.NoError(t, err)
+	assert.Equal(t, importer.Summary{Rows: 2, Imported: 1, Rejected: 1}, *summary)
+	assert.Contains(t, rejects.String(), "\n2,type,20005,")
+
+	_, err = repository.GetProductByCode(context.Background(), "SKU-2")
+	assert.NoError(t, err)
+}
+
+// deletedCategoryRepository refuses the products of a category deleted after
+// the import looked it up, like the foreign key of the products table does.
+type deletedCategoryRepository struct {
+	product.Repository
+	deleted product.ProductType
+}
+
+func (r *deletedCategoryRepository) CreateProduct(
+	ctx context.Context, p *product.Product, changedBy string) (*product.Product, error) {
+	if p.Type == r.deleted {
+		return nil, product.ErrProductTypeNotFound
+	}
+
+	return r.Repository.CreateProduct(ctx, p, changedBy)
+}
+
+func (r *deletedCategoryRepository) CreateProducts(ctx context.Context,
+	products []*product.Product, changedBy string) ([]*product.Product, error) {
+	for _, p := range products {
+		if p.Type == r.deleted {
+			return nil, product.ErrProductTypeNotFound
+		}
+	}
+
+	return r.Repository.CreateProducts(ctx, products, changedBy)
+}
+
+func newImporter(repository product.Repository) importer.Importer {
+	logger, _ := test.NewNullLogger()
+	return importer.New(&importer.NewImporterOpts{
+		R: repository,
+		C: persistence.NewMemoryCategoryRepository(repository),
+		L: logger,
+	})
+}
@@ -0,0 +1,49 @@
+package inventory
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+)
+
+const (
+	ProductNotFoundErrCode     = 40001
+	InvalidAdjustmentRequest   = 40002
+	StockDeltaIsRequired       = 40003
+	InsufficientStock          = 40004
+	InvalidReservationRequest  = 40005
+	BasketIDIsRequired         = 40006
+	AtLeastOneItemIsRequired   = 40007
+	InvalidQuantity            = 40008
+	InvalidReservationTTL      = 40009
+	ReservationNotFoundErrCode = 40010
+	ReservationExpired         = 40011
+	ReservationNotPending      = 40012
+	InvalidWarehouse           = 40013
+	ProductIDIsRequired        = 40014
+)
+
+// Errors returned by repositories along with InsufficientStockError, they
+// are mapped to bags by the service.
+var (
+	ErrProductNotFound       = errors.New("product not found")
+	ErrReservationExpired    = errors.New("reservation expired")
+	ErrReservationNotPending = errors.New("reservation is not pending")
+)
+
+func init() {
+	cerr.RegisterStatus(http.StatusNotFound,
+		ProductNotFoundErrCode,
+		ReservationNotFoundErrCode,
+	)
+	cerr.RegisterStatus(http.StatusUnprocessableEntity,
+		InvalidAdjustmentRequest,
+		InvalidReservationRequest,
+	)
+	cerr.RegisterStatus(http.StatusConflict,
+		InsufficientStock,
+		ReservationExpired,
+		ReservationNotPending,
+	)
+}
@@ -0,0 +1,135 @@
+package inventory
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus"
+)
+
+type Handler interface {
+	SetupRoutes(fr fiber.Router)
+	GetStock(c *fiber.Ctx) error
+	AdjustStock(c *fiber.Ctx) error
+	Reserve(c *fiber.Ctx) error
+	GetReservation(c *fiber.Ctx) error
+	CommitReservation(c *fiber.Ctx) error
+	ReleaseReservation(c *fiber.Ctx) error
+}
+
+type handler struct {
+	logger  *logrus.Logger
+	service Service
+}
+
+type NewHandlerOpts struct {
+	L *logrus.Logger
+	S Service
+}
+
+func NewHandler(opts *NewHandlerOpts) Handler {
+	return &handler{
+		logger:  opts.L,
+		service: opts.S,
+	}
+}
+
+func (h *handler) GetStock(c *fiber.Ctx) error {
+	productID := c.Params("productID")
+	h.logger.Infof("Get Stock request arrived! Product ID: %s", productID)
+
+	stock, err := h.service.GetStock(c.Context(), productID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(stock)
+}
+
+func (h *handler) AdjustStock(c *fiber.Ctx) error {
+	productID := c.Params("productID")
+	h.logger.Infof("Adjust Stock request arrived! Product ID: %s", productID)
+
+	var req AdjustStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	stock, err := h.service.AdjustStock(c.Context(), productID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(stock)
+}
+
+func (h *handler) Reserve(c *fiber.Ctx) error {
+	h.logger.Infof("Reserve request arrived!")
+
+	var req ReserveRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(h.service.MaxReservationTTL()); err != nil {
+		return err
+	}
+
+	reservation, err := h.service.Reserve(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(reservation)
+}
+
+func (h *handler) GetReservation(c *fiber.Ctx) error {
+	reservationID := c.Params("id")
+	h.logger.Infof("Get Reservation request arrived! Reservation ID: %s", reservationID)
+
+	reservation, err := h.service.GetReservation(c.Context(), reservationID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(reservation)
+}
+
+func (h *handler) CommitReservation(c *fiber.Ctx) error {
+	reservationID := c.Params("id")
+	h.logger.Infof("Commit Reservation request arrived! Reservation ID: %s", reservationID)
+
+	reservation, err := h.service.CommitReservation(c.Context(), reservationID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(reservation)
+}
+
+func (h *handler) ReleaseReservation(c *fiber.Ctx) error {
+	reservationID := c.Params("id")
+	h.logger.Infof("Release Reservation request arrived! Reservation ID: %s", reservationID)
+
+	reservation, err := h.service.ReleaseReservation(c.Context(), reservationID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(reservation)
+}
+
+func (h *handler) SetupRoutes(fr fiber.Router) {
+	inventoryGroup := fr.Group("/inventory")
+	inventoryGroup.Get("/:productID", h.GetStock)
+	inventoryGroup.Post("/:productID/adjustments", h.AdjustStock)
+
+	reservationsGroup := fr.Group("/reservations")
+	reservationsGroup.Post("/", h.Reserve)
+	reservationsGroup.Get("/:id", h.GetReservation)
+	reservationsGroup.Post("/:id/commit", h.CommitReservation)
+	reservationsGroup.Post("/:id/release", h.ReleaseReservation)
+}
@@ -0,0 +1,76 @@
+package inventory
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultWarehouse is where stock is kept when no warehouse is given.
+const DefaultWarehouse = "default"
+
+// Stock is the quantity of a product in a warehouse. Reserved only counts
+// the pending reservations that have not expired yet.
+type Stock struct {
+	ProductID string
+	Warehouse string
+	OnHand    int
+	Reserved  int
+	UpdatedAt time.Time
+}
+
+func (s Stock) Available() int {
+	return s.OnHand - s.Reserved
+}
+
+type ReservationStatus string
+
+const (
+	// StatusPending reservations hold stock until they expire.
+	StatusPending   ReservationStatus = "pending"
+	StatusCommitted ReservationStatus = "committed"
+	StatusReleased  ReservationStatus = "released"
+	// StatusExpired is never stored, pending reservations past their expiry
+	// are reported as expired.
+	StatusExpired ReservationStatus = "expired"
+)
+
+// Reservation holds stock of its items for a basket until it is committed,
+// which takes the quantities off the stock, released or expired.
+type Reservation struct {
+	ID        string
+	BasketID  string
+	Status    ReservationStatus
+	Items     []ReservationItem
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// EffectiveStatus reports pending reservations expired at now as expired.
+func (r Reservation) EffectiveStatus(now time.Time) ReservationStatus {
+	if r.Status == StatusPending && !now.Before(r.ExpiresAt) {
+		return StatusExpired
+	}
+
+	return r.Status
+}
+
+type ReservationItem struct {
+	ProductID string
+	Warehouse string
+	Quantity  int
+}
+
+// InsufficientStockError is returned by repositories when a product does not
+// have the requested quantity available in a warehouse.
+type InsufficientStockError struct {
+	ProductID string
+	Warehouse string
+	Requested int
+	Available int
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock of product %s in warehouse %s: requested %d, available %d",
+		e.ProductID, e.Warehouse, e.Requested, e.Available)
+}
@@ -0,0 +1,17 @@
+package inventory
+
+import "context"
+
+//go:generate mockgen -source=repository.go -destination=mock_repository.go -package=inventory
+type Repository interface {
+	// GetStock returns the stock of the product per warehouse.
+	GetStock(ctx context.Context, productID string) ([]Stock, error)
+	// Available sums the available quantity of the products over their
+	// warehouses. Products without stock are left out.
+	Available(ctx context.Context, productIDs []string) (map[string]int, error)
+	AdjustStock(ctx context.Context, productID, warehouse string, delta int) (*Stock, error)
+	Reserve(ctx context.Context, reservation *Reservation) (*Reservation, error)
+	GetReservation(ctx context.Context, id string) (*Reservation, error)
+	CommitReservation(ctx context.Context, id string) (*Reservation, error)
+	ReleaseReservation(ctx context.Context, id string) (*Reservation, error)
+}
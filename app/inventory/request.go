@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/pkg/validation"
+)
+
+const maxWarehouseLength = 255
+
+// AdjustStockRequest adds delta, or takes it off when negative, to the
+// on-hand quantity of the product in the warehouse.
+type AdjustStockRequest struct {
+	Warehouse string `json:"warehouse"`
+	Delta     int    `json:"delta"`
+}
+
+func (a AdjustStockRequest) Validate() error {
+	v := validation.New()
+
+	v.Check(len(a.Warehouse) <= maxWarehouseLength, "warehouse",
+		InvalidWarehouse, fmt.Sprintf("Warehouse can be at most %d characters.", maxWarehouseLength))
+	v.Check(a.Delta != 0, "delta", StockDeltaIsRequired, "Delta must not be zero.")
+
+	return v.Err(InvalidAdjustmentRequest, "Stock adjustment request is invalid.")
+}
+
+type ReserveRequest struct {
+	BasketID string               `json:"basket_id"`
+	Items    []ReserveItemRequest `json:"items"`
+	// TTLSeconds is how long the stock is held, the configured reservation
+	// ttl when zero.
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+type ReserveItemRequest struct {
+	ProductID string `json:"product_id"`
+	Warehouse string `json:"warehouse"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Validate checks the request against the maximum reservation ttl.
+func (r ReserveRequest) Validate(maxTTL time.Duration) error {
+	v := validation.New()
+
+	v.Check(strings.TrimSpace(r.BasketID) != "", "basket_id",
+		BasketIDIsRequired, "Basket id is required.")
+	v.Check(len(r.Items) > 0, "items", AtLeastOneItemIsRequired, "At least one item is required.")
+	for i, item := range r.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		v.Check(item.ProductID != "", field+".product_id",
+			ProductIDIsRequired, "Product id is required.")
+		v.Check(len(item.Warehouse) <= maxWarehouseLength, field+".warehouse",
+			InvalidWarehouse,
+			fmt.Sprintf("Warehouse can be at most %d characters.", maxWarehouseLength))
+		v.Check(item.Quantity > 0, field+".quantity",
+			InvalidQuantity, "Quantity must be positive.")
+	}
+	v.Check(r.TTLSeconds >= 0 && time.Duration(r.TTLSeconds)*time.Second <= maxTTL,
+		"ttl_seconds", InvalidReservationTTL,
+		fmt.Sprintf("Ttl can not be negative or exceed %d seconds.", int(maxTTL.Seconds())))
+
+	return v.Err(InvalidReservationRequest, "Reservation request is invalid.")
+}
+
+// reservationItems merges the items of the same product and warehouse, the
+// default warehouse being used when none is given.
+func (r ReserveRequest) reservationItems() []ReservationItem {
+	items := make([]ReservationItem, 0, len(r.Items))
+	index := make(map[[2]string]int, len(r.Items))
+	for _, item := range r.Items {
+		warehouse := warehouseOrDefault(item.Warehouse)
+		key := [2]string{item.ProductID, warehouse}
+		if i, ok := index[key]; ok {
+			items[i].Quantity += item.Quantity
+			continue
+		}
+
+		index[key] = len(items)
+		items = append(items, ReservationItem{
+			ProductID: item.ProductID,
+			Warehouse: warehouse,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	return items
+}
+
+func warehouseOrDefault(warehouse string) string {
+	if warehouse == "" {
+		return DefaultWarehouse
+	}
+
+	return warehouse
+}
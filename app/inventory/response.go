@@ -0,0 +1,78 @@
+package inventory
+
+import "time"
+
+type StockResponse struct {
+	ProductID  string                   `json:"product_id"`
+	OnHand     int                      `json:"on_hand"`
+	Reserved   int                      `json:"reserved"`
+	Available  int                      `json:"available"`
+	Warehouses []WarehouseStockResponse `json:"warehouses"`
+}
+
+type WarehouseStockResponse struct {
+	Warehouse string    `json:"warehouse"`
+	OnHand    int       `json:"on_hand"`
+	Reserved  int       `json:"reserved"`
+	Available int       `json:"available"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewStockResponse(productID string, stocks []Stock) *StockResponse {
+	response := &StockResponse{
+		ProductID:  productID,
+		Warehouses: make([]WarehouseStockResponse, 0, len(stocks)),
+	}
+
+	for _, stock := range stocks {
+		response.OnHand += stock.OnHand
+		response.Reserved += stock.Reserved
+		response.Available += stock.Available()
+		response.Warehouses = append(response.Warehouses, WarehouseStockResponse{
+			Warehouse: stock.Warehouse,
+			OnHand:    stock.OnHand,
+			Reserved:  stock.Reserved,
+			Available: stock.Available(),
+			UpdatedAt: stock.UpdatedAt,
+		})
+	}
+
+	return response
+}
+
+type ReservationResponse struct {
+	ID        string                    `json:"id"`
+	BasketID  string                    `json:"basket_id"`
+	Status    ReservationStatus         `json:"status"`
+	Items     []ReservationItemResponse `json:"items"`
+	ExpiresAt time.Time                 `json:"expires_at"`
+	CreatedAt time.Time                 `json:"created_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+type ReservationItemResponse struct {
+	ProductID string `json:"product_id"`
+	Warehouse string `json:"warehouse"`
+	Quantity  int    `json:"quantity"`
+}
+
+func NewReservationResponse(reservation *Reservation, now time.Time) *ReservationResponse {
+	if reservation == nil {
+		return nil
+	}
+
+	items := make([]ReservationItemResponse, 0, len(reservation.Items))
+	for _, item := range reservation.Items {
+		items = append(items, ReservationItemResponse(item))
+	}
+
+	return &ReservationResponse{
+		ID:        reservation.ID,
+		BasketID:  reservation.BasketID,
+		Status:    reservation.EffectiveStatus(now),
+		Items:     items,
+		ExpiresAt: reservation.ExpiresAt,
+		CreatedAt: reservation.CreatedAt,
+		UpdatedAt: reservation.UpdatedAt,
+	}
+}
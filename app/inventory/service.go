@@ -0,0 +1,169 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultReservationTTL    = 15 * time.Minute
+	DefaultMaxReservationTTL = 2 * time.Hour
+)
+
+type Service interface {
+	GetStock(ctx context.Context, productID string) (*StockResponse, error)
+	AdjustStock(ctx context.Context, productID string, req AdjustStockRequest) (*StockResponse, error)
+	Reserve(ctx context.Context, req ReserveRequest) (*ReservationResponse, error)
+	GetReservation(ctx context.Context, id string) (*ReservationResponse, error)
+	CommitReservation(ctx context.Context, id string) (*ReservationResponse, error)
+	ReleaseReservation(ctx context.Context, id string) (*ReservationResponse, error)
+	// Available sums the available quantity of the products over their
+	// warehouses, products without stock have none available.
+	Available(ctx context.Context, productIDs []string) (map[string]int, error)
+	MaxReservationTTL() time.Duration
+}
+
+type service struct {
+	logger            *logrus.Logger
+	repository        Repository
+	reservationTTL    time.Duration
+	maxReservationTTL time.Duration
+	now               func() time.Time
+}
+
+type NewServiceOpts struct {
+	L *logrus.Logger
+	R Repository
+	// ReservationTTL is the ttl of reservations not asking for one,
+	// DefaultReservationTTL when zero.
+	ReservationTTL time.Duration
+	// MaxReservationTTL limits the ttl of reservations,
+	// DefaultMaxReservationTTL when zero.
+	MaxReservationTTL time.Duration
+}
+
+func NewService(opts *NewServiceOpts) Service {
+	reservationTTL := opts.ReservationTTL
+	if reservationTTL == 0 {
+		reservationTTL = DefaultReservationTTL
+	}
+
+	maxReservationTTL := opts.MaxReservationTTL
+	if maxReservationTTL == 0 {
+		maxReservationTTL = DefaultMaxReservationTTL
+	}
+
+	return &service{
+		logger:            opts.L,
+		repository:        opts.R,
+		reservationTTL:    reservationTTL,
+		maxReservationTTL: maxReservationTTL,
+		now:               time.Now,
+	}
+}
+
+func (s *service) MaxReservationTTL() time.Duration {
+	return s.maxReservationTTL
+}
+
+func (s *service) GetStock(ctx context.Context, productID string) (*StockResponse, error) {
+	stocks, err := s.repository.GetStock(ctx, productID)
+	if err != nil {
+		return nil, s.repositoryError(err, "could not get stock")
+	}
+
+	return NewStockResponse(productID, stocks), nil
+}
+
+func (s *service) AdjustStock(
+	ctx context.Context, productID string, req AdjustStockRequest) (*StockResponse, error) {
+	if _, err := s.repository.AdjustStock(
+		ctx, productID, warehouseOrDefault(req.Warehouse), req.Delta); err != nil {
+		return nil, s.repositoryError(err, "could not adjust stock")
+	}
+
+	return s.GetStock(ctx, productID)
+}
+
+func (s *service) Reserve(ctx context.Context, req ReserveRequest) (*ReservationResponse, error) {
+	ttl := s.reservationTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	reservation, err := s.repository.Reserve(ctx, &Reservation{
+		ID:        uuid.New().String(),
+		BasketID:  req.BasketID,
+		Status:    StatusPending,
+		Items:     req.reservationItems(),
+		ExpiresAt: s.now().Add(ttl),
+	})
+	if err != nil {
+		return nil, s.repositoryError(err, "could not reserve stock")
+	}
+
+	return NewReservationResponse(reservation, s.now()), nil
+}
+
+func (s *service) GetReservation(ctx context.Context, id string) (*ReservationResponse, error) {
+	reservation, err := s.repository.GetReservation(ctx, id)
+	if err != nil {
+		return nil, s.repositoryError(err, "could not get reservation")
+	}
+
+	return NewReservationResponse(reservation, s.now()), nil
+}
+
+func (s *service) CommitReservation(ctx context.Context, id string) (*ReservationResponse, error) {
+	reservation, err := s.repository.CommitReservation(ctx, id)
+	if err != nil {
+		return nil, s.repositoryError(err, "could not commit reservation")
+	}
+
+	return NewReservationResponse(reservation, s.now()), nil
+}
+
+func (s *service) ReleaseReservation(ctx context.Context, id string) (*ReservationResponse, error) {
+	reservation, err := s.repository.ReleaseReservation(ctx, id)
+	if err != nil {
+		return nil, s.repositoryError(err, "could not release reservation")
+	}
+
+	return NewReservationResponse(reservation, s.now()), nil
+}
+
+func (s *service) Available(ctx context.Context, productIDs []string) (map[string]int, error) {
+	return s.repository.Available(ctx, productIDs)
+}
+
+func (s *service) repositoryError(err error, message string) error {
+	var insufficient *InsufficientStockError
+	switch {
+	case errors.As(err, &insufficient):
+		return cerr.Bag{Code: InsufficientStock, Message: "Insufficient stock.",
+			Details: map[string]interface{}{
+				"product_id": insufficient.ProductID,
+				"warehouse":  insufficient.Warehouse,
+				"requested":  insufficient.Requested,
+				"available":  insufficient.Available,
+			}}
+	case errors.Is(err, ErrProductNotFound):
+		return cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
+	case errors.Is(err, sql.ErrNoRows):
+		return cerr.Bag{Code: ReservationNotFoundErrCode, Message: "Reservation not found."}
+	case errors.Is(err, ErrReservationExpired):
+		return cerr.Bag{Code: ReservationExpired, Message: "Reservation has expired."}
+	case errors.Is(err, ErrReservationNotPending):
+		return cerr.Bag{Code: ReservationNotPending,
+			Message: "Reservation is already committed or released."}
+	}
+
+	s.logger.Errorf("%s: %v", message, err)
+	return cerr.Processing()
+}
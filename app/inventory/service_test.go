@@ -0,0 +1,60 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveMergesItemsAndAppliesDefaultTTL(t *testing.T) {
+	repository := NewMockRepository(gomock.NewController(t))
+	logger, _ := test.NewNullLogger()
+	s := NewService(&NewServiceOpts{L: logger, R: repository, ReservationTTL: time.Minute})
+
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.(*service).now = func() time.Time { return now }
+
+	repository.EXPECT().Reserve(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, r *Reservation) (*Reservation, error) {
+			assert.Equal(t, []ReservationItem{
+				{ProductID: "p1", Warehouse: DefaultWarehouse, Quantity: 3},
+				{ProductID: "p1", Warehouse: "east", Quantity: 1},
+			}, r.Items)
+			assert.Equal(t, now.Add(time.Minute), r.ExpiresAt)
+			return r, nil
+		})
+
+	response, err := s.Reserve(context.Background(), ReserveRequest{
+		BasketID: "b1",
+		Items: []ReserveItemRequest{
+			{ProductID: "p1", Quantity: 2},
+			{ProductID: "p1", Warehouse: "east", Quantity: 1},
+			{ProductID: "p1", Warehouse: DefaultWarehouse, Quantity: 1},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, response.Status)
+}
+
+func TestReserveInsufficientStock(t *testing.T) {
+	repository := NewMockRepository(gomock.NewController(t))
+	logger, _ := test.NewNullLogger()
+	s := NewService(&NewServiceOpts{L: logger, R: repository})
+
+	repository.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(nil, &InsufficientStockError{
+		ProductID: "p1", Warehouse: DefaultWarehouse, Requested: 3, Available: 1})
+
+	_, err := s.Reserve(context.Background(), ReserveRequest{
+		BasketID: "b1", Items: []ReserveItemRequest{{ProductID: "p1", Quantity: 3}}})
+
+	var bag cerr.Bag
+	require.ErrorAs(t, err, &bag)
+	assert.Equal(t, cerr.Code(InsufficientStock), bag.Code)
+	assert.Equal(t, 1, bag.Details["available"])
+}
@@ -0,0 +1,207 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/category"
+	"github.com/pact-cdc-example/product-service/app/product"
+)
+
+type memoryCategoryRepository struct {
+	mu         sync.RWMutex
+	categories map[string]category.Category
+	products   product.Repository
+}
+
+// NewMemoryCategoryRepository returns a concurrency safe category.Repository
+// keeping the categories in memory, seeded with the root categories like the
+// categories migration does. Categories in use by products of the given
+// repository can not be deleted, products may be nil.
+func NewMemoryCategoryRepository(products product.Repository) category.Repository {
+	now := memoryNow()
+	categories := make(map[string]category.Category, len(category.RootCategories))
+	for _, c := range category.RootCategories {
+		c.ID, c.CreatedAt, c.UpdatedAt = uuid.New().String(), now, now
+		categories[c.ID] = c
+	}
+
+	return &memoryCategoryRepository{
+		categories: categories,
+		products:   products,
+	}
+}
+
+func (mr *memoryCategoryRepository) GetCategoryByID(
+	ctx context.Context, id string) (*category.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	c, ok := mr.categories[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &c, nil
+}
+
+func (mr *memoryCategoryRepository) ListCategories(
+	ctx context.Context) ([]category.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	categories := make([]category.Category, 0, len(mr.categories))
+	for _, c := range mr.categories {
+		categories = append(categories, c)
+	}
+	mr.mu.RUnlock()
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+
+		return categories[i].ID < categories[j].ID
+	})
+
+	return categories, nil
+}
+
+func (mr *memoryCategoryRepository) ExistingCategories(
+	ctx context.Context, slugs []string) (map[string]bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]struct{}, len(slugs))
+	for _, slug := range slugs {
+		wanted[slug] = struct{}{}
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	existing := make(map[string]bool, len(slugs))
+	for _, c := range mr.categories {
+		if _, ok := wanted[c.Slug]; ok {
+			existing[c.Slug] = true
+		}
+	}
+
+	return existing, nil
+}
+
+func (mr *memoryCategoryRepository) CreateCategory(
+	ctx context.Context, c *category.Category) (*category.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	for _, existing := range mr.categories {
+		if existing.Slug == c.Slug {
+			return nil, category.ErrSlugAlreadyExists
+		}
+	}
+
+	if c.ParentID != nil {
+		if _, ok := mr.categories[*c.ParentID]; !ok {
+			return nil, category.ErrParentNotFound
+		}
+	}
+
+	created := *c
+	created.CreatedAt = memoryNow()
+	created.UpdatedAt = created.CreatedAt
+	mr.categories[created.ID] = created
+
+	return &created, nil
+}
+
+func (mr *memoryCategoryRepository) UpdateCategory(
+	ctx context.Context, c *category.Category) (*category.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	updated, ok := mr.categories[c.ID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	if c.ParentID != nil {
+		// walking up from the new parent must not reach the category itself.
+		for id := c.ParentID; id != nil; {
+			parent, ok := mr.categories[*id]
+			if !ok {
+				return nil, category.ErrParentNotFound
+			}
+
+			if parent.ID == c.ID {
+				return nil, category.ErrCycle
+			}
+
+			id = parent.ParentID
+		}
+	}
+
+	updated.Name = c.Name
+	updated.ParentID = c.ParentID
+	updated.UpdatedAt = memoryNow()
+	mr.categories[updated.ID] = updated
+
+	return &updated, nil
+}
+
+func (mr *memoryCategoryRepository) DeleteCategory(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	c, ok := mr.categories[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	for _, child := range mr.categories {
+		if child.ParentID != nil && *child.ParentID == id {
+			return category.ErrHasChildren
+		}
+	}
+
+	if mr.products != nil {
+		products, err := mr.products.ListProducts(ctx, product.ListFilter{
+			Types:          []string{c.Slug},
+			Sort:           product.ListSort{Field: product.SortByCreatedAt},
+			Limit:          1,
+			IncludeDeleted: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(products) > 0 {
+			return category.ErrInUse
+		}
+	}
+
+	delete(mr.categories, id)
+	return nil
+}
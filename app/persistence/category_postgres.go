@@ -0,0 +1,253 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/pact-cdc-example/product-service/app/category"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	categoriesSlugConstraint   = "categories_slug_key"
+	categoriesParentConstraint = "categories_parent_id_fkey"
+	foreignKeyViolationErrCode = "23503"
+)
+
+const categoryColumns = `id, slug, name, parent_id, created_at, updated_at`
+
+type postgresCategoryRepository struct {
+	db     *sql.DB
+	logger *logrus.Logger
+}
+
+type NewPostgresCategoryRepositoryOpts struct {
+	DB *sql.DB
+	L  *logrus.Logger
+}
+
+func NewPostgresCategoryRepository(opts *NewPostgresCategoryRepositoryOpts) category.Repository {
+	return &postgresCategoryRepository{
+		db:     opts.DB,
+		logger: opts.L,
+	}
+}
+
+func scanCategory(row rowScanner) (*category.Category, error) {
+	var c category.Category
+	if err := row.Scan(
+		&c.ID,
+		&c.Slug,
+		&c.Name,
+		&c.ParentID,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// isForeignKeyViolation reports whether err is a postgres foreign key
+// violation of the given constraint.
+func isForeignKeyViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) &&
+		pqErr.Code == foreignKeyViolationErrCode && pqErr.Constraint == constraint
+}
+
+func (cr *postgresCategoryRepository) GetCategoryByID(
+	ctx context.Context, id string) (*category.Category, error) {
+	row := cr.db.QueryRowContext(ctx,
+		`SELECT `+categoryColumns+` FROM categories WHERE id = $1`, id)
+
+	c, err := scanCategory(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			cr.logger.Errorf("could not get category :%v", err)
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (cr *postgresCategoryRepository) ListCategories(
+	ctx context.Context) ([]category.Category, error) {
+	rows, err := cr.db.QueryContext(ctx,
+		`SELECT `+categoryColumns+` FROM categories ORDER BY name, id`)
+	if err != nil {
+		cr.logger.Errorf("could not list categories :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []category.Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, *c)
+	}
+
+	return categories, rows.Err()
+}
+
+func (cr *postgresCategoryRepository) ExistingCategories(
+	ctx context.Context, slugs []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(slugs))
+	if len(slugs) == 0 {
+		return existing, nil
+	}
+
+	rows, err := cr.db.QueryContext(ctx,
+		`SELECT slug FROM categories WHERE slug = ANY($1)`, pq.Array(slugs))
+	if err != nil {
+		cr.logger.Errorf("could not check categories :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var slug string
+		if err = rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+
+		existing[slug] = true
+	}
+
+	return existing, rows.Err()
+}
+
+func (cr *postgresCategoryRepository) CreateCategory(
+	ctx context.Context, c *category.Category) (*category.Category, error) {
+	row := cr.db.QueryRowContext(ctx,
+		`INSERT INTO categories (id, slug, name, parent_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+categoryColumns,
+		c.ID, c.Slug, c.Name, c.ParentID,
+	)
+
+	created, err := scanCategory(row)
+	switch {
+	case isUniqueViolation(err, categoriesSlugConstraint):
+		return nil, category.ErrSlugAlreadyExists
+	case isForeignKeyViolation(err, categoriesParentConstraint):
+		return nil, category.ErrParentNotFound
+	case err != nil:
+		cr.logger.Errorf("could not create category :%v", err)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// UpdateCategory renames and moves the category. Moves are serialized by a
+// table lock, so that concurrent moves can not sneak a cycle into the tree.
+func (cr *postgresCategoryRepository) UpdateCategory(
+	ctx context.Context, c *category.Category) (*category.Category, error) {
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if c.ParentID != nil {
+		if _, err = tx.ExecContext(ctx,
+			`LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return nil, err
+		}
+
+		var cycle bool
+		if err = tx.QueryRowContext(ctx,
+			`WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM categories WHERE id = $1
+				UNION
+				SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`,
+			*c.ParentID, c.ID,
+		).Scan(&cycle); err != nil {
+			return nil, err
+		}
+
+		if cycle {
+			return nil, category.ErrCycle
+		}
+	}
+
+	row := tx.QueryRowContext(ctx,
+		`UPDATE categories SET name = $2, parent_id = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+categoryColumns,
+		c.ID, c.Name, c.ParentID,
+	)
+
+	updated, err := scanCategory(row)
+	switch {
+	case isForeignKeyViolation(err, categoriesParentConstraint):
+		return nil, category.ErrParentNotFound
+	case err != nil:
+		if !errors.Is(err, sql.ErrNoRows) {
+			cr.logger.Errorf("could not update category :%v", err)
+		}
+		return nil, err
+	}
+
+	return updated, tx.Commit()
+}
+
+// DeleteCategory deletes a leaf category no product, not even a soft deleted
+// one, refers to. The products refer to the category through a foreign key,
+// a product created in it concurrently either blocks the deletion or fails.
+func (cr *postgresCategoryRepository) DeleteCategory(ctx context.Context, id string) error {
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var slug string
+	if err = tx.QueryRowContext(ctx,
+		`SELECT slug FROM categories WHERE id = $1 FOR UPDATE`, id).Scan(&slug); err != nil {
+		return err
+	}
+
+	var hasChildren, inUse bool
+	if err = tx.QueryRowContext(ctx,
+		`SELECT
+			EXISTS (SELECT 1 FROM categories WHERE parent_id = $1),
+			EXISTS (SELECT 1 FROM products WHERE type = $2)`,
+		id, slug,
+	).Scan(&hasChildren, &inUse); err != nil {
+		return err
+	}
+
+	switch {
+	case hasChildren:
+		return category.ErrHasChildren
+	case inUse:
+		return category.ErrInUse
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id); err != nil {
+		if isForeignKeyViolation(err, categoriesParentConstraint) {
+			return category.ErrHasChildren
+		}
+
+		if isForeignKeyViolation(err, productsTypeConstraint) {
+			return category.ErrInUse
+		}
+
+		cr.logger.Errorf("could not delete category :%v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,143 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/pact-cdc-example/product-service/app/product"
+)
+
+// the images of a product are kept sorted by their position.
+
+func (mr *memoryRepository) ListImages(
+	ctx context.Context, productID string) ([]product.Image, error) {
+	return mr.GetImagesByProductIDs(ctx, []string{productID})
+}
+
+func (mr *memoryRepository) GetImagesByProductIDs(
+	ctx context.Context, productIDs []string) ([]product.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var images []product.Image
+	for _, id := range product.UniqueIDs(productIDs) {
+		images = append(images, mr.images[id]...)
+	}
+
+	return images, nil
+}
+
+func (mr *memoryRepository) GetImage(
+	ctx context.Context, productID, id string) (*product.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	for _, image := range mr.images[productID] {
+		if image.ID == id {
+			return &image, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (mr *memoryRepository) CreateImage(
+	ctx context.Context, image *product.Image) (*product.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.products[image.ProductID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	images := mr.images[image.ProductID]
+	for _, existing := range images {
+		if existing.ID == image.ID {
+			return nil, fmt.Errorf("image %s already exists", image.ID)
+		}
+	}
+
+	created := *image
+	created.ThumbnailSizes = append([]int(nil), image.ThumbnailSizes...)
+	created.Position = 0
+	if len(images) > 0 {
+		created.Position = images[len(images)-1].Position + 1
+	}
+	created.CreatedAt = memoryNow()
+	mr.images[image.ProductID] = append(images, created)
+
+	return &created, nil
+}
+
+// ReorderImages sets the positions of the listed images, the ones left out
+// keep their position.
+func (mr *memoryRepository) ReorderImages(
+	ctx context.Context, productID string, ids []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	positions := make(map[string]int, len(ids))
+	for i, id := range ids {
+		positions[id] = i
+	}
+
+	images := append([]product.Image(nil), mr.images[productID]...)
+	for i := range images {
+		if position, ok := positions[images[i].ID]; ok {
+			images[i].Position = position
+		}
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].Position < images[j].Position
+	})
+	mr.images[productID] = images
+
+	return nil
+}
+
+func (mr *memoryRepository) DeleteImage(ctx context.Context, productID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	images := mr.images[productID]
+	for i, image := range images {
+		if image.ID != id {
+			continue
+		}
+
+		images = append(images[:i:i], images[i+1:]...)
+		for j := i; j < len(images); j++ {
+			if images[j].Position > image.Position {
+				images[j].Position--
+			}
+		}
+		mr.images[productID] = images
+
+		return nil
+	}
+
+	return sql.ErrNoRows
+}
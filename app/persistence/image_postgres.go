@@ -0,0 +1,180 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/pact-cdc-example/product-service/app/product"
+)
+
+const imagesProductConstraint = "product_images_product_id_fkey"
+
+const imageColumns = `id, product_id, position, key, content_type, size, width, height,
+	thumbnail_sizes, created_at`
+
+func scanImage(row rowScanner) (*product.Image, error) {
+	var i product.Image
+	var thumbnailSizes pq.Int64Array
+	if err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Position,
+		&i.Key,
+		&i.ContentType,
+		&i.Size,
+		&i.Width,
+		&i.Height,
+		&thumbnailSizes,
+		&i.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	for _, size := range thumbnailSizes {
+		i.ThumbnailSizes = append(i.ThumbnailSizes, int(size))
+	}
+
+	return &i, nil
+}
+
+func (pr *postgresRepository) ListImages(
+	ctx context.Context, productID string) ([]product.Image, error) {
+	return pr.queryImages(ctx,
+		`SELECT `+imageColumns+` FROM product_images
+		WHERE product_id = $1 ORDER BY position, created_at, id`,
+		productID,
+	)
+}
+
+func (pr *postgresRepository) GetImagesByProductIDs(
+	ctx context.Context, productIDs []string) ([]product.Image, error) {
+	return pr.queryImages(ctx,
+		`SELECT `+imageColumns+` FROM product_images
+		WHERE product_id = ANY($1) ORDER BY product_id, position, created_at, id`,
+		pq.Array(product.UniqueIDs(productIDs)),
+	)
+}
+
+func (pr *postgresRepository) queryImages(
+	ctx context.Context, query string, args ...interface{}) ([]product.Image, error) {
+	rows, err := pr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		pr.logger.Errorf("could not query images :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []product.Image
+	for rows.Next() {
+		i, err := scanImage(rows)
+		if err != nil {
+			pr.logger.Errorf("could not scan image :%v", err)
+			return nil, err
+		}
+
+		images = append(images, *i)
+	}
+
+	return images, rows.Err()
+}
+
+func (pr *postgresRepository) GetImage(
+	ctx context.Context, productID, id string) (*product.Image, error) {
+	row := pr.db.QueryRowContext(ctx,
+		`SELECT `+imageColumns+` FROM product_images WHERE id = $1 AND product_id = $2`,
+		id, productID,
+	)
+
+	i, err := scanImage(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			pr.logger.Errorf("could not get image :%v", err)
+		}
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// CreateImage returns sql.ErrNoRows when the product does not exist.
+func (pr *postgresRepository) CreateImage(
+	ctx context.Context, i *product.Image) (*product.Image, error) {
+	thumbnailSizes := make(pq.Int64Array, 0, len(i.ThumbnailSizes))
+	for _, size := range i.ThumbnailSizes {
+		thumbnailSizes = append(thumbnailSizes, int64(size))
+	}
+
+	row := pr.db.QueryRowContext(ctx,
+		`INSERT INTO product_images (id, product_id, position, key, content_type, size,
+		width, height, thumbnail_sizes)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position) + 1, 0) FROM product_images
+		WHERE product_id = $2), $3, $4, $5, $6, $7, $8)
+		RETURNING `+imageColumns,
+		i.ID, i.ProductID, i.Key, i.ContentType, i.Size, i.Width, i.Height, thumbnailSizes,
+	)
+
+	created, err := scanImage(row)
+	switch {
+	case isForeignKeyViolation(err, imagesProductConstraint):
+		return nil, sql.ErrNoRows
+	case err != nil:
+		pr.logger.Errorf("could not create image :%v", err)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// ReorderImages sets the positions of the listed images at once, the ones
+// left out keep their position.
+func (pr *postgresRepository) ReorderImages(
+	ctx context.Context, productID string, ids []string) error {
+	_, err := pr.db.ExecContext(ctx,
+		`UPDATE product_images SET position = ordered.position - 1
+		FROM unnest($2::TEXT[]) WITH ORDINALITY AS ordered(id, position)
+		WHERE product_images.id = ordered.id AND product_images.product_id = $1`,
+		productID, pq.Array(ids),
+	)
+	if err != nil {
+		pr.logger.Errorf("could not reorder images :%v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteImage closes the gap the image leaves in the positions of the images
+// of its product.
+func (pr *postgresRepository) DeleteImage(ctx context.Context, productID, id string) error {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		pr.logger.Errorf("could not begin transaction :%v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var position int
+	err = tx.QueryRowContext(ctx,
+		`DELETE FROM product_images WHERE id = $1 AND product_id = $2 RETURNING position`,
+		id, productID,
+	).Scan(&position)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			pr.logger.Errorf("could not delete image :%v", err)
+		}
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		`UPDATE product_images SET position = position - 1
+		WHERE product_id = $1 AND position > $2`,
+		productID, position,
+	); err != nil {
+		pr.logger.Errorf("could not delete image :%v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,261 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/pact-cdc-example/product-service/app/inventory"
+	"github.com/pact-cdc-example/product-service/app/product"
+)
+
+type stockKey struct {
+	productID string
+	warehouse string
+}
+
+type memoryInventoryRepository struct {
+	mu           sync.Mutex
+	stocks       map[stockKey]inventory.Stock
+	reservations map[string]inventory.Reservation
+	products     product.Repository
+}
+
+// NewMemoryInventoryRepository returns a concurrency safe
+// inventory.Repository keeping the stock in memory. Stock is only kept for
+// the existing products of the given repository.
+func NewMemoryInventoryRepository(products product.Repository) inventory.Repository {
+	return &memoryInventoryRepository{
+		stocks:       make(map[stockKey]inventory.Stock),
+		reservations: make(map[string]inventory.Reservation),
+		products:     products,
+	}
+}
+
+func (mr *memoryInventoryRepository) GetStock(
+	ctx context.Context, productID string) ([]inventory.Stock, error) {
+	if err := mr.productExists(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	reserved := mr.reserved()
+	var stocks []inventory.Stock
+	for key, s := range mr.stocks {
+		if key.productID == productID {
+			s.Reserved = reserved[key]
+			stocks = append(stocks, s)
+		}
+	}
+
+	sort.Slice(stocks, func(i, j int) bool {
+		return stocks[i].Warehouse < stocks[j].Warehouse
+	})
+
+	return stocks, nil
+}
+
+func (mr *memoryInventoryRepository) Available(
+	ctx context.Context, productIDs []string) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	wanted := make(map[string]bool, len(productIDs))
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+
+	reserved := mr.reserved()
+	available := make(map[string]int, len(productIDs))
+	for key, s := range mr.stocks {
+		if wanted[key.productID] {
+			available[key.productID] += s.OnHand - reserved[key]
+		}
+	}
+
+	return available, nil
+}
+
+func (mr *memoryInventoryRepository) AdjustStock(
+	ctx context.Context, productID, warehouse string, delta int) (*inventory.Stock, error) {
+	if err := mr.productExists(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	key := stockKey{productID: productID, warehouse: warehouse}
+	s, ok := mr.stocks[key]
+	if !ok {
+		s = inventory.Stock{ProductID: productID, Warehouse: warehouse}
+	}
+
+	s.Reserved = mr.reserved()[key]
+	if s.OnHand+delta < s.Reserved {
+		return nil, &inventory.InsufficientStockError{
+			ProductID: productID,
+			Warehouse: warehouse,
+			Requested: -delta,
+			Available: s.Available(),
+		}
+	}
+
+	s.OnHand += delta
+	s.UpdatedAt = memoryNow()
+	mr.stocks[key] = inventory.Stock{
+		ProductID: s.ProductID, Warehouse: s.Warehouse, OnHand: s.OnHand, UpdatedAt: s.UpdatedAt}
+
+	return &s, nil
+}
+
+func (mr *memoryInventoryRepository) Reserve(
+	ctx context.Context, r *inventory.Reservation) (*inventory.Reservation, error) {
+	for _, item := range r.Items {
+		if err := mr.productExists(ctx, item.ProductID); err != nil {
+			return nil, err
+		}
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	reserved := mr.reserved()
+	for _, item := range sortedItems(r.Items) {
+		key := stockKey{productID: item.ProductID, warehouse: item.Warehouse}
+		available := mr.stocks[key].OnHand - reserved[key]
+		if available < item.Quantity {
+			return nil, &inventory.InsufficientStockError{
+				ProductID: item.ProductID,
+				Warehouse: item.Warehouse,
+				Requested: item.Quantity,
+				Available: available,
+			}
+		}
+	}
+
+	created := *r
+	created.Items = sortedItems(r.Items)
+	created.CreatedAt = memoryNow()
+	created.UpdatedAt = created.CreatedAt
+	mr.reservations[created.ID] = created
+
+	return copyReservation(created), nil
+}
+
+func (mr *memoryInventoryRepository) GetReservation(
+	ctx context.Context, id string) (*inventory.Reservation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	r, ok := mr.reservations[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return copyReservation(r), nil
+}
+
+func (mr *memoryInventoryRepository) CommitReservation(
+	ctx context.Context, id string) (*inventory.Reservation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	r, ok := mr.reservations[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	now := memoryNow()
+	if err := pendingReservation(&r, now); err != nil {
+		return nil, err
+	}
+
+	for _, item := range r.Items {
+		key := stockKey{productID: item.ProductID, warehouse: item.Warehouse}
+		s := mr.stocks[key]
+		s.OnHand -= item.Quantity
+		s.UpdatedAt = now
+		mr.stocks[key] = s
+	}
+
+	r.Status, r.UpdatedAt = inventory.StatusCommitted, now
+	mr.reservations[id] = r
+
+	return copyReservation(r), nil
+}
+
+func (mr *memoryInventoryRepository) ReleaseReservation(
+	ctx context.Context, id string) (*inventory.Reservation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	r, ok := mr.reservations[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	if r.Status != inventory.StatusPending {
+		return nil, inventory.ErrReservationNotPending
+	}
+
+	r.Status, r.UpdatedAt = inventory.StatusReleased, memoryNow()
+	mr.reservations[id] = r
+
+	return copyReservation(r), nil
+}
+
+// reserved sums the quantities held by the active reservations per stock,
+// the caller must hold the lock.
+func (mr *memoryInventoryRepository) reserved() map[stockKey]int {
+	now := memoryNow()
+	reserved := make(map[stockKey]int)
+	for _, r := range mr.reservations {
+		if r.EffectiveStatus(now) != inventory.StatusPending {
+			continue
+		}
+
+		for _, item := range r.Items {
+			reserved[stockKey{productID: item.ProductID, warehouse: item.Warehouse}] +=
+				item.Quantity
+		}
+	}
+
+	return reserved
+}
+
+func (mr *memoryInventoryRepository) productExists(ctx context.Context, productID string) error {
+	if _, err := mr.products.GetProductByID(ctx, productID, product.FindOptions{}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return inventory.ErrProductNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func copyReservation(r inventory.Reservation) *inventory.Reservation {
+	r.Items = append([]inventory.ReservationItem(nil), r.Items...)
+	return &r
+}
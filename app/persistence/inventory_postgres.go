@@ -0,0 +1,411 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pact-cdc-example/product-service/app/inventory"
+	"github.com/sirupsen/logrus"
+)
+
+// activeReservedQuery sums the quantities held by the pending, unexpired
+// reservations of the products of $1 per warehouse.
+const activeReservedQuery = `SELECT i.product_id, i.warehouse, SUM(i.quantity) AS reserved
+	FROM inventory_reservation_items i
+	JOIN inventory_reservations r ON r.id = i.reservation_id
+	WHERE r.status = 'pending' AND r.expires_at > NOW() AND i.product_id = ANY($1)
+	GROUP BY i.product_id, i.warehouse`
+
+const reservationColumns = `id, basket_id, status, expires_at, created_at, updated_at`
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type postgresInventoryRepository struct {
+	db     *sql.DB
+	logger *logrus.Logger
+}
+
+type NewPostgresInventoryRepositoryOpts struct {
+	DB *sql.DB
+	L  *logrus.Logger
+}
+
+// NewPostgresInventoryRepository returns an inventory.Repository keeping
+// reservations race-safe by locking the stock rows they draw from. Rows are
+// always locked in product and warehouse order, so that concurrent
+// reservations can not deadlock.
+func NewPostgresInventoryRepository(
+	opts *NewPostgresInventoryRepositoryOpts) inventory.Repository {
+	return &postgresInventoryRepository{
+		db:     opts.DB,
+		logger: opts.L,
+	}
+}
+
+func (ir *postgresInventoryRepository) GetStock(
+	ctx context.Context, productID string) ([]inventory.Stock, error) {
+	if err := productExists(ctx, ir.db, productID); err != nil {
+		return nil, err
+	}
+
+	rows, err := ir.db.QueryContext(ctx,
+		`SELECT s.product_id, s.warehouse, s.on_hand, COALESCE(a.reserved, 0), s.updated_at
+		FROM inventory_stock s
+		LEFT JOIN (`+activeReservedQuery+`) a USING (product_id, warehouse)
+		WHERE s.product_id = ANY($1)
+		ORDER BY s.warehouse`,
+		pq.Array([]string{productID}),
+	)
+	if err != nil {
+		ir.logger.Errorf("could not get stock :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stocks []inventory.Stock
+	for rows.Next() {
+		var s inventory.Stock
+		if err = rows.Scan(
+			&s.ProductID, &s.Warehouse, &s.OnHand, &s.Reserved, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		stocks = append(stocks, s)
+	}
+
+	return stocks, rows.Err()
+}
+
+func (ir *postgresInventoryRepository) Available(
+	ctx context.Context, productIDs []string) (map[string]int, error) {
+	available := make(map[string]int, len(productIDs))
+	if len(productIDs) == 0 {
+		return available, nil
+	}
+
+	rows, err := ir.db.QueryContext(ctx,
+		`SELECT s.product_id, SUM(s.on_hand - COALESCE(a.reserved, 0))
+		FROM inventory_stock s
+		LEFT JOIN (`+activeReservedQuery+`) a USING (product_id, warehouse)
+		WHERE s.product_id = ANY($1)
+		GROUP BY s.product_id`,
+		pq.Array(productIDs),
+	)
+	if err != nil {
+		ir.logger.Errorf("could not get available stock :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID string
+		var quantity int
+		if err = rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+
+		available[productID] = quantity
+	}
+
+	return available, rows.Err()
+}
+
+func (ir *postgresInventoryRepository) AdjustStock(
+	ctx context.Context, productID, warehouse string, delta int) (*inventory.Stock, error) {
+	tx, err := ir.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = productExists(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		`INSERT INTO inventory_stock (product_id, warehouse, on_hand) VALUES ($1, $2, 0)
+		ON CONFLICT (product_id, warehouse) DO NOTHING`,
+		productID, warehouse,
+	); err != nil {
+		ir.logger.Errorf("could not create stock :%v", err)
+		return nil, err
+	}
+
+	stock, err := lockStock(ctx, tx, inventory.ReservationItem{
+		ProductID: productID, Warehouse: warehouse})
+	if err != nil {
+		return nil, err
+	}
+
+	if stock.OnHand+delta < stock.Reserved {
+		return nil, &inventory.InsufficientStockError{
+			ProductID: productID,
+			Warehouse: warehouse,
+			Requested: -delta,
+			Available: stock.Available(),
+		}
+	}
+
+	if err = tx.QueryRowContext(ctx,
+		`UPDATE inventory_stock SET on_hand = on_hand + $3, updated_at = NOW()
+		WHERE product_id = $1 AND warehouse = $2
+		RETURNING on_hand, updated_at`,
+		productID, warehouse, delta,
+	).Scan(&stock.OnHand, &stock.UpdatedAt); err != nil {
+		ir.logger.Errorf("could not adjust stock :%v", err)
+		return nil, err
+	}
+
+	return stock, tx.Commit()
+}
+
+func (ir *postgresInventoryRepository) Reserve(
+	ctx context.Context, r *inventory.Reservation) (*inventory.Reservation, error) {
+	tx, err := ir.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	items := sortedItems(r.Items)
+	for _, item := range items {
+		if err = productExists(ctx, tx, item.ProductID); err != nil {
+			return nil, err
+		}
+
+		stock, err := lockStock(ctx, tx, item)
+		if errors.Is(err, sql.ErrNoRows) {
+			stock, err = &inventory.Stock{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if stock.Available() < item.Quantity {
+			return nil, &inventory.InsufficientStockError{
+				ProductID: item.ProductID,
+				Warehouse: item.Warehouse,
+				Requested: item.Quantity,
+				Available: stock.Available(),
+			}
+		}
+	}
+
+	created := *r
+	if err = tx.QueryRowContext(ctx,
+		`INSERT INTO inventory_reservations (id, basket_id, status, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at`,
+		r.ID, r.BasketID, r.Status, r.ExpiresAt,
+	).Scan(&created.CreatedAt, &created.UpdatedAt); err != nil {
+		ir.logger.Errorf("could not create reservation :%v", err)
+		return nil, err
+	}
+
+	for _, item := range items {
+		if _, err = tx.ExecContext(ctx,
+			`INSERT INTO inventory_reservation_items (reservation_id, product_id, warehouse, quantity)
+			VALUES ($1, $2, $3, $4)`,
+			r.ID, item.ProductID, item.Warehouse, item.Quantity,
+		); err != nil {
+			ir.logger.Errorf("could not create reservation item :%v", err)
+			return nil, err
+		}
+	}
+
+	return &created, tx.Commit()
+}
+
+func (ir *postgresInventoryRepository) GetReservation(
+	ctx context.Context, id string) (*inventory.Reservation, error) {
+	return getReservation(ctx, ir.db, id, false)
+}
+
+// CommitReservation takes the reserved quantities off the stock.
+func (ir *postgresInventoryRepository) CommitReservation(
+	ctx context.Context, id string) (*inventory.Reservation, error) {
+	tx, err := ir.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	r, err := getReservation(ctx, tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = pendingReservation(r, time.Now()); err != nil {
+		return nil, err
+	}
+
+	for _, item := range sortedItems(r.Items) {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE inventory_stock SET on_hand = on_hand - $3, updated_at = NOW()
+			WHERE product_id = $1 AND warehouse = $2 AND on_hand >= $3`,
+			item.ProductID, item.Warehouse, item.Quantity,
+		)
+		if err != nil {
+			ir.logger.Errorf("could not commit stock :%v", err)
+			return nil, err
+		}
+
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return nil, fmt.Errorf("stock of product %s in warehouse %s is below its reservation",
+				item.ProductID, item.Warehouse)
+		}
+	}
+
+	return ir.setReservationStatus(ctx, tx, r, inventory.StatusCommitted)
+}
+
+// ReleaseReservation gives the reserved quantities back, expired
+// reservations can be released too.
+func (ir *postgresInventoryRepository) ReleaseReservation(
+	ctx context.Context, id string) (*inventory.Reservation, error) {
+	tx, err := ir.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	r, err := getReservation(ctx, tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Status != inventory.StatusPending {
+		return nil, inventory.ErrReservationNotPending
+	}
+
+	return ir.setReservationStatus(ctx, tx, r, inventory.StatusReleased)
+}
+
+func (ir *postgresInventoryRepository) setReservationStatus(ctx context.Context,
+	tx *sql.Tx, r *inventory.Reservation, status inventory.ReservationStatus) (
+	*inventory.Reservation, error) {
+	if err := tx.QueryRowContext(ctx,
+		`UPDATE inventory_reservations SET status = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING status, updated_at`,
+		r.ID, status,
+	).Scan(&r.Status, &r.UpdatedAt); err != nil {
+		ir.logger.Errorf("could not update reservation :%v", err)
+		return nil, err
+	}
+
+	return r, tx.Commit()
+}
+
+// getReservation reads the reservation with its items, locking the
+// reservation row when forUpdate is set.
+func getReservation(
+	ctx context.Context, q querier, id string, forUpdate bool) (*inventory.Reservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM inventory_reservations WHERE id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var r inventory.Reservation
+	if err := q.QueryRowContext(ctx, query, id).Scan(
+		&r.ID, &r.BasketID, &r.Status, &r.ExpiresAt, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT product_id, warehouse, quantity FROM inventory_reservation_items
+		WHERE reservation_id = $1 ORDER BY product_id, warehouse`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item inventory.ReservationItem
+		if err = rows.Scan(&item.ProductID, &item.Warehouse, &item.Quantity); err != nil {
+			return nil, err
+		}
+
+		r.Items = append(r.Items, item)
+	}
+
+	return &r, rows.Err()
+}
+
+// lockStock locks the stock row of the item and returns it along with the
+// quantity its active reservations hold.
+func lockStock(
+	ctx context.Context, tx *sql.Tx, item inventory.ReservationItem) (*inventory.Stock, error) {
+	stock := inventory.Stock{ProductID: item.ProductID, Warehouse: item.Warehouse}
+	if err := tx.QueryRowContext(ctx,
+		`SELECT on_hand, updated_at FROM inventory_stock
+		WHERE product_id = $1 AND warehouse = $2
+		FOR UPDATE`,
+		item.ProductID, item.Warehouse,
+	).Scan(&stock.OnHand, &stock.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(reserved), 0) FROM (`+activeReservedQuery+`) a
+		WHERE warehouse = $2`,
+		pq.Array([]string{item.ProductID}), item.Warehouse,
+	).Scan(&stock.Reserved); err != nil {
+		return nil, err
+	}
+
+	return &stock, nil
+}
+
+func productExists(ctx context.Context, q querier, productID string) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)`,
+		productID,
+	).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return inventory.ErrProductNotFound
+	}
+
+	return nil
+}
+
+// sortedItems returns the items in the order their stock rows are locked in.
+func sortedItems(items []inventory.ReservationItem) []inventory.ReservationItem {
+	sorted := append([]inventory.ReservationItem(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].ProductID != sorted[j].ProductID {
+			return sorted[i].ProductID < sorted[j].ProductID
+		}
+
+		return sorted[i].Warehouse < sorted[j].Warehouse
+	})
+
+	return sorted
+}
+
+// pendingReservation fails unless the reservation can still be committed.
+func pendingReservation(r *inventory.Reservation, now time.Time) error {
+	switch r.EffectiveStatus(now) {
+	case inventory.StatusPending:
+		return nil
+	case inventory.StatusExpired:
+		return inventory.ErrReservationExpired
+	default:
+		return inventory.ErrReservationNotPending
+	}
+}
@@ -0,0 +1,517 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/product"
+)
+
+type memoryRepository struct {
+	mu       sync.RWMutex
+	products map[string]product.Product
+	variants map[string]product.Variant
+	// prices holds the scheduled prices by product id.
+	prices map[string][]product.ScheduledPrice
+	// images holds the images by product id, in their order.
+	images map[string][]product.Image
+}
+
+// NewMemoryRepository returns a concurrency safe product.Repository keeping
+// the products in memory. It is meant for local development and tests and
+// mimics the postgres repository, e.g. returning sql.ErrNoRows when a
+// product does not exist.
+func NewMemoryRepository() product.Repository {
+	return &memoryRepository{
+		products: make(map[string]product.Product),
+		variants: make(map[string]product.Variant),
+		prices:   make(map[string][]product.ScheduledPrice),
+		images:   make(map[string][]product.Image),
+	}
+}
+
+func (mr *memoryRepository) GetProductByID(
+	ctx context.Context, id string, opts product.FindOptions) (*product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	p, ok := mr.products[id]
+	if !ok || (p.DeletedAt != nil && !opts.IncludeDeleted) {
+		return nil, sql.ErrNoRows
+	}
+
+	return &p, nil
+}
+
+func (mr *memoryRepository) GetProductByCode(
+	ctx context.Context, code string) (*product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	for _, p := range mr.products {
+		if p.Code == code && p.DeletedAt == nil {
+			return &p, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (mr *memoryRepository) GetProductsByIDs(
+	ctx context.Context, ids []string, opts product.FindOptions) ([]product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	ids = product.UniqueIDs(ids)
+	products := make([]product.Product, 0, len(ids))
+	for _, id := range ids {
+		p, ok := mr.products[id]
+		if !ok || (p.DeletedAt != nil && !opts.IncludeDeleted) {
+			continue
+		}
+
+		products = append(products, p)
+	}
+
+	return products, nil
+}
+
+func (mr *memoryRepository) ListProducts(
+	ctx context.Context, filter product.ListFilter) ([]product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	less, err := memorySortLess(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *product.Product
+	if filter.After != nil {
+		if after, err = memoryCursorProduct(filter.Sort, filter.After); err != nil {
+			return nil, err
+		}
+	}
+
+	mr.mu.RLock()
+	products := make([]product.Product, 0, len(mr.products))
+	for _, p := range mr.products {
+		if matchesListFilter(&p, filter) && (after == nil || less(after, &p)) {
+			products = append(products, p)
+		}
+	}
+	mr.mu.RUnlock()
+
+	sort.Slice(products, func(i, j int) bool {
+		return less(&products[i], &products[j])
+	})
+
+	if filter.Limit > 0 && len(products) > filter.Limit {
+		products = products[:filter.Limit]
+	}
+
+	return products, nil
+}
+
+// SearchProducts ranks the products with a pure Go approximation of the
+// postgres full-text search, falling back to trigram similarity likewise.
+func (mr *memoryRepository) SearchProducts(
+	ctx context.Context, filter product.SearchFilter) ([]product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := parseSearchQuery(filter.Query)
+	if products := mr.rankProducts(filter, query.rank); len(products) > 0 {
+		return products, nil
+	}
+
+	return mr.rankProducts(filter, func(p *product.Product) float64 {
+		if similarity := wordSimilarity(filter.Query, p); similarity >= wordSimilarityThreshold {
+			return similarity
+		}
+
+		return 0
+	}), nil
+}
+
+// rankProducts returns the products of positive rank, best first.
+func (mr *memoryRepository) rankProducts(
+	filter product.SearchFilter, rank func(*product.Product) float64) []product.Product {
+	type rankedProduct struct {
+		product product.Product
+		rank    float64
+	}
+
+	mr.mu.RLock()
+	var ranked []rankedProduct
+	for _, p := range mr.products {
+		if p.DeletedAt != nil && !filter.IncludeDeleted {
+			continue
+		}
+
+		if r := rank(&p); r > 0 {
+			ranked = append(ranked, rankedProduct{product: p, rank: r})
+		}
+	}
+	mr.mu.RUnlock()
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].rank != ranked[j].rank {
+			return ranked[i].rank > ranked[j].rank
+		}
+
+		return ranked[i].product.ID < ranked[j].product.ID
+	})
+
+	if filter.Limit > 0 && len(ranked) > filter.Limit {
+		ranked = ranked[:filter.Limit]
+	}
+
+	products := make([]product.Product, 0, len(ranked))
+	for _, r := range ranked {
+		products = append(products, r.product)
+	}
+
+	return products
+}
+
+// ExportProducts passes every product matching the filter to fn. The
+// products are listed upfront, so fn may use the repository.
+func (mr *memoryRepository) ExportProducts(
+	ctx context.Context, filter product.ListFilter, fn func(*product.Product) error) error {
+	filter.Limit = 0
+	products, err := mr.ListProducts(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		if err = fn(&products[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (mr *memoryRepository) CreateProduct(
+	ctx context.Context, p *product.Product, changedBy string) (*product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.products[p.ID]; ok {
+		return nil, fmt.Errorf("product %s already exists", p.ID)
+	}
+
+	if mr.codeTaken(p.Code, p.ID) {
+		return nil, product.ErrProductCodeAlreadyExists
+	}
+
+	now := memoryNow()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	mr.products[p.ID] = *p
+	mr.recordPrice(p, changedBy)
+
+	return p, nil
+}
+
+func (mr *memoryRepository) CreateProducts(ctx context.Context,
+	products []*product.Product, changedBy string) ([]*product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	ids := make(map[string]struct{}, len(products))
+	codes := make(map[string]struct{}, len(products))
+	for _, p := range products {
+		if _, ok := mr.products[p.ID]; ok {
+			return nil, fmt.Errorf("product %s already exists", p.ID)
+		}
+
+		if _, ok := ids[p.ID]; ok {
+			return nil, fmt.Errorf("product %s already exists", p.ID)
+		}
+
+		_, ok := codes[p.Code]
+		if ok || mr.codeTaken(p.Code, p.ID) {
+			return nil, &product.ProductCodeConflictError{Code: p.Code}
+		}
+
+		ids[p.ID] = struct{}{}
+		codes[p.Code] = struct{}{}
+	}
+
+	now := memoryNow()
+	for _, p := range products {
+		p.CreatedAt = now
+		p.UpdatedAt = now
+		mr.products[p.ID] = *p
+		mr.recordPrice(p, changedBy)
+	}
+
+	return products, nil
+}
+
+func (mr *memoryRepository) CreateProductsSkippingConflicts(ctx context.Context,
+	products []*product.Product, changedBy string) ([]*product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	created := make([]*product.Product, 0, len(products))
+	now := memoryNow()
+	for _, p := range products {
+		if _, ok := mr.products[p.ID]; ok {
+			return created, fmt.Errorf("product %s already exists", p.ID)
+		}
+
+		if mr.codeTaken(p.Code, p.ID) {
+			continue
+		}
+
+		p.CreatedAt = now
+		p.UpdatedAt = now
+		mr.products[p.ID] = *p
+		mr.recordPrice(p, changedBy)
+		created = append(created, p)
+	}
+
+	return created, nil
+}
+
+func (mr *memoryRepository) UpdateProduct(
+	ctx context.Context, p *product.Product, changedBy string) (*product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	existing, ok := mr.products[p.ID]
+	if !ok || existing.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	if mr.codeTaken(p.Code, p.ID) {
+		return nil, product.ErrProductCodeAlreadyExists
+	}
+
+	p.CreatedAt = existing.CreatedAt
+	p.UpdatedAt = memoryNow()
+	p.DeletedAt = nil
+	mr.products[p.ID] = *p
+	if existing.SellingPrice != p.SellingPrice {
+		mr.recordPrice(p, changedBy)
+	}
+
+	return p, nil
+}
+
+// recordPrice adds the selling price of the saved product to its price
+// history, like the postgres repository does in the same transaction. The
+// caller holds the write lock.
+func (mr *memoryRepository) recordPrice(p *product.Product, changedBy string) {
+	mr.prices[p.ID] = append(mr.prices[p.ID], product.ScheduledPrice{
+		ID:        uuid.New().String(),
+		ProductID: p.ID,
+		Price:     p.SellingPrice,
+		ValidFrom: p.UpdatedAt,
+		ChangedBy: changedBy,
+		CreatedAt: p.UpdatedAt,
+	})
+}
+
+func (mr *memoryRepository) DeleteProduct(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	p, ok := mr.products[id]
+	if !ok || p.DeletedAt != nil {
+		return sql.ErrNoRows
+	}
+
+	now := memoryNow()
+	p.DeletedAt = &now
+	p.UpdatedAt = now
+	mr.products[id] = p
+
+	return nil
+}
+
+func (mr *memoryRepository) RestoreProduct(
+	ctx context.Context, id string) (*product.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	p, ok := mr.products[id]
+	if !ok || p.DeletedAt == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	p.DeletedAt = nil
+	p.UpdatedAt = memoryNow()
+	mr.products[id] = p
+
+	return &p, nil
+}
+
+// codeTaken reports whether a product other than id uses the code. Deleted
+// products keep their code reserved like the postgres unique constraint does.
+func (mr *memoryRepository) codeTaken(code, id string) bool {
+	for _, p := range mr.products {
+		if p.Code == code && p.ID != id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// memoryNow returns the current time with the precision postgres stores.
+func memoryNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func matchesListFilter(p *product.Product, filter product.ListFilter) bool {
+	if p.DeletedAt != nil && !filter.IncludeDeleted {
+		return false
+	}
+
+	if !matchesAny(string(p.Type), filter.Types) ||
+		!matchesAny(p.Color, filter.Colors) ||
+		!matchesAny(p.Provider, filter.Providers) ||
+		!matchesAny(p.Distributor, filter.Distributors) {
+		return false
+	}
+
+	if filter.MinPrice != nil && (p.Currency() != filter.MinPrice.Currency ||
+		p.SellingPrice.Amount < filter.MinPrice.Amount) {
+		return false
+	}
+
+	return filter.MaxPrice == nil || (p.Currency() == filter.MaxPrice.Currency &&
+		p.SellingPrice.Amount <= filter.MaxPrice.Amount)
+}
+
+func matchesAny(value string, candidates []string) bool {
+	if len(candidates) == 0 {
+		return true
+	}
+
+	for _, candidate := range candidates {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// memorySortLess returns the ordering of the listing, ties on the sort field
+// are broken by id just like the postgres listing does.
+func memorySortLess(listSort product.ListSort) (func(a, b *product.Product) bool, error) {
+	var compare func(a, b *product.Product) int
+	switch listSort.Field {
+	case product.SortByCreatedAt:
+		compare = func(a, b *product.Product) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	case product.SortBySellingPrice:
+		compare = func(a, b *product.Product) int {
+			if result := strings.Compare(
+				string(a.Currency()), string(b.Currency())); result != 0 {
+				return result
+			}
+
+			switch {
+			case a.SellingPrice.Amount < b.SellingPrice.Amount:
+				return -1
+			case a.SellingPrice.Amount > b.SellingPrice.Amount:
+				return 1
+			}
+			return 0
+		}
+	case product.SortByName:
+		compare = func(a, b *product.Product) int { return strings.Compare(a.Name, b.Name) }
+	default:
+		return nil, fmt.Errorf("unsupported sort field %q", listSort.Field)
+	}
+
+	return func(a, b *product.Product) bool {
+		result := compare(a, b)
+		if result == 0 {
+			result = strings.Compare(a.ID, b.ID)
+		}
+
+		if listSort.Descending {
+			return result > 0
+		}
+
+		return result < 0
+	}, nil
+}
+
+// memoryCursorProduct turns the cursor into a product carrying the sort key,
+// so it can be compared with the listed products.
+func memoryCursorProduct(
+	listSort product.ListSort, cursor *product.Cursor) (*product.Product, error) {
+	p := product.Product{ID: cursor.ID}
+
+	var err error
+	switch listSort.Field {
+	case product.SortByCreatedAt:
+		p.CreatedAt, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	case product.SortBySellingPrice:
+		p.SellingPrice, err = cursor.Price()
+	case product.SortByName:
+		p.Name = cursor.Value
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,38 @@
+package persistence_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/app/category"
+	"github.com/pact-cdc-example/product-service/app/inventory"
+	"github.com/pact-cdc-example/product-service/app/persistence"
+	"github.com/pact-cdc-example/product-service/app/persistence/repositorytest"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/app/promotion"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) product.Repository {
+		return persistence.NewMemoryRepository()
+	})
+}
+
+func TestMemoryCategoryRepository(t *testing.T) {
+	repositorytest.RunCategories(t, func(t *testing.T) (category.Repository, product.Repository) {
+		products := persistence.NewMemoryRepository()
+		return persistence.NewMemoryCategoryRepository(products), products
+	})
+}
+
+func TestMemoryInventoryRepository(t *testing.T) {
+	repositorytest.RunInventory(t, func(t *testing.T) (inventory.Repository, product.Repository) {
+		products := persistence.NewMemoryRepository()
+		return persistence.NewMemoryInventoryRepository(products), products
+	})
+}
+
+func TestMemoryPromotionRepository(t *testing.T) {
+	repositorytest.RunPromotions(t, func(t *testing.T) promotion.Repository {
+		return persistence.NewMemoryPromotionRepository()
+	})
+}
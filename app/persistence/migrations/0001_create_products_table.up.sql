@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS products (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(255) NOT NULL,
+    color VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    buying_price NUMERIC(10,2) NOT NULL,
+    selling_price NUMERIC(10,2) NOT NULL,
+    image_url VARCHAR(255) NOT NULL,
+    type VARCHAR(255) NOT NULL,
+    provider VARCHAR(255) NOT NULL,
+    creator VARCHAR(255) NOT NULL,
+    distributor VARCHAR(255) NOT NULL
+);
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS products_created_at_id_idx ON products (created_at, id);
+CREATE INDEX IF NOT EXISTS products_selling_price_id_idx ON products (selling_price, id);
+CREATE INDEX IF NOT EXISTS products_name_id_idx ON products (name, id);
@@ -0,0 +1 @@
+ALTER TABLE products ADD CONSTRAINT products_code_key UNIQUE (code);
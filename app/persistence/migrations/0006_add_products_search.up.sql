@@ -0,0 +1,18 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', name), 'A') ||
+        setweight(to_tsvector('simple', code), 'A') ||
+        setweight(to_tsvector('simple', type), 'B') ||
+        setweight(to_tsvector('simple', color), 'C') ||
+        setweight(to_tsvector('simple', provider), 'C')
+    ) STORED;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_text TEXT
+    GENERATED ALWAYS AS (
+        lower(name || ' ' || code || ' ' || type || ' ' || color || ' ' || provider)
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS products_search_vector_idx ON products USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS products_search_text_trgm_idx ON products USING GIN (search_text gin_trgm_ops);
@@ -0,0 +1,3 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_type_fkey;
+DROP INDEX IF EXISTS products_type_idx;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    slug VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    parent_id VARCHAR(255) NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT categories_slug_key UNIQUE (slug),
+    CONSTRAINT categories_parent_id_fkey FOREIGN KEY (parent_id)
+        REFERENCES categories (id)
+);
+
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
+CREATE INDEX IF NOT EXISTS products_type_idx ON products (type);
+
+INSERT INTO categories (id, slug, name) VALUES
+    (gen_random_uuid()::TEXT, 'clothing', 'Clothing'),
+    (gen_random_uuid()::TEXT, 'shoes', 'Shoes'),
+    (gen_random_uuid()::TEXT, 'bag', 'Bag'),
+    (gen_random_uuid()::TEXT, 'watch', 'Watch'),
+    (gen_random_uuid()::TEXT, 'wallet', 'Wallet'),
+    (gen_random_uuid()::TEXT, 'glasses', 'Glasses'),
+    (gen_random_uuid()::TEXT, 'hat', 'Hat'),
+    (gen_random_uuid()::TEXT, 'jacket', 'Jacket'),
+    (gen_random_uuid()::TEXT, 'pants', 'Pants'),
+    (gen_random_uuid()::TEXT, 'shirt', 'Shirt')
+ON CONFLICT (slug) DO NOTHING;
+
+-- products refer to their category by slug, so that a category can not be
+-- deleted while a product is being created in it. Types without a category
+-- become root categories first.
+INSERT INTO categories (id, slug, name)
+SELECT gen_random_uuid()::TEXT, type, INITCAP(type) FROM products GROUP BY type
+ON CONFLICT (slug) DO NOTHING;
+
+ALTER TABLE products ADD CONSTRAINT products_type_fkey FOREIGN KEY (type)
+    REFERENCES categories (slug);
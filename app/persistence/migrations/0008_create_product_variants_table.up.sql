@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    product_id VARCHAR(255) NOT NULL,
+    sku VARCHAR(255) NOT NULL,
+    size VARCHAR(255) NOT NULL,
+    color VARCHAR(255) NOT NULL,
+    barcode VARCHAR(14) NOT NULL,
+    price NUMERIC(10,2) NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT product_variants_sku_key UNIQUE (sku),
+    CONSTRAINT product_variants_product_id_fkey FOREIGN KEY (product_id)
+        REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_variants_product_id_idx ON product_variants (product_id, created_at, id);
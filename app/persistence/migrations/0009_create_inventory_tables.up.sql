@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS inventory_stock (
+    product_id VARCHAR(255) NOT NULL,
+    warehouse VARCHAR(255) NOT NULL,
+    on_hand INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, warehouse),
+    CONSTRAINT inventory_stock_product_id_fkey FOREIGN KEY (product_id)
+        REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT inventory_stock_on_hand_check CHECK (on_hand >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS inventory_reservations (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    basket_id VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS inventory_reservation_items (
+    reservation_id VARCHAR(255) NOT NULL,
+    product_id VARCHAR(255) NOT NULL,
+    warehouse VARCHAR(255) NOT NULL,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (reservation_id, product_id, warehouse),
+    CONSTRAINT inventory_reservation_items_reservation_id_fkey FOREIGN KEY (reservation_id)
+        REFERENCES inventory_reservations (id) ON DELETE CASCADE,
+    CONSTRAINT inventory_reservation_items_quantity_check CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS inventory_reservation_items_product_idx
+    ON inventory_reservation_items (product_id, warehouse);
+CREATE INDEX IF NOT EXISTS inventory_reservations_pending_idx
+    ON inventory_reservations (expires_at) WHERE status = 'pending';
@@ -0,0 +1,11 @@
+-- Prices of currencies without two minor unit digits do not survive the
+-- conversion back.
+ALTER TABLE product_variants
+    ALTER COLUMN price TYPE NUMERIC(10,2) USING price / 100.0;
+
+ALTER TABLE products
+    ALTER COLUMN buying_price TYPE NUMERIC(10,2) USING buying_price / 100.0,
+    ALTER COLUMN selling_price TYPE NUMERIC(10,2) USING selling_price / 100.0;
+
+ALTER TABLE products DROP COLUMN IF EXISTS prices;
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,15 @@
+-- Prices are kept in minor units of the currency of the product. Every price
+-- was in TRY before, which has two minor unit digits.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'TRY';
+ALTER TABLE products ALTER COLUMN currency DROP DEFAULT;
+
+-- prices is the price list of the product, its selling price in other
+-- currencies as [{"currency": "EUR", "amount": 1250}, ...].
+ALTER TABLE products ADD COLUMN IF NOT EXISTS prices JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE products
+    ALTER COLUMN buying_price TYPE BIGINT USING ROUND(buying_price * 100)::BIGINT,
+    ALTER COLUMN selling_price TYPE BIGINT USING ROUND(selling_price * 100)::BIGINT;
+
+ALTER TABLE product_variants
+    ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100)::BIGINT;
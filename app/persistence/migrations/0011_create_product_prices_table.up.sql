@@ -0,0 +1,23 @@
+-- product_prices holds the scheduled selling prices of the products, changes
+-- of the regular selling price are recorded with an open validity window.
+CREATE TABLE IF NOT EXISTS product_prices (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    product_id VARCHAR(255) NOT NULL,
+    price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to TIMESTAMPTZ NULL,
+    changed_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT product_prices_product_id_fkey FOREIGN KEY (product_id)
+        REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT product_prices_validity_check CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS product_prices_product_id_idx
+    ON product_prices (product_id, valid_from);
+
+-- The history of the existing products starts with their current price.
+INSERT INTO product_prices (id, product_id, price, currency, valid_from)
+SELECT gen_random_uuid()::TEXT, id, selling_price, currency, created_at
+FROM products;
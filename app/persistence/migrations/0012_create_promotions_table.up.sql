@@ -0,0 +1,21 @@
+-- Percentage promotions have a percentage, fixed amount ones an amount in
+-- the minor units of its currency. Empty scope arrays match every product.
+CREATE TABLE IF NOT EXISTS promotions (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    percentage NUMERIC(5,2) NULL,
+    amount BIGINT NULL,
+    currency CHAR(3) NULL,
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    product_ids TEXT[] NOT NULL DEFAULT '{}',
+    product_types TEXT[] NOT NULL DEFAULT '{}',
+    providers TEXT[] NOT NULL DEFAULT '{}',
+    starts_at TIMESTAMPTZ NULL,
+    ends_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT promotions_period_check CHECK (starts_at IS NULL OR ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS promotions_period_idx ON promotions (starts_at, ends_at);
@@ -0,0 +1,19 @@
+-- product_images holds the uploaded images of the products, their files are
+-- in the image storage under key, the thumbnails next to the original.
+CREATE TABLE IF NOT EXISTS product_images (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    product_id VARCHAR(255) NOT NULL,
+    position INT NOT NULL,
+    key TEXT NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    thumbnail_sizes INT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT product_images_product_id_fkey FOREIGN KEY (product_id)
+        REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_images_product_id_idx
+    ON product_images (product_id, position);
@@ -0,0 +1,2 @@
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS product_variants_currency_check;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,10 @@
+-- Variant prices keep their own currency, they were read in the currency of
+-- their product before and changed meaning along with it.
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS currency CHAR(3) NULL;
+
+UPDATE product_variants SET currency = products.currency
+FROM products
+WHERE products.id = product_variants.product_id AND product_variants.price IS NOT NULL;
+
+ALTER TABLE product_variants ADD CONSTRAINT product_variants_currency_check
+    CHECK ((price IS NULL) = (currency IS NULL));
@@ -3,18 +3,59 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/money"
 	"github.com/sirupsen/logrus"
 )
 
 //go:generate mockgen -source=postgres.go -destination=mock_postgres_repository.go -package=persistence
 type PostgresRepository interface {
-	GetProductByID(ctx context.Context, id string) (*product.Product, error)
-	GetProductsByIDs(ctx context.Context, ids []string) ([]product.Product, error)
-	CreateProduct(ctx context.Context, product *product.Product) (*product.Product, error)
+	GetProductByID(
+		ctx context.Context, id string, opts product.FindOptions) (*product.Product, error)
+	GetProductByCode(ctx context.Context, code string) (*product.Product, error)
+	GetProductsByIDs(
+		ctx context.Context, ids []string, opts product.FindOptions) ([]product.Product, error)
+	ListProducts(ctx context.Context, filter product.ListFilter) ([]product.Product, error)
+	SearchProducts(ctx context.Context, filter product.SearchFilter) ([]product.Product, error)
+	ExportProducts(
+		ctx context.Context, filter product.ListFilter, fn func(*product.Product) error) error
+	CreateProduct(
+		ctx context.Context, product *product.Product, changedBy string) (*product.Product, error)
+	CreateProducts(ctx context.Context,
+		products []*product.Product, changedBy string) ([]*product.Product, error)
+	CreateProductsSkippingConflicts(ctx context.Context,
+		products []*product.Product, changedBy string) ([]*product.Product, error)
+	UpdateProduct(
+		ctx context.Context, product *product.Product, changedBy string) (*product.Product, error)
+	DeleteProduct(ctx context.Context, id string) error
+	RestoreProduct(ctx context.Context, id string) (*product.Product, error)
+	GetVariant(ctx context.Context, productID, id string) (*product.Variant, error)
+	GetVariantsBySKUs(ctx context.Context, skus []string) ([]product.Variant, error)
+	ListVariants(ctx context.Context, productID string) ([]product.Variant, error)
+	CreateVariant(ctx context.Context, variant *product.Variant) (*product.Variant, error)
+	UpdateVariant(ctx context.Context, variant *product.Variant) (*product.Variant, error)
+	DeleteVariant(ctx context.Context, productID, id string) error
+	ListScheduledPrices(ctx context.Context, productID string) ([]product.ScheduledPrice, error)
+	GetScheduledPricesAt(
+		ctx context.Context, productIDs []string, at time.Time) ([]product.ScheduledPrice, error)
+	CreateScheduledPrice(
+		ctx context.Context, price *product.ScheduledPrice) (*product.ScheduledPrice, error)
+	ListImages(ctx context.Context, productID string) ([]product.Image, error)
+	GetImagesByProductIDs(ctx context.Context, productIDs []string) ([]product.Image, error)
+	GetImage(ctx context.Context, productID, id string) (*product.Image, error)
+	CreateImage(ctx context.Context, image *product.Image) (*product.Image, error)
+	ReorderImages(ctx context.Context, productID string, ids []string) error
+	DeleteImage(ctx context.Context, productID, id string) error
 }
 
 type postgresRepository struct {
@@ -34,18 +75,49 @@ func NewPostgresRepository(opts *NewPostgresRepositoryOpts) PostgresRepository {
 	}
 }
 
-func (pr *postgresRepository) GetProductByID(
-	ctx context.Context, id string) (*product.Product, error) {
-	row := pr.db.QueryRowContext(
-		ctx,
-		`SELECT id, name, code, color, created_at, updated_at, 
-    buying_price, selling_price, image_url, type, provider, creator,
-    distributor
-		FROM products WHERE ID = $1`,
-		id,
-	)
+const (
+	productsCodeConstraint = "products_code_key"
+	productsTypeConstraint = "products_type_fkey"
+	uniqueViolationErrCode = "23505"
+)
+
+// isUniqueViolation reports whether err is a postgres unique violation of the
+// given constraint.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) &&
+		pqErr.Code == uniqueViolationErrCode && pqErr.Constraint == constraint
+}
+
+// uniqueViolationKey matches the detail of unique violations naming the
+// conflicting value, e.g. Key (code)=(RUN-1) already exists.
+var uniqueViolationKey = regexp.MustCompile(`^Key \([^)]+\)=\((.*)\) already exists\.$`)
 
+// productCodeConflict returns the error of a violation of the unique product
+// codes, carrying the conflicting code when postgres reports it.
+func productCodeConflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if match := uniqueViolationKey.FindStringSubmatch(pqErr.Detail); match != nil {
+			return &product.ProductCodeConflictError{Code: match[1]}
+		}
+	}
+
+	return product.ErrProductCodeAlreadyExists
+}
+
+const productColumns = `id, name, code, color, created_at, updated_at,
+	buying_price, selling_price, currency, prices, image_url, type, provider,
+	creator, distributor, deleted_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProduct(row rowScanner) (*product.Product, error) {
 	var p product.Product
+	var currency money.Currency
+	var prices priceList
 	if err := row.Scan(
 		&p.ID,
 		&p.Name,
@@ -53,69 +125,767 @@ func (pr *postgresRepository) GetProductByID(
 		&p.Color,
 		&p.CreatedAt,
 		&p.UpdatedAt,
-		&p.BuyingPrice,
-		&p.SellingPrice,
+		&p.BuyingPrice.Amount,
+		&p.SellingPrice.Amount,
+		&currency,
+		&prices,
 		&p.ImageURL,
 		&p.Type,
 		&p.Provider,
 		&p.Creator,
 		&p.Distributor,
+		&p.DeletedAt,
 	); err != nil {
-		pr.logger.Error("could not scan product :%v", err)
 		return nil, err
 	}
 
+	p.BuyingPrice.Currency, p.SellingPrice.Currency = currency, currency
+	p.Prices = prices
+
 	return &p, nil
 }
 
+// priceList stores the price list of a product in a jsonb column, the
+// amounts in minor units.
+type priceList []money.Money
+
+type storedPrice struct {
+	Currency money.Currency `json:"currency"`
+	Amount   int64          `json:"amount"`
+}
+
+func (l priceList) Value() (driver.Value, error) {
+	prices := make([]storedPrice, 0, len(l))
+	for _, price := range l {
+		prices = append(prices, storedPrice{Currency: price.Currency, Amount: price.Amount})
+	}
+
+	return json.Marshal(prices)
+}
+
+func (l *priceList) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported price list type %T", src)
+	}
+
+	var prices []storedPrice
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return err
+	}
+
+	*l = nil
+	for _, price := range prices {
+		*l = append(*l, money.New(price.Amount, price.Currency))
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) GetProductByID(
+	ctx context.Context, id string, opts product.FindOptions) (*product.Product, error) {
+	row := pr.db.QueryRowContext(
+		ctx,
+		`SELECT `+productColumns+`
+		FROM products WHERE ID = $1 AND ($2 OR deleted_at IS NULL)`,
+		id,
+		opts.IncludeDeleted,
+	)
+
+	p, err := scanProduct(row)
+	if err != nil {
+		pr.logger.Errorf("could not scan product :%v", err)
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (pr *postgresRepository) GetProductByCode(
+	ctx context.Context, code string) (*product.Product, error) {
+	row := pr.db.QueryRowContext(
+		ctx,
+		`SELECT `+productColumns+`
+		FROM products WHERE code = $1 AND deleted_at IS NULL`,
+		code,
+	)
+
+	p, err := scanProduct(row)
+	if err != nil {
+		pr.logger.Errorf("could not scan product :%v", err)
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// GetProductsByIDs fetches the products with the given ids in a single query.
+// Repeated ids are returned once and the products follow the order of ids.
 func (pr *postgresRepository) GetProductsByIDs(
-	ctx context.Context, ids []string) ([]product.Product, error) {
-	products := make([]product.Product, 0, len(ids))
-	for i := range ids {
-		product, err := pr.GetProductByID(ctx, ids[i])
-		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+	ctx context.Context, ids []string, opts product.FindOptions) ([]product.Product, error) {
+	ids = product.UniqueIDs(ids)
+
+	rows, err := pr.db.QueryContext(
+		ctx,
+		`SELECT `+productColumns+`
+		FROM products WHERE id = ANY($1) AND ($2 OR deleted_at IS NULL)`,
+		pq.Array(ids),
+		opts.IncludeDeleted,
+	)
+	if err != nil {
+		pr.logger.Errorf("could not get products :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[string]*product.Product, len(ids))
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			pr.logger.Errorf("could not scan product :%v", err)
 			return nil, err
 		}
 
-		if product != nil {
-			products = append(products, *product)
+		found[p.ID] = p
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	products := make([]product.Product, 0, len(found))
+	for _, id := range ids {
+		if p, ok := found[id]; ok {
+			products = append(products, *p)
 		}
 	}
 
 	return products, nil
 }
 
+// sortColumn is a column of a listing sort and the type the cursor value is
+// cast to when comparing.
+type sortColumn struct {
+	column string
+	cast   string
+}
+
+// sortColumns maps the listing sort fields to their columns, the prices are
+// sorted by their currency first.
+var sortColumns = map[product.SortField][]sortColumn{
+	product.SortByCreatedAt: {{column: "created_at", cast: "timestamptz"}},
+	product.SortBySellingPrice: {
+		{column: "currency", cast: "text"},
+		{column: "selling_price", cast: "bigint"},
+	},
+	product.SortByName: {{column: "name", cast: "text"}},
+}
+
+// cursorValues returns the values of the sort columns held by the cursor.
+func cursorValues(field product.SortField, cursor *product.Cursor) ([]interface{}, error) {
+	if field != product.SortBySellingPrice {
+		return []interface{}{cursor.Value}, nil
+	}
+
+	price, err := cursor.Price()
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{string(price.Currency), price.Amount}, nil
+}
+
+func (pr *postgresRepository) ListProducts(
+	ctx context.Context, filter product.ListFilter) ([]product.Product, error) {
+	query, args, err := listQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		pr.logger.Errorf("could not list products :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]product.Product, 0, filter.Limit)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			pr.logger.Errorf("could not scan product :%v", err)
+			return nil, err
+		}
+
+		products = append(products, *p)
+	}
+
+	return products, rows.Err()
+}
+
+// SearchProducts ranks the products by full-text relevance. When nothing
+// matches the full-text query, which is the case for misspelled terms, the
+// products are ranked by trigram word similarity instead.
+func (pr *postgresRepository) SearchProducts(
+	ctx context.Context, filter product.SearchFilter) ([]product.Product, error) {
+	products, err := pr.queryProducts(ctx,
+		`SELECT `+productColumns+`
+		FROM products, websearch_to_tsquery('simple', $1) query
+		WHERE search_vector @@ query AND ($2 OR deleted_at IS NULL)
+		ORDER BY ts_rank(search_vector, query) DESC, id
+		LIMIT $3`,
+		filter.Query, filter.IncludeDeleted, filter.Limit,
+	)
+	if err != nil || len(products) > 0 {
+		return products, err
+	}
+
+	return pr.queryProducts(ctx,
+		`SELECT `+productColumns+`
+		FROM products
+		WHERE lower($1) <% search_text AND ($2 OR deleted_at IS NULL)
+		ORDER BY word_similarity(lower($1), search_text) DESC, id
+		LIMIT $3`,
+		filter.Query, filter.IncludeDeleted, filter.Limit,
+	)
+}
+
+func (pr *postgresRepository) queryProducts(
+	ctx context.Context, query string, args ...interface{}) ([]product.Product, error) {
+	rows, err := pr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		pr.logger.Errorf("could not query products :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []product.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			pr.logger.Errorf("could not scan product :%v", err)
+			return nil, err
+		}
+
+		products = append(products, *p)
+	}
+
+	return products, rows.Err()
+}
+
+// exportFetchSize is the number of rows fetched from the export cursor at
+// once, bounding the memory an export takes regardless of the catalog size.
+const exportFetchSize = 500
+
+// ExportProducts streams every product matching the filter to fn through a
+// server-side cursor. The filter limit is ignored.
+func (pr *postgresRepository) ExportProducts(
+	ctx context.Context, filter product.ListFilter, fn func(*product.Product) error) error {
+	filter.Limit = 0
+	query, args, err := listQuery(filter)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pr.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(
+		ctx, `DECLARE products_export NO SCROLL CURSOR FOR `+query, args...); err != nil {
+		pr.logger.Errorf("could not declare export cursor :%v", err)
+		return err
+	}
+
+	for {
+		fetched, err := fetchProducts(ctx, tx, fn)
+		if err != nil {
+			return err
+		}
+
+		if fetched < exportFetchSize {
+			break
+		}
+	}
+
+	return tx.Commit()
+}
+
+func fetchProducts(ctx context.Context, tx *sql.Tx, fn func(*product.Product) error) (int, error) {
+	rows, err := tx.QueryContext(
+		ctx, fmt.Sprintf(`FETCH FORWARD %d FROM products_export`, exportFetchSize))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return fetched, err
+		}
+
+		fetched++
+		if err = fn(p); err != nil {
+			return fetched, err
+		}
+	}
+
+	return fetched, rows.Err()
+}
+
+// listQuery builds the parameterized listing query of the filter. Only
+// whitelisted columns are written into the query, every value is an argument.
+func listQuery(filter product.ListFilter) (string, []interface{}, error) {
+	sort, ok := sortColumns[filter.Sort.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported sort field %q", filter.Sort.Field)
+	}
+
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"TRUE"}
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if len(filter.Types) > 0 {
+		conditions = append(conditions, "type = ANY("+arg(pq.Array(filter.Types))+")")
+	}
+	if len(filter.Colors) > 0 {
+		conditions = append(conditions, "color = ANY("+arg(pq.Array(filter.Colors))+")")
+	}
+	if len(filter.Providers) > 0 {
+		conditions = append(conditions, "provider = ANY("+arg(pq.Array(filter.Providers))+")")
+	}
+	if len(filter.Distributors) > 0 {
+		conditions = append(conditions,
+			"distributor = ANY("+arg(pq.Array(filter.Distributors))+")")
+	}
+	if filter.MinPrice != nil {
+		conditions = append(conditions, "currency = "+arg(filter.MinPrice.Currency),
+			"selling_price >= "+arg(filter.MinPrice.Amount))
+	}
+	if filter.MaxPrice != nil {
+		conditions = append(conditions, "currency = "+arg(filter.MaxPrice.Currency),
+			"selling_price <= "+arg(filter.MaxPrice.Amount))
+	}
+
+	direction, operator := "ASC", ">"
+	if filter.Sort.Descending {
+		direction, operator = "DESC", "<"
+	}
+
+	columns := make([]string, 0, len(sort)+1)
+	order := make([]string, 0, len(sort)+1)
+	for _, c := range sort {
+		columns = append(columns, c.column)
+		order = append(order, c.column+" "+direction)
+	}
+	columns = append(columns, "id")
+	order = append(order, "id "+direction)
+
+	if filter.After != nil {
+		values, err := cursorValues(filter.Sort.Field, filter.After)
+		if err != nil {
+			return "", nil, err
+		}
+
+		placeholders := make([]string, 0, len(values)+1)
+		for i, value := range values {
+			placeholders = append(placeholders, arg(value)+"::"+sort[i].cast)
+		}
+		placeholders = append(placeholders, arg(filter.After.ID))
+
+		conditions = append(conditions, fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(columns, ", "), operator, strings.Join(placeholders, ", ")))
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM products WHERE %s ORDER BY %s`,
+		productColumns, strings.Join(conditions, " AND "), strings.Join(order, ", "))
+	if filter.Limit > 0 {
+		query += " LIMIT " + arg(filter.Limit)
+	}
+
+	return query, args, nil
+}
+
+// CreateProduct inserts the product along with its selling price as the
+// first entry of its price history.
 func (pr *postgresRepository) CreateProduct(
-	ctx context.Context, product *product.Product) (*product.Product, error) {
-	row := pr.db.QueryRowContext(
+	ctx context.Context, p *product.Product, changedBy string) (*product.Product, error) {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(
+		ctx,
+		`INSERT INTO products (id, name, code, color, buying_price, selling_price,
+		currency, prices, image_url, type, provider, creator, distributor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING created_at, updated_at`,
+		p.ID,
+		p.Name,
+		p.Code,
+		p.Color,
+		p.BuyingPrice.Amount,
+		p.SellingPrice.Amount,
+		p.Currency(),
+		priceList(p.Prices),
+		p.ImageURL,
+		p.Type,
+		p.Provider,
+		p.Creator,
+		p.Distributor,
+	)
+
+	var createdAt time.Time
+	var updatedAt time.Time
+
+	if err = row.Scan(&createdAt, &updatedAt); err != nil {
+		if isUniqueViolation(err, productsCodeConstraint) {
+			return nil, product.ErrProductCodeAlreadyExists
+		}
+
+		if isForeignKeyViolation(err, productsTypeConstraint) {
+			return nil, product.ErrProductTypeNotFound
+		}
+
+		pr.logger.Errorf("could not get created product :%v", err)
+		return nil, err
+	}
+
+	p.CreatedAt = createdAt
+	p.UpdatedAt = updatedAt
+
+	if err = insertRegularPrices(ctx, tx, []*product.Product{p}, changedBy); err != nil {
+		pr.logger.Errorf("could not record price :%v", err)
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// createProductsChunkSize keeps the multi-row inserts below the postgres
+// limit of 65535 parameters per statement.
+const createProductsChunkSize = 1000
+
+// CreateProducts inserts all products and their price history in a single
+// transaction using multi-row inserts, either every product is created or
+// none.
+func (pr *postgresRepository) CreateProducts(ctx context.Context,
+	products []*product.Product, changedBy string) ([]*product.Product, error) {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, chunk := range productChunks(products) {
+		if _, err = insertProducts(ctx, tx, chunk, ""); err != nil {
+			if isUniqueViolation(err, productsCodeConstraint) {
+				return nil, productCodeConflict(err)
+			}
+
+			if isForeignKeyViolation(err, productsTypeConstraint) {
+				return nil, product.ErrProductTypeNotFound
+			}
+
+			pr.logger.Errorf("could not create products :%v", err)
+			return nil, err
+		}
+
+		if err = insertRegularPrices(ctx, tx, chunk, changedBy); err != nil {
+			pr.logger.Errorf("could not record prices :%v", err)
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// CreateProductsSkippingConflicts inserts the products with multi-row inserts,
+// the products whose code is taken are skipped. Every chunk of products is
+// inserted along with its price history in a transaction of its own.
+func (pr *postgresRepository) CreateProductsSkippingConflicts(ctx context.Context,
+	products []*product.Product, changedBy string) ([]*product.Product, error) {
+	created := make([]*product.Product, 0, len(products))
+	for _, chunk := range productChunks(products) {
+		inserted, err := pr.insertProductsSkippingConflicts(ctx, chunk, changedBy)
+		if isForeignKeyViolation(err, productsTypeConstraint) {
+			return created, product.ErrProductTypeNotFound
+		}
+
+		if err != nil {
+			pr.logger.Errorf("could not create products :%v", err)
+			return created, err
+		}
+
+		created = append(created, inserted...)
+	}
+
+	return created, nil
+}
+
+func (pr *postgresRepository) insertProductsSkippingConflicts(ctx context.Context,
+	products []*product.Product, changedBy string) ([]*product.Product, error) {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	inserted, err := insertProducts(ctx, tx, products, "ON CONFLICT (code) DO NOTHING")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = insertRegularPrices(ctx, tx, inserted, changedBy); err != nil {
+		return nil, err
+	}
+
+	return inserted, tx.Commit()
+}
+
+func productChunks(products []*product.Product) [][]*product.Product {
+	var chunks [][]*product.Product
+	for start := 0; start < len(products); start += createProductsChunkSize {
+		end := start + createProductsChunkSize
+		if end > len(products) {
+			end = len(products)
+		}
+
+		chunks = append(chunks, products[start:end])
+	}
+
+	return chunks
+}
+
+// insertProducts inserts the products with a single statement ending with
+// the given conflict clause, and returns the inserted ones in their order.
+func insertProducts(ctx context.Context,
+	q querier, products []*product.Product, onConflict string) ([]*product.Product, error) {
+	const columns = 13
+
+	values := make([]string, 0, len(products))
+	args := make([]interface{}, 0, len(products)*columns)
+	for i, p := range products {
+		placeholders := make([]string, columns)
+		for j := range placeholders {
+			placeholders[j] = fmt.Sprintf("$%d", i*columns+j+1)
+		}
+
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args, p.ID, p.Name, p.Code, p.Color, p.BuyingPrice.Amount,
+			p.SellingPrice.Amount, p.Currency(), priceList(p.Prices), p.ImageURL, p.Type,
+			p.Provider, p.Creator, p.Distributor)
+	}
+
+	rows, err := q.QueryContext(
 		ctx,
 		`INSERT INTO products (id, name, code, color, buying_price, selling_price,
-		image_url, type, provider, creator, distributor)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		currency, prices, image_url, type, provider, creator, distributor)
+		VALUES `+strings.Join(values, ", ")+`
+		`+onConflict+`
+		RETURNING id, created_at, updated_at`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type timestamps struct{ createdAt, updatedAt time.Time }
+	inserted := make(map[string]timestamps, len(products))
+	for rows.Next() {
+		var id string
+		var t timestamps
+		if err = rows.Scan(&id, &t.createdAt, &t.updatedAt); err != nil {
+			return nil, err
+		}
+
+		inserted[id] = t
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	created := make([]*product.Product, 0, len(inserted))
+	for _, p := range products {
+		if t, ok := inserted[p.ID]; ok {
+			p.CreatedAt, p.UpdatedAt = t.createdAt, t.updatedAt
+			created = append(created, p)
+		}
+	}
+
+	return created, nil
+}
+
+// insertRegularPrices records the selling prices of the saved products in
+// their price history, in effect from the time the products are saved.
+func insertRegularPrices(
+	ctx context.Context, tx *sql.Tx, products []*product.Product, changedBy string) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	const columns = 6
+
+	values := make([]string, 0, len(products))
+	args := make([]interface{}, 0, len(products)*columns)
+	for i, p := range products {
+		placeholders := make([]string, columns)
+		for j := range placeholders {
+			placeholders[j] = fmt.Sprintf("$%d", i*columns+j+1)
+		}
+
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args, uuid.New().String(), p.ID, p.SellingPrice.Amount,
+			p.SellingPrice.Currency, p.UpdatedAt, changedBy)
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO product_prices (id, product_id, price, currency, valid_from, changed_by)
+		VALUES `+strings.Join(values, ", "),
+		args...,
+	)
+
+	return err
+}
+
+// UpdateProduct updates the product and records its selling price in its
+// price history when the price changes.
+func (pr *postgresRepository) UpdateProduct(
+	ctx context.Context, p *product.Product, changedBy string) (*product.Product, error) {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var current money.Money
+	if err = tx.QueryRowContext(ctx,
+		`SELECT selling_price, currency FROM products
+		WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		p.ID,
+	).Scan(&current.Amount, &current.Currency); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			pr.logger.Errorf("could not get product to update :%v", err)
+		}
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(
+		ctx,
+		`UPDATE products SET name = $2, code = $3, color = $4, buying_price = $5,
+		selling_price = $6, currency = $7, prices = $8, image_url = $9, type = $10,
+		provider = $11, creator = $12, distributor = $13, updated_at = NOW()
+		WHERE id = $1
 		RETURNING created_at, updated_at`,
-		product.ID,
-		product.Name,
-		product.Code,
-		product.Color,
-		product.BuyingPrice,
-		product.SellingPrice,
-		product.ImageURL,
-		product.Type,
-		product.Provider,
-		product.Creator,
-		product.Distributor,
+		p.ID,
+		p.Name,
+		p.Code,
+		p.Color,
+		p.BuyingPrice.Amount,
+		p.SellingPrice.Amount,
+		p.Currency(),
+		priceList(p.Prices),
+		p.ImageURL,
+		p.Type,
+		p.Provider,
+		p.Creator,
+		p.Distributor,
 	)
 
 	var createdAt time.Time
 	var updatedAt time.Time
 
-	if err := row.Scan(&createdAt, &updatedAt); err != nil {
-		pr.logger.Error("could not get created product :%v", err)
+	if err = row.Scan(&createdAt, &updatedAt); err != nil {
+		if isUniqueViolation(err, productsCodeConstraint) {
+			return nil, product.ErrProductCodeAlreadyExists
+		}
+
+		if isForeignKeyViolation(err, productsTypeConstraint) {
+			return nil, product.ErrProductTypeNotFound
+		}
+
+		pr.logger.Errorf("could not get updated product :%v", err)
 		return nil, err
 	}
 
-	product.CreatedAt = createdAt
-	product.UpdatedAt = updatedAt
+	p.CreatedAt = createdAt
+	p.UpdatedAt = updatedAt
+
+	if current != p.SellingPrice {
+		if err = insertRegularPrices(ctx, tx, []*product.Product{p}, changedBy); err != nil {
+			pr.logger.Errorf("could not record price :%v", err)
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (pr *postgresRepository) DeleteProduct(ctx context.Context, id string) error {
+	result, err := pr.db.ExecContext(
+		ctx,
+		`UPDATE products SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		pr.logger.Errorf("could not delete product :%v", err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) RestoreProduct(
+	ctx context.Context, id string) (*product.Product, error) {
+	row := pr.db.QueryRowContext(
+		ctx,
+		`UPDATE products SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING `+productColumns,
+		id,
+	)
+
+	p, err := scanProduct(row)
+	if err != nil {
+		pr.logger.Errorf("could not get restored product :%v", err)
+		return nil, err
+	}
 
-	return product, nil
+	return p, nil
 }
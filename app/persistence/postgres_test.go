@@ -0,0 +1,141 @@
+package persistence_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/category"
+	"github.com/pact-cdc-example/product-service/app/inventory"
+	"github.com/pact-cdc-example/product-service/app/persistence"
+	"github.com/pact-cdc-example/product-service/app/persistence/repositorytest"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/app/promotion"
+	"github.com/pact-cdc-example/product-service/pkg/migrate"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+// postgresDSNEnv points the postgres tests to a disposable database, e.g.
+// "host=localhost port=5435 user=pact-cdc password=pact-cdc dbname=pact-cdc sslmode=disable".
+const postgresDSNEnv = "PRODUCT_SERVICE_TEST_POSTGRES_DSN"
+
+func TestPostgresRepository(t *testing.T) {
+	db, logger := openTestDB(t)
+
+	repositorytest.Run(t, func(t *testing.T) product.Repository {
+		_, err := db.Exec(`TRUNCATE products, product_variants, product_prices, product_images,
+			inventory_stock, inventory_reservations, inventory_reservation_items`)
+		require.NoError(t, err)
+
+		return persistence.NewPostgresRepository(&persistence.NewPostgresRepositoryOpts{
+			DB: db,
+			L:  logger,
+		})
+	})
+}
+
+func TestPostgresCategoryRepository(t *testing.T) {
+	db, logger := openTestDB(t)
+
+	repositorytest.RunCategories(t, func(t *testing.T) (category.Repository, product.Repository) {
+		_, err := db.Exec(`TRUNCATE products, product_variants, product_prices, product_images,
+			categories, inventory_stock, inventory_reservations, inventory_reservation_items`)
+		require.NoError(t, err)
+
+		categories := persistence.NewPostgresCategoryRepository(
+			&persistence.NewPostgresCategoryRepositoryOpts{DB: db, L: logger})
+		for _, c := range category.RootCategories {
+			c.ID = uuid.New().String()
+			_, err = categories.CreateCategory(context.Background(), &c)
+			require.NoError(t, err)
+		}
+
+		return categories, persistence.NewPostgresRepository(
+			&persistence.NewPostgresRepositoryOpts{DB: db, L: logger})
+	})
+}
+
+// TestPostgresProductTypeForeignKey covers the foreign key of the product
+// types, the memory repositories do not relate products to categories.
+func TestPostgresProductTypeForeignKey(t *testing.T) {
+	db, logger := openTestDB(t)
+	_, err := db.Exec(`TRUNCATE products, product_variants, product_prices, product_images,
+		inventory_stock, inventory_reservations, inventory_reservation_items`)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	products := persistence.NewPostgresRepository(
+		&persistence.NewPostgresRepositoryOpts{DB: db, L: logger})
+	categories := persistence.NewPostgresCategoryRepository(
+		&persistence.NewPostgresCategoryRepositoryOpts{DB: db, L: logger})
+
+	_, err = products.CreateProduct(ctx, &product.Product{
+		ID: uuid.New().String(), Name: "Rocket", Code: "ROCKET-1", Type: "spaceship"}, "")
+	require.ErrorIs(t, err, product.ErrProductTypeNotFound)
+
+	belt, err := categories.CreateCategory(ctx, &category.Category{
+		ID: uuid.New().String(), Slug: "belt-" + uuid.New().String()[:8], Name: "Belt"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.Exec(`TRUNCATE products CASCADE`)
+		_ = categories.DeleteCategory(ctx, belt.ID)
+	})
+
+	_, err = products.CreateProduct(ctx, &product.Product{
+		ID: uuid.New().String(), Name: "Belt", Code: "BELT-1", Type: product.ProductType(belt.Slug)}, "")
+	require.NoError(t, err)
+	require.ErrorIs(t, categories.DeleteCategory(ctx, belt.ID), category.ErrInUse)
+}
+
+func TestPostgresInventoryRepository(t *testing.T) {
+	db, logger := openTestDB(t)
+
+	repositorytest.RunInventory(t, func(t *testing.T) (inventory.Repository, product.Repository) {
+		_, err := db.Exec(`TRUNCATE products, product_variants, product_prices, product_images,
+			inventory_stock, inventory_reservations, inventory_reservation_items`)
+		require.NoError(t, err)
+
+		return persistence.NewPostgresInventoryRepository(
+				&persistence.NewPostgresInventoryRepositoryOpts{DB: db, L: logger}),
+			persistence.NewPostgresRepository(
+				&persistence.NewPostgresRepositoryOpts{DB: db, L: logger})
+	})
+}
+
+func TestPostgresPromotionRepository(t *testing.T) {
+	db, logger := openTestDB(t)
+
+	repositorytest.RunPromotions(t, func(t *testing.T) promotion.Repository {
+		_, err := db.Exec(`TRUNCATE promotions`)
+		require.NoError(t, err)
+
+		return persistence.NewPostgresPromotionRepository(
+			&persistence.NewPostgresPromotionRepositoryOpts{DB: db, L: logger})
+	})
+}
+
+// openTestDB connects to the database of postgresDSNEnv and migrates it, the
+// test is skipped when it is not set.
+func openTestDB(t *testing.T) (*sql.DB, *logrus.Logger) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger, _ := test.NewNullLogger()
+	require.NoError(t, migrate.New(&migrate.NewMigratorOpts{
+		DB: db,
+		FS: persistence.Migrations(),
+		L:  logger,
+	}).Up(context.Background()))
+
+	return db, logger
+}
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/app/product"
+)
+
+func (mr *memoryRepository) ListScheduledPrices(
+	ctx context.Context, productID string) ([]product.ScheduledPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	prices := append([]product.ScheduledPrice(nil), mr.prices[productID]...)
+	mr.mu.RUnlock()
+
+	sort.Slice(prices, func(i, j int) bool {
+		if !prices[i].ValidFrom.Equal(prices[j].ValidFrom) {
+			return prices[i].ValidFrom.After(prices[j].ValidFrom)
+		}
+
+		if !prices[i].CreatedAt.Equal(prices[j].CreatedAt) {
+			return prices[i].CreatedAt.After(prices[j].CreatedAt)
+		}
+
+		return prices[i].ID < prices[j].ID
+	})
+
+	return prices, nil
+}
+
+func (mr *memoryRepository) GetScheduledPricesAt(ctx context.Context,
+	productIDs []string, at time.Time) ([]product.ScheduledPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var prices []product.ScheduledPrice
+	for _, id := range product.UniqueIDs(productIDs) {
+		for _, p := range mr.prices[id] {
+			if p.InEffect(at) {
+				prices = append(prices, p)
+			}
+		}
+	}
+
+	return prices, nil
+}
+
+func (mr *memoryRepository) CreateScheduledPrice(
+	ctx context.Context, p *product.ScheduledPrice) (*product.ScheduledPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.products[p.ProductID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	for _, existing := range mr.prices[p.ProductID] {
+		if existing.ID == p.ID {
+			return nil, fmt.Errorf("scheduled price %s already exists", p.ID)
+		}
+	}
+
+	created := *p
+	created.CreatedAt = memoryNow()
+	mr.prices[p.ProductID] = append(mr.prices[p.ProductID], created)
+
+	return &created, nil
+}
@@ -0,0 +1,102 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pact-cdc-example/product-service/app/product"
+)
+
+const pricesProductConstraint = "product_prices_product_id_fkey"
+
+const scheduledPriceColumns = `id, product_id, price, currency, valid_from, valid_to,
+	changed_by, created_at`
+
+func scanScheduledPrice(row rowScanner) (*product.ScheduledPrice, error) {
+	var p product.ScheduledPrice
+	var validTo sql.NullTime
+	if err := row.Scan(
+		&p.ID,
+		&p.ProductID,
+		&p.Price.Amount,
+		&p.Price.Currency,
+		&p.ValidFrom,
+		&validTo,
+		&p.ChangedBy,
+		&p.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if validTo.Valid {
+		p.ValidTo = &validTo.Time
+	}
+
+	return &p, nil
+}
+
+func (pr *postgresRepository) ListScheduledPrices(
+	ctx context.Context, productID string) ([]product.ScheduledPrice, error) {
+	return pr.queryScheduledPrices(ctx,
+		`SELECT `+scheduledPriceColumns+` FROM product_prices
+		WHERE product_id = $1 ORDER BY valid_from DESC, created_at DESC, id`,
+		productID,
+	)
+}
+
+func (pr *postgresRepository) GetScheduledPricesAt(ctx context.Context,
+	productIDs []string, at time.Time) ([]product.ScheduledPrice, error) {
+	return pr.queryScheduledPrices(ctx,
+		`SELECT `+scheduledPriceColumns+` FROM product_prices
+		WHERE product_id = ANY($1) AND valid_from <= $2 AND (valid_to IS NULL OR valid_to > $2)`,
+		pq.Array(product.UniqueIDs(productIDs)), at,
+	)
+}
+
+func (pr *postgresRepository) queryScheduledPrices(ctx context.Context,
+	query string, args ...interface{}) ([]product.ScheduledPrice, error) {
+	rows, err := pr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		pr.logger.Errorf("could not query scheduled prices :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []product.ScheduledPrice
+	for rows.Next() {
+		p, err := scanScheduledPrice(rows)
+		if err != nil {
+			pr.logger.Errorf("could not scan scheduled price :%v", err)
+			return nil, err
+		}
+
+		prices = append(prices, *p)
+	}
+
+	return prices, rows.Err()
+}
+
+// CreateScheduledPrice returns sql.ErrNoRows when the product does not exist.
+func (pr *postgresRepository) CreateScheduledPrice(
+	ctx context.Context, p *product.ScheduledPrice) (*product.ScheduledPrice, error) {
+	row := pr.db.QueryRowContext(ctx,
+		`INSERT INTO product_prices (id, product_id, price, currency, valid_from, valid_to,
+		changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+scheduledPriceColumns,
+		p.ID, p.ProductID, p.Price.Amount, p.Price.Currency, p.ValidFrom, p.ValidTo, p.ChangedBy,
+	)
+
+	created, err := scanScheduledPrice(row)
+	switch {
+	case isForeignKeyViolation(err, pricesProductConstraint):
+		return nil, sql.ErrNoRows
+	case err != nil:
+		pr.logger.Errorf("could not create scheduled price :%v", err)
+		return nil, err
+	}
+
+	return created, nil
+}
@@ -0,0 +1,141 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/app/promotion"
+)
+
+type memoryPromotionRepository struct {
+	mu         sync.RWMutex
+	promotions map[string]promotion.Promotion
+}
+
+// NewMemoryPromotionRepository returns a concurrency safe
+// promotion.Repository keeping the promotions in memory.
+func NewMemoryPromotionRepository() promotion.Repository {
+	return &memoryPromotionRepository{
+		promotions: make(map[string]promotion.Promotion),
+	}
+}
+
+func (mr *memoryPromotionRepository) GetPromotion(
+	ctx context.Context, id string) (*promotion.Promotion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	p, ok := mr.promotions[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &p, nil
+}
+
+func (mr *memoryPromotionRepository) ListPromotions(
+	ctx context.Context) ([]promotion.Promotion, error) {
+	return mr.listPromotions(ctx, func(*promotion.Promotion) bool { return true })
+}
+
+func (mr *memoryPromotionRepository) ListRunningPromotions(
+	ctx context.Context, at time.Time) ([]promotion.Promotion, error) {
+	return mr.listPromotions(ctx, func(p *promotion.Promotion) bool {
+		rule := p.Rule()
+		return rule.Runs(at)
+	})
+}
+
+// listPromotions returns the promotions matching keep, oldest first.
+func (mr *memoryPromotionRepository) listPromotions(
+	ctx context.Context, keep func(*promotion.Promotion) bool) ([]promotion.Promotion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	var promotions []promotion.Promotion
+	for _, p := range mr.promotions {
+		if keep(&p) {
+			promotions = append(promotions, p)
+		}
+	}
+	mr.mu.RUnlock()
+
+	sort.Slice(promotions, func(i, j int) bool {
+		if !promotions[i].CreatedAt.Equal(promotions[j].CreatedAt) {
+			return promotions[i].CreatedAt.Before(promotions[j].CreatedAt)
+		}
+
+		return promotions[i].ID < promotions[j].ID
+	})
+
+	return promotions, nil
+}
+
+func (mr *memoryPromotionRepository) CreatePromotion(
+	ctx context.Context, p *promotion.Promotion) (*promotion.Promotion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.promotions[p.ID]; ok {
+		return nil, fmt.Errorf("promotion %s already exists", p.ID)
+	}
+
+	created := *p
+	created.CreatedAt = memoryNow()
+	created.UpdatedAt = created.CreatedAt
+	mr.promotions[p.ID] = created
+
+	return &created, nil
+}
+
+func (mr *memoryPromotionRepository) UpdatePromotion(
+	ctx context.Context, p *promotion.Promotion) (*promotion.Promotion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	existing, ok := mr.promotions[p.ID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	updated := *p
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = memoryNow()
+	mr.promotions[p.ID] = updated
+
+	return &updated, nil
+}
+
+func (mr *memoryPromotionRepository) DeletePromotion(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.promotions[id]; !ok {
+		return sql.ErrNoRows
+	}
+
+	delete(mr.promotions, id)
+	return nil
+}
@@ -0,0 +1,219 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pact-cdc-example/product-service/app/promotion"
+	"github.com/pact-cdc-example/product-service/app/promotion/engine"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/sirupsen/logrus"
+)
+
+const promotionColumns = `id, name, kind, percentage, amount, currency, stackable,
+	product_ids, product_types, providers, starts_at, ends_at, created_at, updated_at`
+
+type postgresPromotionRepository struct {
+	db     *sql.DB
+	logger *logrus.Logger
+}
+
+type NewPostgresPromotionRepositoryOpts struct {
+	DB *sql.DB
+	L  *logrus.Logger
+}
+
+func NewPostgresPromotionRepository(opts *NewPostgresPromotionRepositoryOpts) promotion.Repository {
+	return &postgresPromotionRepository{
+		db:     opts.DB,
+		logger: opts.L,
+	}
+}
+
+func scanPromotion(row rowScanner) (*promotion.Promotion, error) {
+	var p promotion.Promotion
+	var kind string
+	var percentage, currency sql.NullString
+	var amount sql.NullInt64
+	if err := row.Scan(
+		&p.ID,
+		&p.Name,
+		&kind,
+		&percentage,
+		&amount,
+		&currency,
+		&p.Stackable,
+		pq.Array(&p.ProductIDs),
+		pq.Array(&p.ProductTypes),
+		pq.Array(&p.Providers),
+		&p.StartsAt,
+		&p.EndsAt,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	p.Kind = engine.Kind(kind)
+	if percentage.Valid {
+		pct, ok := new(big.Rat).SetString(percentage.String)
+		if !ok {
+			return nil, errors.New("invalid promotion percentage " + percentage.String)
+		}
+		p.Percentage = pct
+	}
+
+	if amount.Valid {
+		p.Amount = money.New(amount.Int64, money.Currency(currency.String))
+	}
+
+	return &p, nil
+}
+
+// promotionValues are the percentage, amount and currency column values of
+// the promotion, the ones its kind does not use are null.
+func promotionValues(p *promotion.Promotion) (percentage, amount, currency interface{}) {
+	if p.Percentage != nil {
+		percentage = p.Percentage.FloatString(2)
+	}
+
+	if p.Kind == engine.FixedAmount {
+		amount, currency = p.Amount.Amount, string(p.Amount.Currency)
+	}
+
+	return percentage, amount, currency
+}
+
+func (pr *postgresPromotionRepository) GetPromotion(
+	ctx context.Context, id string) (*promotion.Promotion, error) {
+	row := pr.db.QueryRowContext(ctx,
+		`SELECT `+promotionColumns+` FROM promotions WHERE id = $1`, id)
+
+	p, err := scanPromotion(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			pr.logger.Errorf("could not get promotion :%v", err)
+		}
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (pr *postgresPromotionRepository) ListPromotions(
+	ctx context.Context) ([]promotion.Promotion, error) {
+	return pr.queryPromotions(ctx,
+		`SELECT `+promotionColumns+` FROM promotions ORDER BY created_at, id`)
+}
+
+func (pr *postgresPromotionRepository) ListRunningPromotions(
+	ctx context.Context, at time.Time) ([]promotion.Promotion, error) {
+	return pr.queryPromotions(ctx,
+		`SELECT `+promotionColumns+` FROM promotions
+		WHERE (starts_at IS NULL OR starts_at <= $1) AND (ends_at IS NULL OR ends_at > $1)
+		ORDER BY created_at, id`,
+		at,
+	)
+}
+
+func (pr *postgresPromotionRepository) queryPromotions(
+	ctx context.Context, query string, args ...interface{}) ([]promotion.Promotion, error) {
+	rows, err := pr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		pr.logger.Errorf("could not query promotions :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []promotion.Promotion
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			pr.logger.Errorf("could not scan promotion :%v", err)
+			return nil, err
+		}
+
+		promotions = append(promotions, *p)
+	}
+
+	return promotions, rows.Err()
+}
+
+func (pr *postgresPromotionRepository) CreatePromotion(
+	ctx context.Context, p *promotion.Promotion) (*promotion.Promotion, error) {
+	percentage, amount, currency := promotionValues(p)
+	row := pr.db.QueryRowContext(ctx,
+		`INSERT INTO promotions (id, name, kind, percentage, amount, currency, stackable,
+		product_ids, product_types, providers, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING `+promotionColumns,
+		p.ID, p.Name, string(p.Kind), percentage, amount, currency, p.Stackable,
+		pq.Array(nonNil(p.ProductIDs)), pq.Array(nonNil(p.ProductTypes)),
+		pq.Array(nonNil(p.Providers)), p.StartsAt, p.EndsAt,
+	)
+
+	created, err := scanPromotion(row)
+	if err != nil {
+		pr.logger.Errorf("could not create promotion :%v", err)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (pr *postgresPromotionRepository) UpdatePromotion(
+	ctx context.Context, p *promotion.Promotion) (*promotion.Promotion, error) {
+	percentage, amount, currency := promotionValues(p)
+	row := pr.db.QueryRowContext(ctx,
+		`UPDATE promotions SET name = $2, kind = $3, percentage = $4, amount = $5,
+		currency = $6, stackable = $7, product_ids = $8, product_types = $9,
+		providers = $10, starts_at = $11, ends_at = $12, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+promotionColumns,
+		p.ID, p.Name, string(p.Kind), percentage, amount, currency, p.Stackable,
+		pq.Array(nonNil(p.ProductIDs)), pq.Array(nonNil(p.ProductTypes)),
+		pq.Array(nonNil(p.Providers)), p.StartsAt, p.EndsAt,
+	)
+
+	updated, err := scanPromotion(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			pr.logger.Errorf("could not update promotion :%v", err)
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (pr *postgresPromotionRepository) DeletePromotion(ctx context.Context, id string) error {
+	result, err := pr.db.ExecContext(ctx, `DELETE FROM promotions WHERE id = $1`, id)
+	if err != nil {
+		pr.logger.Errorf("could not delete promotion :%v", err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// nonNil returns an empty slice for nil, the scope columns are not nullable.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
@@ -0,0 +1,185 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/category"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// CategoryFactory returns a category repository holding only the root
+// categories, along with the product repository its categories are used by.
+type CategoryFactory func(t *testing.T) (category.Repository, product.Repository)
+
+// RunCategories runs the conformance suite of category.Repository.
+func RunCategories(t *testing.T, factory CategoryFactory) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, r category.Repository, products product.Repository)
+	}{
+		{"RootCategories", testRootCategories},
+		{"CreateCategory", testCreateCategory},
+		{"CreateCategoryErrors", testCreateCategoryErrors},
+		{"UpdateCategory", testUpdateCategory},
+		{"UpdateCategoryErrors", testUpdateCategoryErrors},
+		{"DeleteCategory", testDeleteCategory},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			r, products := factory(t)
+			c.run(t, r, products)
+		})
+	}
+}
+
+func testRootCategories(t *testing.T, r category.Repository, _ product.Repository) {
+	ctx := context.Background()
+
+	categories, err := r.ListCategories(ctx)
+	require.NoError(t, err)
+	require.Len(t, categories, len(category.RootCategories))
+	for _, c := range categories {
+		assert.Nil(t, c.ParentID)
+	}
+
+	existing, err := r.ExistingCategories(ctx, []string{"shoes", "belt", "bag"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"shoes": true, "bag": true}, existing)
+}
+
+func testCreateCategory(t *testing.T, r category.Repository, _ product.Repository) {
+	ctx := context.Background()
+	clothing := rootCategory(t, r, "clothing")
+
+	belt := createCategory(t, r, "belt", &clothing.ID)
+	assert.Equal(t, &clothing.ID, belt.ParentID)
+	assert.False(t, belt.CreatedAt.IsZero())
+
+	found, err := r.GetCategoryByID(ctx, belt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, belt.Slug, found.Slug)
+	assert.Equal(t, belt.Name, found.Name)
+
+	existing, err := r.ExistingCategories(ctx, []string{"belt"})
+	require.NoError(t, err)
+	assert.True(t, existing["belt"])
+
+	categories, err := r.ListCategories(ctx)
+	require.NoError(t, err)
+	assert.Len(t, categories, len(category.RootCategories)+1)
+
+	_, err = r.GetCategoryByID(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testCreateCategoryErrors(t *testing.T, r category.Repository, _ product.Repository) {
+	ctx := context.Background()
+
+	_, err := r.CreateCategory(ctx, &category.Category{
+		ID: uuid.New().String(), Slug: "shoes", Name: "Shoes again"})
+	assert.ErrorIs(t, err, category.ErrSlugAlreadyExists)
+
+	missing := uuid.New().String()
+	_, err = r.CreateCategory(ctx, &category.Category{
+		ID: uuid.New().String(), Slug: "orphan", Name: "Orphan", ParentID: &missing})
+	assert.ErrorIs(t, err, category.ErrParentNotFound)
+}
+
+func testUpdateCategory(t *testing.T, r category.Repository, _ product.Repository) {
+	ctx := context.Background()
+	clothing := rootCategory(t, r, "clothing")
+	bag := rootCategory(t, r, "bag")
+	belt := createCategory(t, r, "belt", &clothing.ID)
+
+	updated, err := r.UpdateCategory(ctx, &category.Category{
+		ID: belt.ID, Name: "Belts", ParentID: &bag.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "belt", updated.Slug)
+	assert.Equal(t, "Belts", updated.Name)
+	assert.Equal(t, &bag.ID, updated.ParentID)
+
+	updated, err = r.UpdateCategory(ctx, &category.Category{ID: belt.ID, Name: "Belts"})
+	require.NoError(t, err)
+	assert.Nil(t, updated.ParentID)
+}
+
+func testUpdateCategoryErrors(t *testing.T, r category.Repository, _ product.Repository) {
+	ctx := context.Background()
+	clothing := rootCategory(t, r, "clothing")
+	belt := createCategory(t, r, "belt", &clothing.ID)
+	leatherBelt := createCategory(t, r, "leather-belt", &belt.ID)
+
+	_, err := r.UpdateCategory(ctx, &category.Category{
+		ID: clothing.ID, Name: clothing.Name, ParentID: &leatherBelt.ID})
+	assert.ErrorIs(t, err, category.ErrCycle)
+
+	_, err = r.UpdateCategory(ctx, &category.Category{
+		ID: belt.ID, Name: belt.Name, ParentID: &belt.ID})
+	assert.ErrorIs(t, err, category.ErrCycle)
+
+	missing := uuid.New().String()
+	_, err = r.UpdateCategory(ctx, &category.Category{
+		ID: belt.ID, Name: belt.Name, ParentID: &missing})
+	assert.ErrorIs(t, err, category.ErrParentNotFound)
+
+	_, err = r.UpdateCategory(ctx, &category.Category{ID: missing, Name: "Missing"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteCategory(t *testing.T, r category.Repository, products product.Repository) {
+	ctx := context.Background()
+	clothing := rootCategory(t, r, "clothing")
+	belt := createCategory(t, r, "belt", &clothing.ID)
+
+	assert.ErrorIs(t, r.DeleteCategory(ctx, clothing.ID), category.ErrHasChildren)
+
+	created := create(t, products, 1, func(p *product.Product) { p.Type = "belt" })
+	require.NoError(t, products.DeleteProduct(ctx, created.ID))
+	assert.ErrorIs(t, r.DeleteCategory(ctx, belt.ID), category.ErrInUse,
+		"soft deleted products keep their category in use")
+
+	unused := createCategory(t, r, "suspenders", &clothing.ID)
+	require.NoError(t, r.DeleteCategory(ctx, unused.ID))
+
+	_, err := r.GetCategoryByID(ctx, unused.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, r.DeleteCategory(ctx, unused.ID), sql.ErrNoRows)
+}
+
+func rootCategory(t *testing.T, r category.Repository, slug string) category.Category {
+	t.Helper()
+
+	categories, err := r.ListCategories(context.Background())
+	require.NoError(t, err)
+
+	for _, c := range categories {
+		if c.Slug == slug {
+			return c
+		}
+	}
+
+	t.Fatalf("root category %q not found", slug)
+	return category.Category{}
+}
+
+func createCategory(
+	t *testing.T, r category.Repository, slug string, parentID *string) *category.Category {
+	t.Helper()
+
+	created, err := r.CreateCategory(context.Background(), &category.Category{
+		ID:       uuid.New().String(),
+		Slug:     slug,
+		Name:     slug,
+		ParentID: parentID,
+	})
+	require.NoError(t, err)
+
+	return created
+}
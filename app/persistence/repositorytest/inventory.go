@@ -0,0 +1,311 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/inventory"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// InventoryFactory returns an empty inventory repository along with the
+// product repository its stock is kept for.
+type InventoryFactory func(t *testing.T) (inventory.Repository, product.Repository)
+
+// RunInventory runs the conformance suite of inventory.Repository.
+func RunInventory(t *testing.T, factory InventoryFactory) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, r inventory.Repository, products product.Repository)
+	}{
+		{"AdjustStock", testAdjustStock},
+		{"AdjustStockBelowReserved", testAdjustStockBelowReserved},
+		{"UnknownProduct", testInventoryUnknownProduct},
+		{"Reserve", testReserve},
+		{"ReserveInsufficientStock", testReserveInsufficientStock},
+		{"ConcurrentReservations", testConcurrentReservations},
+		{"CommitReservation", testCommitReservation},
+		{"ReleaseReservation", testReleaseReservation},
+		{"ExpiredReservation", testExpiredReservation},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			r, products := factory(t)
+			c.run(t, r, products)
+		})
+	}
+}
+
+func testAdjustStock(t *testing.T, r inventory.Repository, products product.Repository) {
+	ctx := context.Background()
+	p := create(t, products, 1)
+
+	stocks, err := r.GetStock(ctx, p.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stocks)
+
+	adjust(t, r, p.ID, inventory.DefaultWarehouse, 10)
+	stock := adjust(t, r, p.ID, inventory.DefaultWarehouse, -3)
+	assert.Equal(t, 7, stock.OnHand)
+	assert.False(t, stock.UpdatedAt.IsZero())
+	adjust(t, r, p.ID, "east", 5)
+
+	stocks, err = r.GetStock(ctx, p.ID)
+	require.NoError(t, err)
+	require.Len(t, stocks, 2)
+	assert.Equal(t, inventory.DefaultWarehouse, stocks[0].Warehouse)
+	assert.Equal(t, 7, stocks[0].OnHand)
+	assert.Equal(t, "east", stocks[1].Warehouse)
+	assert.Equal(t, 5, stocks[1].OnHand)
+
+	available, err := r.Available(ctx, []string{p.ID, uuid.New().String()})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{p.ID: 12}, available)
+
+	_, err = r.AdjustStock(ctx, p.ID, "east", -6)
+	assertInsufficientStock(t, err, p.ID, "east", 5)
+}
+
+func testAdjustStockBelowReserved(
+	t *testing.T, r inventory.Repository, products product.Repository) {
+	p := create(t, products, 1)
+	adjust(t, r, p.ID, inventory.DefaultWarehouse, 10)
+	reserve(t, r, time.Hour, item(p.ID, 4))
+
+	_, err := r.AdjustStock(context.Background(), p.ID, inventory.DefaultWarehouse, -7)
+	assertInsufficientStock(t, err, p.ID, inventory.DefaultWarehouse, 6)
+
+	stock := adjust(t, r, p.ID, inventory.DefaultWarehouse, -6)
+	assert.Equal(t, 4, stock.OnHand)
+	assert.Equal(t, 0, stock.Available())
+}
+
+func testInventoryUnknownProduct(
+	t *testing.T, r inventory.Repository, products product.Repository) {
+	ctx := context.Background()
+	deleted := create(t, products, 1)
+	require.NoError(t, products.DeleteProduct(ctx, deleted.ID))
+
+	for _, id := range []string{uuid.New().String(), deleted.ID} {
+		_, err := r.GetStock(ctx, id)
+		assert.ErrorIs(t, err, inventory.ErrProductNotFound)
+
+		_, err = r.AdjustStock(ctx, id, inventory.DefaultWarehouse, 1)
+		assert.ErrorIs(t, err, inventory.ErrProductNotFound)
+
+		_, err = r.Reserve(ctx, newReservation(time.Hour, item(id, 1)))
+		assert.ErrorIs(t, err, inventory.ErrProductNotFound)
+	}
+
+	_, err := r.GetReservation(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = r.CommitReservation(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = r.ReleaseReservation(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testReserve(t *testing.T, r inventory.Repository, products product.Repository) {
+	ctx := context.Background()
+	shoes, bag := create(t, products, 1), create(t, products, 2)
+	adjust(t, r, shoes.ID, inventory.DefaultWarehouse, 5)
+	adjust(t, r, bag.ID, inventory.DefaultWarehouse, 2)
+
+	reservation := reserve(t, r, time.Hour, item(shoes.ID, 3), item(bag.ID, 2))
+	assert.Equal(t, inventory.StatusPending, reservation.Status)
+	assert.False(t, reservation.CreatedAt.IsZero())
+
+	found, err := r.GetReservation(ctx, reservation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, reservation.BasketID, found.BasketID)
+	assert.Equal(t, inventory.StatusPending, found.Status)
+	assert.ElementsMatch(t, []inventory.ReservationItem{
+		item(shoes.ID, 3), item(bag.ID, 2)}, found.Items)
+
+	stocks, err := r.GetStock(ctx, shoes.ID)
+	require.NoError(t, err)
+	require.Len(t, stocks, 1)
+	assert.Equal(t, 5, stocks[0].OnHand)
+	assert.Equal(t, 3, stocks[0].Reserved)
+
+	available, err := r.Available(ctx, []string{shoes.ID, bag.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{shoes.ID: 2, bag.ID: 0}, available)
+}
+
+func testReserveInsufficientStock(
+	t *testing.T, r inventory.Repository, products product.Repository) {
+	ctx := context.Background()
+	shoes, bag := create(t, products, 1), create(t, products, 2)
+	adjust(t, r, shoes.ID, inventory.DefaultWarehouse, 5)
+
+	_, err := r.Reserve(ctx, newReservation(time.Hour, item(shoes.ID, 6)))
+	assertInsufficientStock(t, err, shoes.ID, inventory.DefaultWarehouse, 5)
+
+	// nothing is reserved when one of the items is not available.
+	_, err = r.Reserve(ctx, newReservation(time.Hour, item(shoes.ID, 1), item(bag.ID, 1)))
+	assertInsufficientStock(t, err, bag.ID, inventory.DefaultWarehouse, 0)
+
+	available, err := r.Available(ctx, []string{shoes.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 5, available[shoes.ID])
+}
+
+func testConcurrentReservations(
+	t *testing.T, r inventory.Repository, products product.Repository) {
+	const stock, baskets = 10, 30
+	shoes, bag := create(t, products, 1), create(t, products, 2)
+	adjust(t, r, shoes.ID, inventory.DefaultWarehouse, stock)
+	adjust(t, r, bag.ID, inventory.DefaultWarehouse, stock)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < baskets; i++ {
+		// items are given in both orders to catch lock ordering deadlocks.
+		items := []inventory.ReservationItem{item(shoes.ID, 1), item(bag.ID, 1)}
+		if i%2 == 1 {
+			items[0], items[1] = items[1], items[0]
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := r.Reserve(context.Background(), newReservation(time.Hour, items...))
+			var insufficient *inventory.InsufficientStockError
+			if !errors.As(err, &insufficient) {
+				assert.NoError(t, err)
+			}
+
+			if err == nil {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, stock, reserved)
+
+	available, err := r.Available(context.Background(), []string{shoes.ID, bag.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{shoes.ID: 0, bag.ID: 0}, available)
+}
+
+func testCommitReservation(t *testing.T, r inventory.Repository, products product.Repository) {
+	ctx := context.Background()
+	p := create(t, products, 1)
+	adjust(t, r, p.ID, inventory.DefaultWarehouse, 5)
+	reservation := reserve(t, r, time.Hour, item(p.ID, 3))
+
+	committed, err := r.CommitReservation(ctx, reservation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, inventory.StatusCommitted, committed.Status)
+
+	stocks, err := r.GetStock(ctx, p.ID)
+	require.NoError(t, err)
+	require.Len(t, stocks, 1)
+	assert.Equal(t, 2, stocks[0].OnHand)
+	assert.Equal(t, 0, stocks[0].Reserved)
+
+	_, err = r.CommitReservation(ctx, reservation.ID)
+	assert.ErrorIs(t, err, inventory.ErrReservationNotPending)
+	_, err = r.ReleaseReservation(ctx, reservation.ID)
+	assert.ErrorIs(t, err, inventory.ErrReservationNotPending)
+}
+
+func testReleaseReservation(t *testing.T, r inventory.Repository, products product.Repository) {
+	ctx := context.Background()
+	p := create(t, products, 1)
+	adjust(t, r, p.ID, inventory.DefaultWarehouse, 5)
+	reservation := reserve(t, r, time.Hour, item(p.ID, 3))
+
+	released, err := r.ReleaseReservation(ctx, reservation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, inventory.StatusReleased, released.Status)
+
+	available, err := r.Available(ctx, []string{p.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 5, available[p.ID])
+
+	_, err = r.CommitReservation(ctx, reservation.ID)
+	assert.ErrorIs(t, err, inventory.ErrReservationNotPending)
+}
+
+func testExpiredReservation(t *testing.T, r inventory.Repository, products product.Repository) {
+	ctx := context.Background()
+	p := create(t, products, 1)
+	adjust(t, r, p.ID, inventory.DefaultWarehouse, 5)
+	expired := reserve(t, r, -time.Minute, item(p.ID, 3))
+
+	available, err := r.Available(ctx, []string{p.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 5, available[p.ID])
+
+	_, err = r.CommitReservation(ctx, expired.ID)
+	assert.ErrorIs(t, err, inventory.ErrReservationExpired)
+
+	released, err := r.ReleaseReservation(ctx, expired.ID)
+	require.NoError(t, err)
+	assert.Equal(t, inventory.StatusReleased, released.Status)
+}
+
+func adjust(
+	t *testing.T, r inventory.Repository, productID, warehouse string, delta int) *inventory.Stock {
+	t.Helper()
+
+	stock, err := r.AdjustStock(context.Background(), productID, warehouse, delta)
+	require.NoError(t, err)
+
+	return stock
+}
+
+func reserve(t *testing.T, r inventory.Repository,
+	ttl time.Duration, items ...inventory.ReservationItem) *inventory.Reservation {
+	t.Helper()
+
+	reservation, err := r.Reserve(context.Background(), newReservation(ttl, items...))
+	require.NoError(t, err)
+
+	return reservation
+}
+
+func newReservation(ttl time.Duration, items ...inventory.ReservationItem) *inventory.Reservation {
+	return &inventory.Reservation{
+		ID:        uuid.New().String(),
+		BasketID:  uuid.New().String(),
+		Status:    inventory.StatusPending,
+		Items:     items,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
+
+func item(productID string, quantity int) inventory.ReservationItem {
+	return inventory.ReservationItem{
+		ProductID: productID,
+		Warehouse: inventory.DefaultWarehouse,
+		Quantity:  quantity,
+	}
+}
+
+func assertInsufficientStock(
+	t *testing.T, err error, productID, warehouse string, available int) {
+	t.Helper()
+
+	var insufficient *inventory.InsufficientStockError
+	require.ErrorAs(t, err, &insufficient)
+	assert.Equal(t, productID, insufficient.ProductID)
+	assert.Equal(t, warehouse, insufficient.Warehouse)
+	assert.Equal(t, available, insufficient.Available)
+}
@@ -0,0 +1,159 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/promotion"
+	"github.com/pact-cdc-example/product-service/app/promotion/engine"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PromotionFactory returns an empty promotion repository.
+type PromotionFactory func(t *testing.T) promotion.Repository
+
+// RunPromotions runs the conformance suite of promotion.Repository.
+func RunPromotions(t *testing.T, factory PromotionFactory) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, r promotion.Repository)
+	}{
+		{"CreatePromotion", testCreatePromotion},
+		{"RunningPromotions", testRunningPromotions},
+		{"UpdatePromotion", testUpdatePromotion},
+		{"DeletePromotion", testDeletePromotion},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			c.run(t, factory(t))
+		})
+	}
+}
+
+func newPromotion(startsAt, endsAt *time.Time) *promotion.Promotion {
+	return &promotion.Promotion{
+		ID:           uuid.New().String(),
+		Name:         "summer sale",
+		Kind:         engine.Percentage,
+		Percentage:   big.NewRat(25, 2),
+		ProductTypes: []string{"shoes"},
+		StartsAt:     startsAt,
+		EndsAt:       endsAt,
+	}
+}
+
+func testCreatePromotion(t *testing.T, r promotion.Repository) {
+	ctx := context.Background()
+
+	percentage, err := r.CreatePromotion(ctx, newPromotion(nil, nil))
+	require.NoError(t, err)
+	assert.False(t, percentage.CreatedAt.IsZero())
+
+	fixed := newPromotion(nil, nil)
+	fixed.Kind = engine.FixedAmount
+	fixed.Percentage = nil
+	fixed.Amount = money.New(1050, "EUR")
+	fixed.Stackable = true
+	fixed.ProductTypes = nil
+	fixed.Providers = []string{"acme"}
+	_, err = r.CreatePromotion(ctx, fixed)
+	require.NoError(t, err)
+
+	found, err := r.GetPromotion(ctx, percentage.ID)
+	require.NoError(t, err)
+	assert.Equal(t, engine.Percentage, found.Kind)
+	require.NotNil(t, found.Percentage)
+	assert.Equal(t, "12.50", found.Percentage.FloatString(2))
+	assert.Equal(t, []string{"shoes"}, found.ProductTypes)
+	assert.Empty(t, found.Providers)
+	assert.Nil(t, found.StartsAt)
+
+	found, err = r.GetPromotion(ctx, fixed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, engine.FixedAmount, found.Kind)
+	assert.Nil(t, found.Percentage)
+	assert.Equal(t, money.New(1050, "EUR"), found.Amount)
+	assert.True(t, found.Stackable)
+	assert.Equal(t, []string{"acme"}, found.Providers)
+
+	promotions, err := r.ListPromotions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, promotions, 2)
+
+	_, err = r.GetPromotion(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testRunningPromotions(t *testing.T, r promotion.Repository) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	running := []*promotion.Promotion{
+		newPromotion(nil, nil),
+		newPromotion(&past, &future),
+		newPromotion(&now, nil),
+	}
+	notRunning := []*promotion.Promotion{
+		newPromotion(&future, nil),
+		newPromotion(nil, &now),
+	}
+	for _, p := range append(running, notRunning...) {
+		_, err := r.CreatePromotion(ctx, p)
+		require.NoError(t, err)
+	}
+
+	promotions, err := r.ListRunningPromotions(ctx, now)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, p := range promotions {
+		ids = append(ids, p.ID)
+	}
+	assert.ElementsMatch(t,
+		[]string{running[0].ID, running[1].ID, running[2].ID}, ids)
+}
+
+func testUpdatePromotion(t *testing.T, r promotion.Repository) {
+	ctx := context.Background()
+
+	created, err := r.CreatePromotion(ctx, newPromotion(nil, nil))
+	require.NoError(t, err)
+
+	end := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Second)
+	changed := *created
+	changed.Name = "winter sale"
+	changed.Percentage = big.NewRat(30, 1)
+	changed.EndsAt = &end
+	updated, err := r.UpdatePromotion(ctx, &changed)
+	require.NoError(t, err)
+	assert.Equal(t, "winter sale", updated.Name)
+	assert.Equal(t, "30.00", updated.Percentage.FloatString(2))
+	require.NotNil(t, updated.EndsAt)
+	assert.True(t, end.Equal(*updated.EndsAt))
+	assert.True(t, created.CreatedAt.Equal(updated.CreatedAt))
+
+	_, err = r.UpdatePromotion(ctx, newPromotion(nil, nil))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeletePromotion(t *testing.T, r promotion.Repository) {
+	ctx := context.Background()
+
+	created, err := r.CreatePromotion(ctx, newPromotion(nil, nil))
+	require.NoError(t, err)
+
+	require.NoError(t, r.DeletePromotion(ctx, created.ID))
+
+	_, err = r.GetPromotion(ctx, created.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, r.DeletePromotion(ctx, created.ID), sql.ErrNoRows)
+}
@@ -0,0 +1,959 @@
+// Package repositorytest provides a conformance suite every product.Repository
+// implementation is expected to pass, so that the backends stay
+// interchangeable.
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty repository. It is called once per test case.
+type Factory func(t *testing.T) product.Repository
+
+// Run runs the conformance suite against the repositories built by factory.
+func Run(t *testing.T, factory Factory) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, r product.Repository)
+	}{
+		{"GetProductByIDNotFound", testGetProductByIDNotFound},
+		{"CreateProduct", testCreateProduct},
+		{"CreateProductDuplicateID", testCreateProductDuplicateID},
+		{"CreateProductDuplicateCode", testCreateProductDuplicateCode},
+		{"UpdateProductDuplicateCode", testUpdateProductDuplicateCode},
+		{"GetProductByCode", testGetProductByCode},
+		{"CreateProducts", testCreateProducts},
+		{"CreateProductsIsAtomic", testCreateProductsIsAtomic},
+		{"CreateProductsSkippingConflicts", testCreateProductsSkippingConflicts},
+		{"GetProductsByIDs", testGetProductsByIDs},
+		{"GetProductsByIDsEmpty", testGetProductsByIDsEmpty},
+		{"UpdateProduct", testUpdateProduct},
+		{"UpdateProductNotFound", testUpdateProductNotFound},
+		{"DeleteAndRestoreProduct", testDeleteAndRestoreProduct},
+		{"ListProductsFilters", testListProductsFilters},
+		{"ListProductsPagination", testListProductsPagination},
+		{"ListProductsSortByPriceMixedCurrencies", testListProductsSortByPriceMixedCurrencies},
+		{"SearchProducts", testSearchProducts},
+		{"SearchProductsTypoFallback", testSearchProductsTypoFallback},
+		{"ExportProducts", testExportProducts},
+		{"Variants", testVariants},
+		{"VariantErrors", testVariantErrors},
+		{"VariantPriceKeepsCurrency", testVariantPriceKeepsCurrency},
+		{"ScheduledPrices", testScheduledPrices},
+		{"ScheduledPriceUnknownProduct", testScheduledPriceUnknownProduct},
+		{"PriceHistory", testPriceHistory},
+		{"Images", testImages},
+		{"ImageErrors", testImageErrors},
+		{"CanceledContext", testCanceledContext},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			c.run(t, factory(t))
+		})
+	}
+}
+
+func testGetProductByIDNotFound(t *testing.T, r product.Repository) {
+	p, err := r.GetProductByID(context.Background(), uuid.New().String(), product.FindOptions{})
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Nil(t, p)
+}
+
+func testCreateProduct(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	given := newProduct(1)
+
+	before := time.Now().Add(-time.Minute)
+	created, err := r.CreateProduct(ctx, copyOf(given), "")
+	require.NoError(t, err)
+
+	assert.True(t, created.CreatedAt.After(before), "created_at must be set")
+	assert.True(t, created.UpdatedAt.After(before), "updated_at must be set")
+
+	got, err := r.GetProductByID(ctx, given.ID, product.FindOptions{})
+	require.NoError(t, err)
+	assertSameProduct(t, given, got)
+	assert.True(t, created.CreatedAt.Equal(got.CreatedAt))
+	assert.Nil(t, got.DeletedAt)
+}
+
+func testCreateProductDuplicateID(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	given := newProduct(1)
+
+	_, err := r.CreateProduct(ctx, copyOf(given), "")
+	require.NoError(t, err)
+
+	_, err = r.CreateProduct(ctx, copyOf(given), "")
+	assert.Error(t, err)
+}
+
+func testCreateProductDuplicateCode(t *testing.T, r product.Repository) {
+	existing := create(t, r, 1)
+
+	duplicate := newProduct(2)
+	duplicate.Code = existing.Code
+
+	_, err := r.CreateProduct(context.Background(), duplicate, "")
+	assert.ErrorIs(t, err, product.ErrProductCodeAlreadyExists)
+}
+
+func testUpdateProductDuplicateCode(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	first, second := create(t, r, 1), create(t, r, 2)
+
+	changed := copyOf(second)
+	changed.Code = first.Code
+	_, err := r.UpdateProduct(ctx, changed, "")
+	assert.ErrorIs(t, err, product.ErrProductCodeAlreadyExists)
+
+	unchanged := copyOf(second)
+	_, err = r.UpdateProduct(ctx, unchanged, "")
+	assert.NoError(t, err, "a product keeps its own code")
+}
+
+func testGetProductByCode(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	created := create(t, r, 1)
+
+	got, err := r.GetProductByCode(ctx, created.Code)
+	require.NoError(t, err)
+	assertSameProduct(t, created, got)
+
+	_, err = r.GetProductByCode(ctx, "unknown")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, r.DeleteProduct(ctx, created.ID))
+	_, err = r.GetProductByCode(ctx, created.Code)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testCreateProducts(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	given := []*product.Product{newProduct(1), newProduct(2), newProduct(3)}
+
+	created, err := r.CreateProducts(ctx, []*product.Product{
+		copyOf(given[0]), copyOf(given[1]), copyOf(given[2]),
+	}, "")
+	require.NoError(t, err)
+	require.Len(t, created, len(given))
+
+	for i := range given {
+		assert.False(t, created[i].CreatedAt.IsZero(), "created_at must be set")
+
+		got, err := r.GetProductByID(ctx, given[i].ID, product.FindOptions{})
+		require.NoError(t, err)
+		assertSameProduct(t, given[i], got)
+	}
+}
+
+func testCreateProductsIsAtomic(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	existing := create(t, r, 1)
+
+	valid, duplicate := newProduct(2), newProduct(3)
+	duplicate.Code = existing.Code
+
+	_, err := r.CreateProducts(ctx, []*product.Product{valid, duplicate}, "")
+	assert.ErrorIs(t, err, product.ErrProductCodeAlreadyExists)
+
+	var conflict *product.ProductCodeConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, existing.Code, conflict.Code)
+	}
+
+	_, err = r.GetProductByID(ctx, valid.ID, product.FindOptions{})
+	assert.ErrorIs(t, err, sql.ErrNoRows, "no product of a failed batch is created")
+}
+
+func testCreateProductsSkippingConflicts(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	existing := create(t, r, 1)
+
+	first, duplicate, repeated, last := newProduct(2), newProduct(3), newProduct(4), newProduct(5)
+	duplicate.Code = existing.Code
+	repeated.Code = first.Code
+
+	created, err := r.CreateProductsSkippingConflicts(ctx, []*product.Product{
+		copyOf(first), duplicate, repeated, copyOf(last),
+	}, "")
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	assert.Equal(t, first.ID, created[0].ID)
+	assert.Equal(t, last.ID, created[1].ID)
+	assert.False(t, created[0].CreatedAt.IsZero(), "created_at must be set")
+
+	for _, given := range []*product.Product{first, last} {
+		got, err := r.GetProductByID(ctx, given.ID, product.FindOptions{})
+		require.NoError(t, err)
+		assertSameProduct(t, given, got)
+	}
+
+	for _, skipped := range []*product.Product{duplicate, repeated} {
+		_, err = r.GetProductByID(ctx, skipped.ID, product.FindOptions{})
+		assert.ErrorIs(t, err, sql.ErrNoRows, "products with taken codes are skipped")
+	}
+}
+
+func testGetProductsByIDs(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	first, second, third := create(t, r, 1), create(t, r, 2), create(t, r, 3)
+
+	products, err := r.GetProductsByIDs(ctx, []string{
+		third.ID, uuid.New().String(), first.ID, third.ID, second.ID,
+	}, product.FindOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, products, 3, "missing ids are skipped and duplicates returned once")
+	assert.Equal(t, third.ID, products[0].ID, "the requested order is preserved")
+	assert.Equal(t, first.ID, products[1].ID)
+	assert.Equal(t, second.ID, products[2].ID)
+	assertSameProduct(t, first, &products[1])
+}
+
+func testGetProductsByIDsEmpty(t *testing.T, r product.Repository) {
+	create(t, r, 1)
+
+	products, err := r.GetProductsByIDs(context.Background(), []string{}, product.FindOptions{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, products)
+}
+
+func testUpdateProduct(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	created := create(t, r, 1)
+
+	changed := newProduct(2)
+	changed.ID = created.ID
+
+	updated, err := r.UpdateProduct(ctx, copyOf(changed), "")
+	require.NoError(t, err)
+	assert.True(t, updated.CreatedAt.Equal(created.CreatedAt), "created_at must not change")
+	assert.False(t, updated.UpdatedAt.Before(created.UpdatedAt), "updated_at must be bumped")
+
+	got, err := r.GetProductByID(ctx, created.ID, product.FindOptions{})
+	require.NoError(t, err)
+	assertSameProduct(t, changed, got)
+}
+
+func testUpdateProductNotFound(t *testing.T, r product.Repository) {
+	_, err := r.UpdateProduct(context.Background(), newProduct(1), "")
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteAndRestoreProduct(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	deleted, kept := create(t, r, 1), create(t, r, 2)
+
+	require.NoError(t, r.DeleteProduct(ctx, deleted.ID))
+	assert.ErrorIs(t, r.DeleteProduct(ctx, deleted.ID), sql.ErrNoRows, "already deleted")
+	assert.ErrorIs(t, r.DeleteProduct(ctx, uuid.New().String()), sql.ErrNoRows)
+
+	_, err := r.GetProductByID(ctx, deleted.ID, product.FindOptions{})
+	assert.ErrorIs(t, err, sql.ErrNoRows, "deleted products are hidden by default")
+
+	got, err := r.GetProductByID(ctx, deleted.ID, product.FindOptions{IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.NotNil(t, got.DeletedAt)
+
+	products, err := r.GetProductsByIDs(ctx, []string{deleted.ID, kept.ID}, product.FindOptions{})
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, kept.ID, products[0].ID)
+
+	_, err = r.UpdateProduct(ctx, copyOf(deleted), "")
+	assert.ErrorIs(t, err, sql.ErrNoRows, "deleted products can not be updated")
+
+	restored, err := r.RestoreProduct(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+
+	_, err = r.RestoreProduct(ctx, deleted.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "only deleted products can be restored")
+
+	_, err = r.GetProductByID(ctx, deleted.ID, product.FindOptions{})
+	assert.NoError(t, err)
+}
+
+func testListProductsFilters(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	cheapBag := create(t, r, 1, func(p *product.Product) {
+		p.Type, p.SellingPrice, p.Color = product.Bag, price(1000), "red"
+	})
+	create(t, r, 2, func(p *product.Product) {
+		p.Type, p.SellingPrice, p.Color = product.Bag, price(50000), "red"
+	})
+	create(t, r, 3, func(p *product.Product) {
+		p.Type, p.SellingPrice, p.Color = product.Hat, price(1000), "red"
+	})
+	deleted := create(t, r, 4, func(p *product.Product) {
+		p.Type, p.SellingPrice, p.Color = product.Bag, price(1000), "red"
+	})
+	require.NoError(t, r.DeleteProduct(ctx, deleted.ID))
+	create(t, r, 5, func(p *product.Product) {
+		p.Type, p.Color = product.Bag, "red"
+		p.BuyingPrice, p.SellingPrice = money.New(500, "EUR"), money.New(1000, "EUR")
+	})
+
+	maxPrice := price(10000)
+	filter := product.ListFilter{
+		Types:    []string{string(product.Bag)},
+		Colors:   []string{"red"},
+		MaxPrice: &maxPrice,
+		Sort:     product.ListSort{Field: product.SortByCreatedAt},
+		Limit:    10,
+	}
+
+	products, err := r.ListProducts(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, cheapBag.ID, products[0].ID)
+
+	filter.IncludeDeleted = true
+	products, err = r.ListProducts(ctx, filter)
+	require.NoError(t, err)
+	assert.Len(t, products, 2)
+}
+
+func testListProductsPagination(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	prices := []int64{3000, 1000, 2000, 1000, 4000}
+	for i, amount := range prices {
+		amount := amount
+		create(t, r, i, func(p *product.Product) { p.SellingPrice = price(amount) })
+	}
+
+	for _, sort := range []product.ListSort{
+		{Field: product.SortByCreatedAt},
+		{Field: product.SortByCreatedAt, Descending: true},
+		{Field: product.SortBySellingPrice},
+		{Field: product.SortBySellingPrice, Descending: true},
+		{Field: product.SortByName},
+	} {
+		all, err := r.ListProducts(ctx, product.ListFilter{Sort: sort, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, all, len(prices))
+
+		var paged []product.Product
+		filter := product.ListFilter{Sort: sort, Limit: 2}
+		for {
+			page, err := r.ListProducts(ctx, filter)
+			require.NoError(t, err)
+			paged = append(paged, page...)
+
+			if len(page) < filter.Limit {
+				break
+			}
+
+			cursor := product.NewCursor(sort, &page[len(page)-1])
+			filter.After = &cursor
+		}
+
+		require.Len(t, paged, len(all), "sort %s", sort)
+		for i := range all {
+			assert.Equal(t, all[i].ID, paged[i].ID, "sort %s", sort)
+		}
+	}
+}
+
+func testListProductsSortByPriceMixedCurrencies(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	prices := []money.Money{
+		money.New(10000, "EUR"),
+		money.New(5000, product.DefaultCurrency),
+		money.New(5000, "EUR"),
+		money.New(10000, product.DefaultCurrency),
+		money.New(7500, "USD"),
+	}
+	ids := make([]string, len(prices))
+	for i, sellingPrice := range prices {
+		sellingPrice := sellingPrice
+		ids[i] = create(t, r, i, func(p *product.Product) {
+			p.BuyingPrice = money.New(0, sellingPrice.Currency)
+			p.SellingPrice, p.Prices = sellingPrice, nil
+		}).ID
+	}
+
+	// the amounts of different currencies are not comparable, the products
+	// are sorted by their currency first.
+	expected := []string{ids[2], ids[0], ids[1], ids[3], ids[4]}
+	for _, sort := range []product.ListSort{
+		{Field: product.SortBySellingPrice},
+		{Field: product.SortBySellingPrice, Descending: true},
+	} {
+		var paged []string
+		filter := product.ListFilter{Sort: sort, Limit: 2}
+		for {
+			page, err := r.ListProducts(ctx, filter)
+			require.NoError(t, err)
+			paged = append(paged, productIDs(page)...)
+
+			if len(page) < filter.Limit {
+				break
+			}
+
+			cursor := product.NewCursor(sort, &page[len(page)-1])
+			filter.After = &cursor
+		}
+
+		assert.Equal(t, expected, paged, "sort %s", sort)
+		expected = reversed(expected)
+	}
+}
+
+func reversed(ids []string) []string {
+	r := make([]string, len(ids))
+	for i, id := range ids {
+		r[len(ids)-1-i] = id
+	}
+
+	return r
+}
+
+func testSearchProducts(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	travelBag := create(t, r, 1, func(p *product.Product) {
+		p.Name, p.Type = "Leather Travel Bag", product.Bag
+	})
+	tote := create(t, r, 2, func(p *product.Product) {
+		p.Name, p.Type = "Leather Tote", product.Bag
+	})
+	jacket := create(t, r, 3, func(p *product.Product) {
+		p.Name, p.Type = "Leather Jacket", product.Jacket
+	})
+	deleted := create(t, r, 4, func(p *product.Product) {
+		p.Name, p.Type = "Leather Wallet", product.Wallet
+	})
+	require.NoError(t, r.DeleteProduct(ctx, deleted.ID))
+
+	products, err := r.SearchProducts(ctx, product.SearchFilter{Query: "bag", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+	assert.Equal(t, travelBag.ID, products[0].ID, "name matches rank above type matches")
+	assert.Equal(t, tote.ID, products[1].ID)
+
+	products, err = r.SearchProducts(ctx, product.SearchFilter{Query: "leather", Limit: 10})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{travelBag.ID, tote.ID, jacket.ID}, productIDs(products))
+
+	products, err = r.SearchProducts(ctx, product.SearchFilter{Query: "leather -bag", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{jacket.ID}, productIDs(products))
+
+	products, err = r.SearchProducts(ctx, product.SearchFilter{
+		Query: "leather", Limit: 10, IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Len(t, products, 4)
+
+	products, err = r.SearchProducts(ctx, product.SearchFilter{Query: "leather", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, products, 2)
+}
+
+func testSearchProductsTypoFallback(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	jacket := create(t, r, 1, func(p *product.Product) { p.Name = "Leather Jacket" })
+	create(t, r, 2, func(p *product.Product) { p.Name = "Denim Jacket" })
+
+	products, err := r.SearchProducts(ctx, product.SearchFilter{Query: "lether", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{jacket.ID}, productIDs(products))
+
+	products, err = r.SearchProducts(ctx, product.SearchFilter{Query: "umbrella", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, products)
+}
+
+func productIDs(products []product.Product) []string {
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	return ids
+}
+
+func testExportProducts(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	var expected []string
+	for i := 0; i < 5; i++ {
+		expected = append(expected, create(t, r, i, func(p *product.Product) {
+			p.Type = product.Bag
+		}).ID)
+	}
+	create(t, r, 5, func(p *product.Product) { p.Type = product.Hat })
+
+	var exported []string
+	err := r.ExportProducts(ctx, product.ListFilter{
+		Types: []string{string(product.Bag)},
+		Sort:  product.ListSort{Field: product.SortByCreatedAt},
+		Limit: 2,
+	}, func(p *product.Product) error {
+		exported = append(exported, p.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, expected, exported)
+
+	stop := errors.New("stop")
+	calls := 0
+	err = r.ExportProducts(ctx, product.ListFilter{
+		Sort: product.ListSort{Field: product.SortByCreatedAt},
+	}, func(p *product.Product) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func testVariants(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	shoe := create(t, r, 1)
+	other := create(t, r, 2)
+
+	variantPrice := price(3050)
+	small, err := r.CreateVariant(ctx, newVariant(shoe.ID, "SHOE-38", &variantPrice))
+	require.NoError(t, err)
+	assert.False(t, small.CreatedAt.IsZero())
+	large, err := r.CreateVariant(ctx, newVariant(shoe.ID, "SHOE-44", nil))
+	require.NoError(t, err)
+	_, err = r.CreateVariant(ctx, newVariant(other.ID, "OTHER-1", nil))
+	require.NoError(t, err)
+
+	found, err := r.GetVariant(ctx, shoe.ID, small.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "SHOE-38", found.SKU)
+	require.NotNil(t, found.Price)
+	assert.Equal(t, variantPrice, *found.Price)
+
+	variants, err := r.ListVariants(ctx, shoe.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{small.ID, large.ID}, variantIDs(variants))
+
+	variants, err = r.GetVariantsBySKUs(ctx, []string{"SHOE-44", "OTHER-1", "MISSING", "SHOE-44"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"SHOE-44", "OTHER-1"}, variantSKUs(variants))
+
+	large.Size, large.Price = "45", &variantPrice
+	updated, err := r.UpdateVariant(ctx, large)
+	require.NoError(t, err)
+	assert.Equal(t, "45", updated.Size)
+	require.NotNil(t, updated.Price)
+	assert.Equal(t, variantPrice, *updated.Price)
+
+	require.NoError(t, r.DeleteVariant(ctx, shoe.ID, small.ID))
+	_, err = r.GetVariant(ctx, shoe.ID, small.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testVariantPriceKeepsCurrency(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	shoe := create(t, r, 1)
+
+	variantPrice := price(3050)
+	variant, err := r.CreateVariant(ctx, newVariant(shoe.ID, "SHOE-38", &variantPrice))
+	require.NoError(t, err)
+
+	shoe.BuyingPrice, shoe.SellingPrice = money.New(12, "EUR"), money.New(25, "EUR")
+	_, err = r.UpdateProduct(ctx, shoe, "")
+	require.NoError(t, err)
+
+	found, err := r.GetVariant(ctx, shoe.ID, variant.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.Price)
+	assert.Equal(t, variantPrice, *found.Price,
+		"the variant price is not reinterpreted in the new currency of its product")
+
+	eurPrice := money.New(2850, "EUR")
+	found.Price = &eurPrice
+	updated, err := r.UpdateVariant(ctx, found)
+	require.NoError(t, err)
+	require.NotNil(t, updated.Price)
+	assert.Equal(t, eurPrice, *updated.Price)
+}
+
+func testVariantErrors(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	shoe := create(t, r, 1)
+	other := create(t, r, 2)
+
+	variant, err := r.CreateVariant(ctx, newVariant(shoe.ID, "SHOE-38", nil))
+	require.NoError(t, err)
+	taken, err := r.CreateVariant(ctx, newVariant(shoe.ID, "SHOE-40", nil))
+	require.NoError(t, err)
+
+	_, err = r.CreateVariant(ctx, newVariant(other.ID, "SHOE-38", nil))
+	assert.ErrorIs(t, err, product.ErrVariantSKUAlreadyExists)
+
+	_, err = r.CreateVariant(ctx, newVariant(uuid.New().String(), "GHOST-1", nil))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	taken.SKU = variant.SKU
+	_, err = r.UpdateVariant(ctx, taken)
+	assert.ErrorIs(t, err, product.ErrVariantSKUAlreadyExists)
+
+	_, err = r.GetVariant(ctx, other.ID, variant.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "variants are scoped to their product")
+
+	moved := *variant
+	moved.ProductID = other.ID
+	_, err = r.UpdateVariant(ctx, &moved)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	assert.ErrorIs(t, r.DeleteVariant(ctx, other.ID, variant.ID), sql.ErrNoRows)
+}
+
+func newVariant(productID, sku string, price *money.Money) *product.Variant {
+	return &product.Variant{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		SKU:       sku,
+		Size:      "42",
+		Color:     "white",
+		Barcode:   "4006381333931",
+		Price:     price,
+	}
+}
+
+func variantIDs(variants []product.Variant) []string {
+	ids := make([]string, 0, len(variants))
+	for _, v := range variants {
+		ids = append(ids, v.ID)
+	}
+
+	return ids
+}
+
+func variantSKUs(variants []product.Variant) []string {
+	skus := make([]string, 0, len(variants))
+	for _, v := range variants {
+		skus = append(skus, v.SKU)
+	}
+
+	return skus
+}
+
+func testScheduledPrices(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	shoe := create(t, r, 1)
+	other := create(t, r, 2)
+
+	// the history starts with the prices the products are created with.
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	end := start.Add(24 * time.Hour)
+	regular, err := r.CreateScheduledPrice(ctx, newScheduledPrice(shoe.ID, 2000, start, nil))
+	require.NoError(t, err)
+	assert.False(t, regular.CreatedAt.IsZero())
+	sale, err := r.CreateScheduledPrice(ctx,
+		newScheduledPrice(shoe.ID, 1500, start.Add(2*time.Hour), &end))
+	require.NoError(t, err)
+	assert.Equal(t, "bob", sale.ChangedBy)
+	require.NotNil(t, sale.ValidTo)
+	assert.True(t, end.Equal(*sale.ValidTo))
+	_, err = r.CreateScheduledPrice(ctx, newScheduledPrice(other.ID, 900, start, nil))
+	require.NoError(t, err)
+
+	history, err := r.ListScheduledPrices(ctx, shoe.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	initial := history[1]
+	assert.Equal(t, []string{sale.ID, initial.ID, regular.ID}, scheduledPriceIDs(history))
+	assert.Equal(t, price(1500), history[0].Price)
+	assert.Equal(t, shoe.SellingPrice, initial.Price)
+
+	inEffect, err := r.GetScheduledPricesAt(ctx, []string{shoe.ID}, start.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []string{regular.ID}, scheduledPriceIDs(inEffect))
+
+	inEffect, err = r.GetScheduledPricesAt(ctx, []string{shoe.ID, other.ID}, start.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, inEffect, 5)
+
+	inEffect, err = r.GetScheduledPricesAt(ctx, []string{shoe.ID}, end)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{initial.ID, regular.ID}, scheduledPriceIDs(inEffect),
+		"windows exclude their end")
+
+	inEffect, err = r.GetScheduledPricesAt(ctx, []string{shoe.ID}, start.Add(-time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, inEffect)
+}
+
+func testPriceHistory(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+
+	shoe, err := r.CreateProduct(ctx, newProduct(1), "alice")
+	require.NoError(t, err)
+	assertPriceHistory(t, r, shoe.ID, priceChange{shoe.SellingPrice, "alice"})
+	history, err := r.ListScheduledPrices(ctx, shoe.ID)
+	require.NoError(t, err)
+	assert.True(t, shoe.UpdatedAt.Equal(history[0].ValidFrom),
+		"the price is in effect from the time the product is saved")
+
+	shoe.Name = "renamed"
+	_, err = r.UpdateProduct(ctx, copyOf(shoe), "bob")
+	require.NoError(t, err)
+	assertPriceHistory(t, r, shoe.ID, priceChange{shoe.SellingPrice, "alice"})
+
+	shoe.SellingPrice = price(3000)
+	updated, err := r.UpdateProduct(ctx, copyOf(shoe), "bob")
+	require.NoError(t, err)
+	assertPriceHistory(t, r, shoe.ID,
+		priceChange{price(3000), "bob"}, priceChange{price(2575), "alice"})
+	history, err = r.ListScheduledPrices(ctx, shoe.ID)
+	require.NoError(t, err)
+	assert.True(t, updated.UpdatedAt.Equal(history[0].ValidFrom))
+
+	batch := []*product.Product{newProduct(2), newProduct(3)}
+	_, err = r.CreateProducts(ctx, batch, "carol")
+	require.NoError(t, err)
+	for _, p := range batch {
+		assertPriceHistory(t, r, p.ID, priceChange{p.SellingPrice, "carol"})
+	}
+
+	valid, duplicate := newProduct(4), newProduct(5)
+	duplicate.Code = shoe.Code
+	_, err = r.CreateProducts(ctx, []*product.Product{valid, duplicate}, "carol")
+	require.Error(t, err)
+	assertPriceHistory(t, r, valid.ID)
+
+	_, err = r.CreateProductsSkippingConflicts(ctx, []*product.Product{valid, duplicate}, "dave")
+	require.NoError(t, err)
+	assertPriceHistory(t, r, valid.ID, priceChange{valid.SellingPrice, "dave"})
+	assertPriceHistory(t, r, shoe.ID,
+		priceChange{price(3000), "bob"}, priceChange{price(2575), "alice"})
+}
+
+func testScheduledPriceUnknownProduct(t *testing.T, r product.Repository) {
+	_, err := r.CreateScheduledPrice(context.Background(),
+		newScheduledPrice(uuid.New().String(), 1000, time.Now(), nil))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testImages(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	shoe := create(t, r, 1)
+	other := create(t, r, 2)
+
+	first, err := r.CreateImage(ctx, newImage(shoe.ID))
+	require.NoError(t, err)
+	assert.Equal(t, 0, first.Position)
+	assert.Equal(t, []int{160, 480}, first.ThumbnailSizes)
+	assert.False(t, first.CreatedAt.IsZero())
+	second, err := r.CreateImage(ctx, newImage(shoe.ID))
+	require.NoError(t, err)
+	assert.Equal(t, 1, second.Position)
+	third, err := r.CreateImage(ctx, newImage(shoe.ID))
+	require.NoError(t, err)
+	_, err = r.CreateImage(ctx, newImage(other.ID))
+	require.NoError(t, err)
+
+	found, err := r.GetImage(ctx, shoe.ID, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, second.Key, found.Key)
+	assert.Equal(t, "image/png", found.ContentType)
+	assert.Equal(t, int64(2048), found.Size)
+	assert.Equal(t, 640, found.Width)
+
+	images, err := r.GetImagesByProductIDs(ctx, []string{shoe.ID, other.ID, shoe.ID})
+	require.NoError(t, err)
+	assert.Len(t, images, 4)
+
+	require.NoError(t, r.ReorderImages(ctx, shoe.ID, []string{third.ID, first.ID, second.ID}))
+	images, err = r.ListImages(ctx, shoe.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{third.ID, first.ID, second.ID}, imageIDs(images))
+	assert.Equal(t, []int{0, 1, 2}, imagePositions(images))
+
+	require.NoError(t, r.DeleteImage(ctx, shoe.ID, third.ID))
+	images, err = r.ListImages(ctx, shoe.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID, second.ID}, imageIDs(images))
+	assert.Equal(t, []int{0, 1}, imagePositions(images))
+
+	fourth, err := r.CreateImage(ctx, newImage(shoe.ID))
+	require.NoError(t, err)
+	assert.Equal(t, 2, fourth.Position)
+}
+
+func testImageErrors(t *testing.T, r product.Repository) {
+	ctx := context.Background()
+	shoe := create(t, r, 1)
+	other := create(t, r, 2)
+
+	_, err := r.CreateImage(ctx, newImage(uuid.New().String()))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	image, err := r.CreateImage(ctx, newImage(shoe.ID))
+	require.NoError(t, err)
+
+	_, err = r.GetImage(ctx, other.ID, image.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, r.DeleteImage(ctx, other.ID, image.ID), sql.ErrNoRows)
+	assert.ErrorIs(t, r.DeleteImage(ctx, shoe.ID, uuid.New().String()), sql.ErrNoRows)
+}
+
+func newImage(productID string) *product.Image {
+	id := uuid.New().String()
+	return &product.Image{
+		ID:             id,
+		ProductID:      productID,
+		Key:            fmt.Sprintf("products/%s/images/%s/original.png", productID, id),
+		ContentType:    "image/png",
+		Size:           2048,
+		Width:          640,
+		Height:         480,
+		ThumbnailSizes: []int{160, 480},
+	}
+}
+
+func imageIDs(images []product.Image) []string {
+	ids := make([]string, 0, len(images))
+	for _, i := range images {
+		ids = append(ids, i.ID)
+	}
+
+	return ids
+}
+
+func imagePositions(images []product.Image) []int {
+	positions := make([]int, 0, len(images))
+	for _, i := range images {
+		positions = append(positions, i.Position)
+	}
+
+	return positions
+}
+
+func newScheduledPrice(
+	productID string, amount int64, validFrom time.Time, validTo *time.Time) *product.ScheduledPrice {
+	return &product.ScheduledPrice{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		Price:     price(amount),
+		ValidFrom: validFrom,
+		ValidTo:   validTo,
+		ChangedBy: "bob",
+	}
+}
+
+// priceChange is an entry of the price history of a product.
+type priceChange struct {
+	price     money.Money
+	changedBy string
+}
+
+func assertPriceHistory(
+	t *testing.T, r product.Repository, productID string, expected ...priceChange) {
+	t.Helper()
+
+	history, err := r.ListScheduledPrices(context.Background(), productID)
+	require.NoError(t, err)
+
+	var changes []priceChange
+	for _, p := range history {
+		changes = append(changes, priceChange{p.Price, p.ChangedBy})
+	}
+	assert.Equal(t, expected, changes)
+}
+
+func scheduledPriceIDs(prices []product.ScheduledPrice) []string {
+	ids := make([]string, 0, len(prices))
+	for _, p := range prices {
+		ids = append(ids, p.ID)
+	}
+
+	return ids
+}
+
+func testCanceledContext(t *testing.T, r product.Repository) {
+	created := create(t, r, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := r.GetProductByID(ctx, created.ID, product.FindOptions{})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = r.GetProductsByIDs(ctx, []string{created.ID}, product.FindOptions{})
+	assert.Error(t, err)
+
+	_, err = r.CreateProduct(ctx, newProduct(2), "")
+	assert.Error(t, err)
+}
+
+func create(t *testing.T, r product.Repository, n int, modifiers ...func(*product.Product)) *product.Product {
+	t.Helper()
+
+	p := newProduct(n)
+	for _, modify := range modifiers {
+		modify(p)
+	}
+
+	created, err := r.CreateProduct(context.Background(), p, "")
+	require.NoError(t, err)
+
+	return created
+}
+
+func newProduct(n int) *product.Product {
+	return &product.Product{
+		ID:           uuid.New().String(),
+		Name:         fmt.Sprintf("product %d", n),
+		Code:         fmt.Sprintf("CODE-%d-%s", n, uuid.New().String()[:8]),
+		Color:        "black",
+		BuyingPrice:  price(1250),
+		SellingPrice: price(2575),
+		Prices:       []money.Money{money.New(75, "EUR"), money.New(1250, "JPY")},
+		ImageURL:     fmt.Sprintf("https://images.example.com/%d.png", n),
+		Type:         product.Shoes,
+		Provider:     "provider",
+		Creator:      "creator",
+		Distributor:  "distributor",
+	}
+}
+
+// price is an amount of the default currency in minor units.
+func price(amount int64) money.Money {
+	return money.New(amount, product.DefaultCurrency)
+}
+
+func copyOf(p *product.Product) *product.Product {
+	c := *p
+	return &c
+}
+
+func assertSameProduct(t *testing.T, expected, actual *product.Product) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Code, actual.Code)
+	assert.Equal(t, expected.Color, actual.Color)
+	assert.Equal(t, expected.BuyingPrice, actual.BuyingPrice)
+	assert.Equal(t, expected.SellingPrice, actual.SellingPrice)
+	assert.Equal(t, expected.Prices, actual.Prices)
+	assert.Equal(t, expected.ImageURL, actual.ImageURL)
+	assert.Equal(t, expected.Type, actual.Type)
+	assert.Equal(t, expected.Provider, actual.Provider)
+	assert.Equal(t, expected.Creator, actual.Creator)
+	assert.Equal(t, expected.Distributor, actual.Distributor)
+}
@@ -0,0 +1,153 @@
+package persistence
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pact-cdc-example/product-service/app/product"
+)
+
+// The pure Go search below mirrors the postgres search closely enough for the
+// in-memory repository: the weights are the ts_rank defaults of the field
+// weights set by the search_vector column and the similarity threshold is the
+// pg_trgm default of the word similarity operator.
+const (
+	searchWeightA = 1.0
+	searchWeightB = 0.4
+	searchWeightC = 0.2
+
+	wordSimilarityThreshold = 0.6
+)
+
+type searchField struct {
+	value  string
+	weight float64
+}
+
+func searchFields(p *product.Product) []searchField {
+	return []searchField{
+		{p.Name, searchWeightA},
+		{p.Code, searchWeightA},
+		{string(p.Type), searchWeightB},
+		{p.Color, searchWeightC},
+		{p.Provider, searchWeightC},
+	}
+}
+
+// searchQuery is a parsed web search query, the terms must all match and the
+// excluded terms must not match at all.
+type searchQuery struct {
+	terms    []string
+	excluded []string
+}
+
+func parseSearchQuery(query string) searchQuery {
+	var q searchQuery
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(word, "-") {
+			q.excluded = append(q.excluded, searchTokens(word[1:])...)
+			continue
+		}
+
+		q.terms = append(q.terms, searchTokens(word)...)
+	}
+
+	return q
+}
+
+// rank returns the full-text relevance of the product, zero when it does not
+// match the query.
+func (q searchQuery) rank(p *product.Product) float64 {
+	if len(q.terms) == 0 {
+		return 0
+	}
+
+	weights := make(map[string]float64)
+	for _, field := range searchFields(p) {
+		for _, token := range searchTokens(field.value) {
+			if field.weight > weights[token] {
+				weights[token] = field.weight
+			}
+		}
+	}
+
+	for _, term := range q.excluded {
+		if _, ok := weights[term]; ok {
+			return 0
+		}
+	}
+
+	var rank float64
+	for _, term := range q.terms {
+		weight, ok := weights[term]
+		if !ok {
+			return 0
+		}
+
+		rank += weight
+	}
+
+	return rank
+}
+
+// searchTokens splits s into lowercase words. Words joined by punctuation,
+// like product codes, are kept along with their parts.
+func searchTokens(s string) []string {
+	var tokens []string
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		parts := strings.FieldsFunc(word, isNotAlphanumeric)
+		if len(parts) == 0 {
+			continue
+		}
+
+		if len(parts) > 1 {
+			tokens = append(tokens, strings.TrimFunc(word, isNotAlphanumeric))
+		}
+		tokens = append(tokens, parts...)
+	}
+
+	return tokens
+}
+
+// wordSimilarity is the share of the trigrams of query found in the product,
+// tolerating typos the full-text search does not.
+func wordSimilarity(query string, p *product.Product) float64 {
+	queryTrigrams := trigrams(query)
+	if len(queryTrigrams) == 0 {
+		return 0
+	}
+
+	values := make([]string, 0, 5)
+	for _, field := range searchFields(p) {
+		values = append(values, field.value)
+	}
+	textTrigrams := trigrams(strings.Join(values, " "))
+
+	shared := 0
+	for trigram := range queryTrigrams {
+		if _, ok := textTrigrams[trigram]; ok {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(queryTrigrams))
+}
+
+// trigrams returns the trigrams of the words of s the way pg_trgm extracts
+// them, every word is lowercased and padded with two spaces in front and one
+// at the end.
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), isNotAlphanumeric) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+func isNotAlphanumeric(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,47 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTokens(t *testing.T) {
+	assert.Equal(t,
+		[]string{"leather", "bag", "code-1-ab12", "code", "1", "ab12"},
+		searchTokens("Leather  BAG code-1-ab12."))
+}
+
+func TestSearchQueryRank(t *testing.T) {
+	p := &product.Product{
+		Name: "Leather Bag", Code: "LB-1", Type: product.Bag, Color: "brown", Provider: "acme",
+	}
+
+	testCases := []struct {
+		query    string
+		expected float64
+	}{
+		{"leather", searchWeightA},
+		{"bag", searchWeightA},
+		{"brown", searchWeightC},
+		{"Leather brown", searchWeightA + searchWeightC},
+		{"lb-1", 3 * searchWeightA},
+		{"leather black", 0},
+		{"leather -brown", 0},
+		{"-black", 0},
+	}
+
+	for _, tc := range testCases {
+		assert.InDelta(t, tc.expected, parseSearchQuery(tc.query).rank(p), 1e-9, tc.query)
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	p := &product.Product{Name: "Leather Jacket", Type: product.Jacket}
+
+	assert.Equal(t, 1.0, wordSimilarity("jacket", p))
+	assert.GreaterOrEqual(t, wordSimilarity("lether", p), wordSimilarityThreshold)
+	assert.Less(t, wordSimilarity("umbrella", p), wordSimilarityThreshold)
+	assert.Zero(t, wordSimilarity("--", p))
+}
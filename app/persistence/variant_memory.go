@@ -0,0 +1,156 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/pact-cdc-example/product-service/app/product"
+)
+
+func (mr *memoryRepository) GetVariant(
+	ctx context.Context, productID, id string) (*product.Variant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	v, ok := mr.variants[id]
+	if !ok || v.ProductID != productID {
+		return nil, sql.ErrNoRows
+	}
+
+	return &v, nil
+}
+
+func (mr *memoryRepository) GetVariantsBySKUs(
+	ctx context.Context, skus []string) ([]product.Variant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]struct{}, len(skus))
+	for _, sku := range skus {
+		wanted[sku] = struct{}{}
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var variants []product.Variant
+	for _, v := range mr.variants {
+		if _, ok := wanted[v.SKU]; ok {
+			variants = append(variants, v)
+		}
+	}
+
+	return variants, nil
+}
+
+func (mr *memoryRepository) ListVariants(
+	ctx context.Context, productID string) ([]product.Variant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.RLock()
+	var variants []product.Variant
+	for _, v := range mr.variants {
+		if v.ProductID == productID {
+			variants = append(variants, v)
+		}
+	}
+	mr.mu.RUnlock()
+
+	sort.Slice(variants, func(i, j int) bool {
+		if !variants[i].CreatedAt.Equal(variants[j].CreatedAt) {
+			return variants[i].CreatedAt.Before(variants[j].CreatedAt)
+		}
+
+		return variants[i].ID < variants[j].ID
+	})
+
+	return variants, nil
+}
+
+func (mr *memoryRepository) CreateVariant(
+	ctx context.Context, v *product.Variant) (*product.Variant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.products[v.ProductID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	if mr.skuTaken(v.SKU, v.ID) {
+		return nil, product.ErrVariantSKUAlreadyExists
+	}
+
+	created := *v
+	created.CreatedAt = memoryNow()
+	created.UpdatedAt = created.CreatedAt
+	mr.variants[created.ID] = created
+
+	return &created, nil
+}
+
+func (mr *memoryRepository) UpdateVariant(
+	ctx context.Context, v *product.Variant) (*product.Variant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	existing, ok := mr.variants[v.ID]
+	if !ok || existing.ProductID != v.ProductID {
+		return nil, sql.ErrNoRows
+	}
+
+	if mr.skuTaken(v.SKU, v.ID) {
+		return nil, product.ErrVariantSKUAlreadyExists
+	}
+
+	updated := *v
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = memoryNow()
+	mr.variants[updated.ID] = updated
+
+	return &updated, nil
+}
+
+func (mr *memoryRepository) DeleteVariant(ctx context.Context, productID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	v, ok := mr.variants[id]
+	if !ok || v.ProductID != productID {
+		return sql.ErrNoRows
+	}
+
+	delete(mr.variants, id)
+	return nil
+}
+
+// skuTaken reports whether a variant other than id uses the sku. The caller
+// must hold the lock.
+func (mr *memoryRepository) skuTaken(sku, id string) bool {
+	for _, v := range mr.variants {
+		if v.SKU == sku && v.ID != id {
+			return true
+		}
+	}
+
+	return false
+}
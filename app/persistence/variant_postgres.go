@@ -0,0 +1,183 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+)
+
+const (
+	variantsSKUConstraint     = "product_variants_sku_key"
+	variantsProductConstraint = "product_variants_product_id_fkey"
+)
+
+const variantColumns = `id, product_id, sku, size, color, barcode, price, currency,
+	created_at, updated_at`
+
+func scanVariant(row rowScanner) (*product.Variant, error) {
+	var v product.Variant
+	var price sql.NullInt64
+	var currency sql.NullString
+	if err := row.Scan(
+		&v.ID,
+		&v.ProductID,
+		&v.SKU,
+		&v.Size,
+		&v.Color,
+		&v.Barcode,
+		&price,
+		&currency,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if price.Valid {
+		amount := money.New(price.Int64, money.Currency(currency.String))
+		v.Price = &amount
+	}
+
+	return &v, nil
+}
+
+// variantPrice is the price and currency column values of the variant.
+func variantPrice(v *product.Variant) (amount, currency interface{}) {
+	if v.Price == nil {
+		return nil, nil
+	}
+
+	return v.Price.Amount, v.Price.Currency
+}
+
+func (pr *postgresRepository) GetVariant(
+	ctx context.Context, productID, id string) (*product.Variant, error) {
+	row := pr.db.QueryRowContext(ctx,
+		`SELECT `+variantColumns+` FROM product_variants WHERE id = $1 AND product_id = $2`,
+		id, productID,
+	)
+
+	v, err := scanVariant(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			pr.logger.Errorf("could not get variant :%v", err)
+		}
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (pr *postgresRepository) GetVariantsBySKUs(
+	ctx context.Context, skus []string) ([]product.Variant, error) {
+	return pr.queryVariants(ctx,
+		`SELECT `+variantColumns+` FROM product_variants WHERE sku = ANY($1)`,
+		pq.Array(product.UniqueIDs(skus)),
+	)
+}
+
+func (pr *postgresRepository) ListVariants(
+	ctx context.Context, productID string) ([]product.Variant, error) {
+	return pr.queryVariants(ctx,
+		`SELECT `+variantColumns+` FROM product_variants
+		WHERE product_id = $1 ORDER BY created_at, id`,
+		productID,
+	)
+}
+
+func (pr *postgresRepository) queryVariants(
+	ctx context.Context, query string, args ...interface{}) ([]product.Variant, error) {
+	rows, err := pr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		pr.logger.Errorf("could not query variants :%v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []product.Variant
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			pr.logger.Errorf("could not scan variant :%v", err)
+			return nil, err
+		}
+
+		variants = append(variants, *v)
+	}
+
+	return variants, rows.Err()
+}
+
+// CreateVariant returns sql.ErrNoRows when the product does not exist.
+func (pr *postgresRepository) CreateVariant(
+	ctx context.Context, v *product.Variant) (*product.Variant, error) {
+	price, currency := variantPrice(v)
+	row := pr.db.QueryRowContext(ctx,
+		`INSERT INTO product_variants (id, product_id, sku, size, color, barcode, price, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+variantColumns,
+		v.ID, v.ProductID, v.SKU, v.Size, v.Color, v.Barcode, price, currency,
+	)
+
+	created, err := scanVariant(row)
+	switch {
+	case isUniqueViolation(err, variantsSKUConstraint):
+		return nil, product.ErrVariantSKUAlreadyExists
+	case isForeignKeyViolation(err, variantsProductConstraint):
+		return nil, sql.ErrNoRows
+	case err != nil:
+		pr.logger.Errorf("could not create variant :%v", err)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (pr *postgresRepository) UpdateVariant(
+	ctx context.Context, v *product.Variant) (*product.Variant, error) {
+	price, currency := variantPrice(v)
+	row := pr.db.QueryRowContext(ctx,
+		`UPDATE product_variants SET sku = $3, size = $4, color = $5, barcode = $6,
+		price = $7, currency = $8, updated_at = NOW()
+		WHERE id = $1 AND product_id = $2
+		RETURNING `+variantColumns,
+		v.ID, v.ProductID, v.SKU, v.Size, v.Color, v.Barcode, price, currency,
+	)
+
+	updated, err := scanVariant(row)
+	switch {
+	case isUniqueViolation(err, variantsSKUConstraint):
+		return nil, product.ErrVariantSKUAlreadyExists
+	case err != nil:
+		if !errors.Is(err, sql.ErrNoRows) {
+			pr.logger.Errorf("could not update variant :%v", err)
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (pr *postgresRepository) DeleteVariant(ctx context.Context, productID, id string) error {
+	result, err := pr.db.ExecContext(ctx,
+		`DELETE FROM product_variants WHERE id = $1 AND product_id = $2`, id, productID)
+	if err != nil {
+		pr.logger.Errorf("could not delete variant :%v", err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+package product
+
+import "context"
+
+// AvailabilityChecker sums the stock available to be sold of the products.
+// Products without stock have none available.
+type AvailabilityChecker interface {
+	Available(ctx context.Context, productIDs []string) (map[string]int, error)
+}
@@ -0,0 +1,9 @@
+package product
+
+import "context"
+
+// CategoryChecker looks up which of the given category slugs exist. The type
+// of a product is the slug of its category.
+type CategoryChecker interface {
+	ExistingCategories(ctx context.Context, slugs []string) (map[string]bool, error)
+}
@@ -0,0 +1,117 @@
+package product
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/pkg/money"
+)
+
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	// SortBySellingPrice sorts the products by their currency first, the
+	// amounts of different currencies are not comparable.
+	SortBySellingPrice SortField = "price"
+	SortByName         SortField = "name"
+)
+
+type ListSort struct {
+	Field      SortField
+	Descending bool
+}
+
+var defaultListSort = ListSort{Field: SortByCreatedAt, Descending: true}
+
+// parseListSort parses sort options like "price" or "-created_at", where the
+// leading minus means descending order.
+func parseListSort(value string) (ListSort, bool) {
+	if value == "" {
+		return defaultListSort, true
+	}
+
+	sort := ListSort{Field: SortField(strings.TrimPrefix(value, "-"))}
+	sort.Descending = strings.HasPrefix(value, "-")
+
+	switch sort.Field {
+	case SortByCreatedAt, SortBySellingPrice, SortByName:
+		return sort, true
+	}
+
+	return ListSort{}, false
+}
+
+func (s ListSort) String() string {
+	if s.Descending {
+		return "-" + string(s.Field)
+	}
+
+	return string(s.Field)
+}
+
+// Cursor points at the last product of a listed page. Value holds the sort key
+// of that product so the next page can continue right after it.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"i"`
+}
+
+func NewCursor(sort ListSort, product *Product) Cursor {
+	var value string
+	switch sort.Field {
+	case SortByCreatedAt:
+		value = product.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case SortBySellingPrice:
+		value = fmt.Sprintf("%s %d", product.Currency(), product.SellingPrice.Amount)
+	case SortByName:
+		value = product.Name
+	}
+
+	return Cursor{Sort: sort.String(), Value: value, ID: product.ID}
+}
+
+// Price returns the selling price held by a cursor of the price sort, like
+// "EUR 1250".
+func (c Cursor) Price() (money.Money, error) {
+	code, amount, ok := strings.Cut(c.Value, " ")
+	if !ok {
+		return money.Money{}, fmt.Errorf("invalid price cursor %q", c.Value)
+	}
+
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	minor, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return money.New(minor, currency), nil
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Cursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
@@ -1,9 +1,182 @@
 package product
 
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+)
+
 const (
 	ProductNotFoundErrCode           = 20001
 	OneOrMoreProductsNotFoundErrCode = 20003
 	AtLeastOneProductIDIsRequired    = 20002
 	ProductTypeIsRequired            = 20004
 	InvalidProductType               = 20005
+	DeletedProductNotFoundErrCode    = 20006
+	InvalidListLimit                 = 20007
+	InvalidPriceRange                = 20008
+	InvalidSortOption                = 20009
+	InvalidCursor                    = 20010
+	TooManyProductIDs                = 20011
+	InvalidProductRequest            = 20012
+	ProductNameIsRequired            = 20013
+	NegativeBuyingPrice              = 20014
+	NegativeSellingPrice             = 20015
+	SellingPriceBelowBuyingPrice     = 20016
+	InvalidImageURL                  = 20017
+	ProductCodeAlreadyExists         = 20018
+	ProductCodeIsRequired            = 20019
+	AtLeastOneProductIsRequired      = 20020
+	TooManyProducts                  = 20021
+	InvalidBatchCreateRequest        = 20022
+	InvalidExportFormat              = 20023
+	InvalidSearchQuery               = 20024
+	VariantNotFoundErrCode           = 20025
+	InvalidVariantRequest            = 20026
+	VariantSKUIsRequired             = 20027
+	InvalidVariantBarcode            = 20028
+	NegativeVariantPrice             = 20029
+	VariantSKUAlreadyExists          = 20030
+	InvalidCurrency                  = 20031
+	InvalidPrice                     = 20032
+	DuplicatePriceCurrency           = 20033
+	PriceNotAvailable                = 20034
+	InvalidAsOf                      = 20035
+	InvalidScheduledPriceRequest     = 20036
+	InvalidPriceWindow               = 20037
+	ScheduledPriceIsRequired         = 20038
+	PricingPolicyViolation           = 20039
+	MarginBelowMinimum               = 20040
+	PriceChangeTooLarge              = 20041
+	PriceBelowMinimum                = 20042
+	PriceAboveMaximum                = 20043
+	PricingOverrideNotAllowed        = 20044
+	ImageNotFoundErrCode             = 20045
+	ImageIsRequired                  = 20046
+	ImageTooLarge                    = 20047
+	UnsupportedImageType             = 20048
+	InvalidImage                     = 20049
+	InvalidImageOrder                = 20050
 )
+
+// ErrProductCodeAlreadyExists is returned by repositories when a product
+// would share its code with another product.
+var ErrProductCodeAlreadyExists = errors.New("product code already exists")
+
+// ProductCodeConflictError is returned by repositories creating several
+// products at once when the code of one of them is taken, it matches
+// ErrProductCodeAlreadyExists.
+type ProductCodeConflictError struct {
+	Code string
+}
+
+func (e *ProductCodeConflictError) Error() string {
+	return fmt.Sprintf("product code %q already exists", e.Code)
+}
+
+func (e *ProductCodeConflictError) Is(target error) bool {
+	return target == ErrProductCodeAlreadyExists
+}
+
+// ErrProductTypeNotFound is returned by repositories when the type of a
+// product is not the slug of a category.
+var ErrProductTypeNotFound = errors.New("product type not found")
+
+// ErrVariantSKUAlreadyExists is returned by repositories when a variant would
+// share its SKU with another variant.
+var ErrVariantSKUAlreadyExists = errors.New("variant sku already exists")
+
+func productCodeAlreadyExists() cerr.Bag {
+	return cerr.Bag{Code: ProductCodeAlreadyExists,
+		Message: "Another product with the same code already exists."}
+}
+
+// productTypeNotFound reports a type whose category is deleted after the
+// request is validated, just like the validation does.
+func productTypeNotFound() cerr.Bag {
+	return cerr.Bag{Code: InvalidProductRequest, Message: "Product request is invalid.",
+		Errors: []cerr.FieldError{{
+			Field: "type", Code: InvalidProductType, Message: "Invalid product type."}}}
+}
+
+func variantNotFound() cerr.Bag {
+	return cerr.Bag{Code: VariantNotFoundErrCode, Message: "Variant not found."}
+}
+
+func variantSKUAlreadyExists() cerr.Bag {
+	return cerr.Bag{Code: VariantSKUAlreadyExists,
+		Message: "Another variant with the same sku already exists."}
+}
+
+func imageNotFound() cerr.Bag {
+	return cerr.Bag{Code: ImageNotFoundErrCode, Message: "Image not found."}
+}
+
+func imageIsRequired() cerr.Bag {
+	return cerr.Bag{Code: ImageIsRequired,
+		Message: "Image file must be uploaded as the image field of a multipart form."}
+}
+
+func imageTooLarge(maxSize int64) cerr.Bag {
+	return cerr.Bag{Code: ImageTooLarge,
+		Message: fmt.Sprintf("Image file can be at most %d bytes.", maxSize)}
+}
+
+func invalidImageOrder() cerr.Bag {
+	return cerr.Bag{Code: InvalidImageOrder,
+		Message: "Image ids must list every image of the product once."}
+}
+
+func invalidCurrency() cerr.Bag {
+	return cerr.Bag{Code: InvalidCurrency, Message: "Currency must be an ISO 4217 currency code."}
+}
+
+func init() {
+	cerr.RegisterStatus(http.StatusNotFound,
+		ProductNotFoundErrCode,
+		OneOrMoreProductsNotFoundErrCode,
+		DeletedProductNotFoundErrCode,
+		VariantNotFoundErrCode,
+		ImageNotFoundErrCode,
+	)
+	cerr.RegisterStatus(http.StatusUnprocessableEntity,
+		AtLeastOneProductIDIsRequired,
+		ProductTypeIsRequired,
+		InvalidProductType,
+		InvalidListLimit,
+		InvalidPriceRange,
+		InvalidSortOption,
+		InvalidCursor,
+		TooManyProductIDs,
+		InvalidProductRequest,
+		AtLeastOneProductIsRequired,
+		TooManyProducts,
+		InvalidBatchCreateRequest,
+		InvalidExportFormat,
+		InvalidSearchQuery,
+		InvalidVariantRequest,
+		InvalidCurrency,
+		PriceNotAvailable,
+		InvalidAsOf,
+		InvalidScheduledPriceRequest,
+		PricingPolicyViolation,
+		ImageIsRequired,
+		InvalidImage,
+		InvalidImageOrder,
+	)
+	cerr.RegisterStatus(http.StatusRequestEntityTooLarge,
+		ImageTooLarge,
+	)
+	cerr.RegisterStatus(http.StatusUnsupportedMediaType,
+		UnsupportedImageType,
+	)
+	cerr.RegisterStatus(http.StatusForbidden,
+		PricingOverrideNotAllowed,
+	)
+	cerr.RegisterStatus(http.StatusConflict,
+		ProductCodeAlreadyExists,
+		VariantSKUAlreadyExists,
+	)
+}
@@ -0,0 +1,129 @@
+package product
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// exportWriter encodes the exported products one at a time, so an export
+// never holds more than a single product.
+type exportWriter interface {
+	Write(product *Product) error
+	Flush() error
+}
+
+func newExportWriter(w io.Writer, format ExportFormat, includeInternal bool) exportWriter {
+	if format == ExportFormatCSV {
+		return newCSVExportWriter(w, includeInternal)
+	}
+
+	return &ndjsonExportWriter{encoder: json.NewEncoder(w), includeInternal: includeInternal}
+}
+
+type ndjsonExportWriter struct {
+	encoder         *json.Encoder
+	includeInternal bool
+}
+
+func (n *ndjsonExportWriter) Write(product *Product) error {
+	if n.includeInternal {
+		return n.encoder.Encode(newExportProductRecord(product))
+	}
+
+	// exports carry the regular selling prices, neither the scheduled prices
+	// nor the promotions of the streamed products are looked up.
+	return n.encoder.Encode(NewGetProductResponse(product, time.Now()))
+}
+
+func (n *ndjsonExportWriter) Flush() error {
+	return nil
+}
+
+// exportProductRecord is an exported product along with its internal fields.
+type exportProductRecord struct {
+	CreateProductResponse
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func newExportProductRecord(product *Product) *exportProductRecord {
+	return &exportProductRecord{
+		CreateProductResponse: *NewCreateProductResponse(product),
+		DeletedAt:             product.DeletedAt,
+	}
+}
+
+var (
+	csvExportHeader = []string{
+		"id", "name", "code", "color", "type", "price", "currency", "image_url",
+		"created_at", "updated_at", "deleted_at",
+	}
+	csvInternalExportHeader = []string{
+		"id", "name", "code", "color", "type", "buying_price", "selling_price", "currency",
+		"image_url", "provider", "creator", "distributor", "created_at", "updated_at", "deleted_at",
+	}
+)
+
+type csvExportWriter struct {
+	writer          *csv.Writer
+	includeInternal bool
+	headerWritten   bool
+}
+
+func newCSVExportWriter(w io.Writer, includeInternal bool) *csvExportWriter {
+	return &csvExportWriter{writer: csv.NewWriter(w), includeInternal: includeInternal}
+}
+
+func (c *csvExportWriter) Write(product *Product) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	var deletedAt string
+	if product.DeletedAt != nil {
+		deletedAt = formatExportTime(*product.DeletedAt)
+	}
+
+	if !c.includeInternal {
+		return c.writer.Write([]string{
+			product.ID, product.Name, product.Code, product.Color, string(product.Type),
+			product.SellingPrice.Decimal(), string(product.Currency()), product.ImageURL,
+			formatExportTime(product.CreatedAt), formatExportTime(product.UpdatedAt), deletedAt,
+		})
+	}
+
+	return c.writer.Write([]string{
+		product.ID, product.Name, product.Code, product.Color, string(product.Type),
+		product.BuyingPrice.Decimal(), product.SellingPrice.Decimal(),
+		string(product.Currency()), product.ImageURL, product.Provider, product.Creator, product.Distributor,
+		formatExportTime(product.CreatedAt), formatExportTime(product.UpdatedAt), deletedAt,
+	})
+}
+
+// Flush writes the header even when no product is exported.
+func (c *csvExportWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvExportWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+
+	c.headerWritten = true
+	if c.includeInternal {
+		return c.writer.Write(csvInternalExportHeader)
+	}
+
+	return c.writer.Write(csvExportHeader)
+}
+
+func formatExportTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
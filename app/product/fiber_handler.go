@@ -1,7 +1,16 @@
 package product
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/pkg/auth"
 	"github.com/pact-cdc-example/product-service/pkg/cerr"
 	"github.com/sirupsen/logrus"
 )
@@ -9,24 +18,92 @@ import (
 type Handler interface {
 	SetupRoutes(fr fiber.Router)
 	GetProductByID(c *fiber.Ctx) error
+	GetProductByCode(c *fiber.Ctx) error
 	GetProductsByIDs(c *fiber.Ctx) error
+	ListProducts(c *fiber.Ctx) error
+	SearchProducts(c *fiber.Ctx) error
+	ExportProducts(c *fiber.Ctx) error
 	CreateProduct(c *fiber.Ctx) error
+	CreateProducts(c *fiber.Ctx) error
+	UpdateProduct(c *fiber.Ctx) error
+	PatchProduct(c *fiber.Ctx) error
+	DeleteProduct(c *fiber.Ctx) error
+	RestoreProduct(c *fiber.Ctx) error
+	ListVariants(c *fiber.Ctx) error
+	GetVariant(c *fiber.Ctx) error
+	CreateVariant(c *fiber.Ctx) error
+	UpdateVariant(c *fiber.Ctx) error
+	DeleteVariant(c *fiber.Ctx) error
+	ListScheduledPrices(c *fiber.Ctx) error
+	SchedulePrice(c *fiber.Ctx) error
+	ListImages(c *fiber.Ctx) error
+	UploadImage(c *fiber.Ctx) error
+	ReorderImages(c *fiber.Ctx) error
+	DeleteImage(c *fiber.Ctx) error
 }
 
 type handler struct {
-	logger  *logrus.Logger
-	service Service
+	logger         *logrus.Logger
+	service        Service
+	maxBulkIDs     int
+	maxBatchCreate int
+	maxImageSize   int64
+	exportTimeout  time.Duration
+	idempotency    fiber.Handler
 }
 
 type NewHandlerOpts struct {
 	L *logrus.Logger
 	S Service
+	// MaxBulkIDs limits the ids of a bulk lookup, DefaultMaxBulkIDs when zero.
+	MaxBulkIDs int
+	// MaxBatchCreate limits the products of a batch create,
+	// DefaultMaxBatchCreate when zero.
+	MaxBatchCreate int
+	// MaxImageSize limits the size of the uploaded image files in bytes,
+	// DefaultMaxImageSize when zero.
+	MaxImageSize int64
+	// ExportTimeout bounds the exports, DefaultExportTimeout when zero.
+	ExportTimeout time.Duration
+	// Idempotency is the middleware guarding product creation against
+	// retries, optional.
+	Idempotency fiber.Handler
 }
 
 func NewHandler(opts *NewHandlerOpts) Handler {
+	maxBulkIDs := opts.MaxBulkIDs
+	if maxBulkIDs == 0 {
+		maxBulkIDs = DefaultMaxBulkIDs
+	}
+
+	maxBatchCreate := opts.MaxBatchCreate
+	if maxBatchCreate == 0 {
+		maxBatchCreate = DefaultMaxBatchCreate
+	}
+
+	maxImageSize := opts.MaxImageSize
+	if maxImageSize == 0 {
+		maxImageSize = DefaultMaxImageSize
+	}
+
+	exportTimeout := opts.ExportTimeout
+	if exportTimeout == 0 {
+		exportTimeout = DefaultExportTimeout
+	}
+
+	idempotency := opts.Idempotency
+	if idempotency == nil {
+		idempotency = func(c *fiber.Ctx) error { return c.Next() }
+	}
+
 	return &handler{
-		logger:  opts.L,
-		service: opts.S,
+		logger:         opts.L,
+		service:        opts.S,
+		maxBulkIDs:     maxBulkIDs,
+		maxBatchCreate: maxBatchCreate,
+		maxImageSize:   maxImageSize,
+		exportTimeout:  exportTimeout,
+		idempotency:    idempotency,
 	}
 }
 
@@ -35,49 +112,90 @@ func (h *handler) CreateProduct(c *fiber.Ctx) error {
 
 	var req CreateProductRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(cerr.BodyParser())
-	}
-
-	if err := req.Validate(); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(err)
+		return cerr.BodyParser()
 	}
 
 	product, err := h.service.CreateProduct(c.Context(), req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
 	}
 
 	return c.JSON(product)
 }
 
+func (h *handler) CreateProducts(c *fiber.Ctx) error {
+	h.logger.Infof("Create Products request arrived!")
+
+	req := CreateProductsRequest{Atomic: c.QueryBool("atomic", true)}
+	if err := c.BodyParser(&req.Products); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(h.maxBatchCreate); err != nil {
+		return err
+	}
+
+	response, err := h.service.CreateProducts(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
 func (h *handler) GetProductByID(c *fiber.Ctx) error {
 	productID := c.Params("id")
 	h.logger.Infof("Get Product By ID request arrived! Product ID: %s", productID)
 
-	product, err := h.service.GetProductByID(c.Context(), productID)
+	req := GetProductRequest{
+		ID:             productID,
+		IncludeDeleted: includeDeleted(c),
+		Currency:       c.Query("currency"),
+		AsOf:           c.Query("as_of"),
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	product, err := h.service.GetProductByID(c.Context(), req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(err)
+		return contractStatus(err)
 	}
 
 	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
 	return c.JSON(product)
 }
 
+func (h *handler) GetProductByCode(c *fiber.Ctx) error {
+	code := c.Params("code")
+	h.logger.Infof("Get Product By Code request arrived! Product Code: %s", code)
+
+	product, err := h.service.GetProductByCode(c.Context(), code)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(product)
+}
+
 func (h *handler) GetProductsByIDs(c *fiber.Ctx) error {
 	h.logger.Infof("Get Product By IDs request arrived!")
 
 	var req GetProductsByIDsRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(cerr.BodyParser())
+		return cerr.BodyParser()
 	}
 
-	if err := req.Validate(); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(err)
+	if err := req.Validate(h.maxBulkIDs); err != nil {
+		return err
 	}
 
+	req.IncludeDeleted = includeDeleted(c)
+	req.Partial = c.QueryBool("partial")
+
 	products, err := h.service.GetProductsByIDs(c.Context(), req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(err)
+		return contractStatus(err)
 	}
 
 	err = c.JSON(products)
@@ -85,10 +203,405 @@ func (h *handler) GetProductsByIDs(c *fiber.Ctx) error {
 	return err
 }
 
+func (h *handler) ListProducts(c *fiber.Ctx) error {
+	h.logger.Infof("List Products request arrived!")
+
+	var req ListProductsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	req.IncludeDeleted = includeDeleted(c)
+
+	products, err := h.service.ListProducts(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(products)
+}
+
+func (h *handler) SearchProducts(c *fiber.Ctx) error {
+	h.logger.Infof("Search Products request arrived!")
+
+	var req SearchProductsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	req.IncludeDeleted = includeDeleted(c)
+
+	products, err := h.service.SearchProducts(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(products)
+}
+
+// ExportProducts streams the products matching the listing filters. Once the
+// stream has started the status can not change anymore, so export failures
+// are logged here and truncate the body.
+func (h *handler) ExportProducts(c *fiber.Ctx) error {
+	h.logger.Infof("Export Products request arrived!")
+
+	req := ExportProductsRequest{
+		Format:          ExportFormat(c.Query("format", string(ExportFormatNDJSON))),
+		IncludeInternal: auth.IsAdmin(c),
+	}
+	if err := c.QueryParser(&req.Filter); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	req.Filter.IncludeDeleted = includeDeleted(c)
+
+	// the filter is decoded before the body is streamed, an invalid cursor
+	// can not be reported once the response is started.
+	filter, err := req.Filter.Filter()
+	if err != nil {
+		return err
+	}
+
+	contentType := "application/x-ndjson"
+	if req.Format == ExportFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="products.%s"`, req.Format))
+
+	// the request context is recycled once the handler returns, before the
+	// body is streamed.
+	logger := h.logger.WithFields(logrus.Fields{
+		"request_id": c.Context().ID(),
+		"format":     req.Format,
+	})
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), h.exportTimeout)
+		defer cancel()
+
+		err := h.service.ExportProducts(ctx, req, filter, &exportStream{w: w, cancel: cancel})
+		if err != nil {
+			logger.Errorf("could not export products: %v", err)
+		}
+	})
+
+	return nil
+}
+
+// exportStream cancels the export as soon as writing to the client fails,
+// so that a client going away releases the export cursor right away.
+type exportStream struct {
+	w      io.Writer
+	cancel context.CancelFunc
+}
+
+func (e *exportStream) Write(p []byte) (int, error) {
+	n, err := e.w.Write(p)
+	if err != nil {
+		e.cancel()
+	}
+
+	return n, err
+}
+
+func (h *handler) UpdateProduct(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("Update Product request arrived! Product ID: %s", productID)
+
+	var req UpdateProductRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	product, err := h.service.UpdateProduct(c.Context(), productID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(product)
+}
+
+func (h *handler) PatchProduct(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("Patch Product request arrived! Product ID: %s", productID)
+
+	if !json.Valid(c.Body()) {
+		return cerr.BodyParser()
+	}
+
+	product, err := h.service.PatchProduct(c.Context(), productID, c.Body())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(product)
+}
+
+func (h *handler) DeleteProduct(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("Delete Product request arrived! Product ID: %s", productID)
+
+	if err := h.service.DeleteProduct(c.Context(), productID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *handler) RestoreProduct(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("Restore Product request arrived! Product ID: %s", productID)
+
+	product, err := h.service.RestoreProduct(c.Context(), productID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(product)
+}
+
+func (h *handler) ListVariants(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("List Variants request arrived! Product ID: %s", productID)
+
+	variants, err := h.service.ListVariants(c.Context(), productID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(variants)
+}
+
+func (h *handler) GetVariant(c *fiber.Ctx) error {
+	productID, variantID := c.Params("id"), c.Params("variantID")
+	h.logger.Infof("Get Variant request arrived! Product ID: %s, Variant ID: %s",
+		productID, variantID)
+
+	variant, err := h.service.GetVariant(c.Context(), productID, variantID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(variant)
+}
+
+func (h *handler) CreateVariant(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("Create Variant request arrived! Product ID: %s", productID)
+
+	var req CreateVariantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	variant, err := h.service.CreateVariant(c.Context(), productID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(variant)
+}
+
+func (h *handler) UpdateVariant(c *fiber.Ctx) error {
+	productID, variantID := c.Params("id"), c.Params("variantID")
+	h.logger.Infof("Update Variant request arrived! Product ID: %s, Variant ID: %s",
+		productID, variantID)
+
+	var req UpdateVariantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	variant, err := h.service.UpdateVariant(c.Context(), productID, variantID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(variant)
+}
+
+func (h *handler) DeleteVariant(c *fiber.Ctx) error {
+	productID, variantID := c.Params("id"), c.Params("variantID")
+	h.logger.Infof("Delete Variant request arrived! Product ID: %s, Variant ID: %s",
+		productID, variantID)
+
+	if err := h.service.DeleteVariant(c.Context(), productID, variantID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *handler) ListScheduledPrices(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("List Scheduled Prices request arrived! Product ID: %s", productID)
+
+	prices, err := h.service.ListScheduledPrices(c.Context(), productID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(prices)
+}
+
+func (h *handler) SchedulePrice(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("Schedule Price request arrived! Product ID: %s", productID)
+
+	var req SchedulePriceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	price, err := h.service.SchedulePrice(c.Context(), productID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(price)
+}
+
+func (h *handler) ListImages(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("List Images request arrived! Product ID: %s", productID)
+
+	images, err := h.service.ListImages(c.Context(), productID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(images)
+}
+
+// UploadImage accepts the image file as the image field of a multipart form.
+// Files over the size limit are rejected before they are read.
+func (h *handler) UploadImage(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("Upload Image request arrived! Product ID: %s", productID)
+
+	header, err := c.FormFile("image")
+	if err != nil {
+		return imageIsRequired()
+	}
+
+	if header.Size > h.maxImageSize {
+		return imageTooLarge(h.maxImageSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return cerr.BodyParser()
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, h.maxImageSize+1))
+	if err != nil {
+		return cerr.BodyParser()
+	}
+
+	req := UploadImageRequest{Content: content}
+	if err = req.Validate(h.maxImageSize); err != nil {
+		return err
+	}
+
+	image, err := h.service.UploadImage(c.Context(), productID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(image)
+}
+
+func (h *handler) ReorderImages(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	h.logger.Infof("Reorder Images request arrived! Product ID: %s", productID)
+
+	var req ReorderImagesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	images, err := h.service.ReorderImages(c.Context(), productID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(images)
+}
+
+func (h *handler) DeleteImage(c *fiber.Ctx) error {
+	productID, imageID := c.Params("id"), c.Params("imageID")
+	h.logger.Infof("Delete Image request arrived! Product ID: %s, Image ID: %s",
+		productID, imageID)
+
+	if err := h.service.DeleteImage(c.Context(), productID, imageID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// includeDeleted reports whether soft deleted products were asked for. It is
+// an admin only option and silently ignored for other callers.
+func includeDeleted(c *fiber.Ctx) bool {
+	return auth.IsAdmin(c) && c.QueryBool("include_deleted")
+}
+
+// contractStatus keeps the not found errors of the product lookups on 400 Bad
+// Request. The published StockService and BasketService pacts expect it from
+// GET /products/:id and POST /products/bulk, the other routes respond 404.
+func contractStatus(err error) error {
+	var bag cerr.Bag
+	if errors.As(err, &bag) &&
+		(bag.Code == ProductNotFoundErrCode || bag.Code == OneOrMoreProductsNotFoundErrCode) {
+		return bag.WithStatus(fiber.StatusBadRequest)
+	}
+
+	return err
+}
+
 func (h *handler) SetupRoutes(fr fiber.Router) {
 	productsGroup := fr.Group("/products")
 
+	productsGroup.Get("/", h.ListProducts)
+	productsGroup.Get("/search", h.SearchProducts)
+	productsGroup.Get("/export", h.ExportProducts)
 	productsGroup.Post("/bulk", h.GetProductsByIDs)
+	productsGroup.Post("/batch-create", h.CreateProducts)
+	productsGroup.Get("/by-code/:code", h.GetProductByCode)
 	productsGroup.Get("/:id", h.GetProductByID)
-	productsGroup.Post("/", h.CreateProduct)
+	productsGroup.Post("/", h.idempotency, h.CreateProduct)
+	productsGroup.Put("/:id", h.UpdateProduct)
+	productsGroup.Patch("/:id", h.PatchProduct)
+	productsGroup.Delete("/:id", auth.RequireAdmin, h.DeleteProduct)
+	productsGroup.Post("/:id/restore", auth.RequireAdmin, h.RestoreProduct)
+	productsGroup.Get("/:id/variants", h.ListVariants)
+	productsGroup.Post("/:id/variants", h.CreateVariant)
+	productsGroup.Get("/:id/variants/:variantID", h.GetVariant)
+	productsGroup.Put("/:id/variants/:variantID", h.UpdateVariant)
+	productsGroup.Delete("/:id/variants/:variantID", h.DeleteVariant)
+	productsGroup.Get("/:id/prices", h.ListScheduledPrices)
+	productsGroup.Post("/:id/prices", h.SchedulePrice)
+	productsGroup.Get("/:id/images", h.ListImages)
+	productsGroup.Post("/:id/images", h.UploadImage)
+	productsGroup.Put("/:id/images/order", h.ReorderImages)
+	productsGroup.Delete("/:id/images/:imageID", h.DeleteImage)
 }
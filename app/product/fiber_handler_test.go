@@ -0,0 +1,194 @@
+package product_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/auth"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/server"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNotFoundStatus pins the statuses of the published consumer pacts, the
+// product lookups respond their not found errors with 400 Bad Request.
+func TestNotFoundStatus(t *testing.T) {
+	s, _ := newService(t)
+	app := newApp(&product.NewHandlerOpts{S: s})
+
+	cases := []struct {
+		method   string
+		target   string
+		body     string
+		expected int
+		code     cerr.Code
+	}{
+		{http.MethodGet, "/api/v1/products/missing", "",
+			http.StatusBadRequest, product.ProductNotFoundErrCode},
+		{http.MethodPost, "/api/v1/products/bulk", `{"ids":["missing"]}`,
+			http.StatusBadRequest, product.OneOrMoreProductsNotFoundErrCode},
+		{http.MethodPut, "/api/v1/products/missing", `{"name":"Runner"}`,
+			http.StatusNotFound, product.ProductNotFoundErrCode},
+		{http.MethodDelete, "/api/v1/products/missing", "",
+			http.StatusNotFound, product.ProductNotFoundErrCode},
+		{http.MethodGet, "/api/v1/products/missing/variants", "",
+			http.StatusNotFound, product.ProductNotFoundErrCode},
+	}
+
+	for _, c := range cases {
+		t.Run(c.method+" "+c.target, func(t *testing.T) {
+			resp := doRequestWithKey(t, app, adminKey, c.method, c.target, c.body)
+			assert.Equal(t, c.expected, resp.StatusCode)
+			assert.Equal(t, c.code, decodeBag(t, resp).Code)
+		})
+	}
+}
+
+func TestDeleteAndRestoreRequireAdmin(t *testing.T) {
+	s, _ := newService(t)
+	id := createProduct(t, s)
+	app := newApp(&product.NewHandlerOpts{S: s})
+
+	cases := []struct {
+		apiKey   string
+		expected int
+		code     cerr.Code
+	}{
+		{"", http.StatusUnauthorized, cerr.UnauthorizedErrCode},
+		{viewerKey, http.StatusForbidden, cerr.ForbiddenErrCode},
+	}
+
+	routes := []struct {
+		method string
+		target string
+	}{
+		{http.MethodDelete, "/api/v1/products/" + id},
+		{http.MethodPost, "/api/v1/products/" + id + "/restore"},
+	}
+
+	for _, c := range cases {
+		for _, r := range routes {
+			resp := doRequestWithKey(t, app, c.apiKey, r.method, r.target, "")
+			assert.Equal(t, c.expected, resp.StatusCode, "%s %s", r.method, r.target)
+			assert.Equal(t, c.code, decodeBag(t, resp).Code)
+		}
+	}
+
+	resp := doRequestWithKey(t, app, adminKey, routes[0].method, routes[0].target, "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = doRequestWithKey(t, app, adminKey, routes[1].method, routes[1].target, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestExportProductsRejectsInvalidCursor(t *testing.T) {
+	s, _ := newService(t)
+	createProduct(t, s)
+	app := newApp(&product.NewHandlerOpts{S: s})
+
+	resp := doRequest(t, app, http.MethodGet, "/api/v1/products/export?cursor=garbage", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Equal(t, cerr.Code(product.InvalidCursor), decodeBag(t, resp).Code)
+
+	resp = doRequest(t, app, http.MethodGet, "/api/v1/products/export", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"code":"RUN-1"`)
+}
+
+func TestExportProductsTimeout(t *testing.T) {
+	s, _ := newService(t)
+	createProduct(t, s)
+	logger, hook := test.NewNullLogger()
+	app := newApp(&product.NewHandlerOpts{S: s, L: logger, ExportTimeout: time.Nanosecond})
+
+	resp := doRequest(t, app, http.MethodGet, "/api/v1/products/export?format=csv", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "RUN-1", "the export is cut off by its timeout")
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Contains(t, entry.Message, context.DeadlineExceeded.Error())
+	assert.Equal(t, product.ExportFormatCSV, entry.Data["format"])
+	assert.Contains(t, entry.Data, "request_id")
+}
+
+// newApp serves the product routes with the error handler of the server,
+// logging to a null logger unless given one.
+func newApp(opts *product.NewHandlerOpts) *fiber.App {
+	if opts.L == nil {
+		opts.L, _ = test.NewNullLogger()
+	}
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: server.NewErrorHandler(&server.NewServerOpts{L: opts.L}),
+	})
+	app.Use(auth.New(&auth.NewMiddlewareOpts{Keys: map[string]auth.Principal{
+		adminKey:  {Name: "root", Role: auth.RoleAdmin},
+		viewerKey: {Name: "viewer", Role: "viewer"},
+	}}))
+	product.NewHandler(opts).SetupRoutes(app.Group("/api/v1"))
+
+	return app
+}
+
+// the api keys accepted by the apps of newApp.
+const (
+	adminKey  = "admin-key"
+	viewerKey = "viewer-key"
+)
+
+func doRequest(t *testing.T, app *fiber.App, method, target, body string) *http.Response {
+	t.Helper()
+
+	return doRequestWithKey(t, app, "", method, target, body)
+}
+
+// doRequestWithKey sends the request with the api key, anonymously when it is
+// empty.
+func doRequestWithKey(t *testing.T, app *fiber.App,
+	apiKey, method, target, body string) *http.Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if apiKey != "" {
+		req.Header.Set(auth.HeaderAPIKey, apiKey)
+	}
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func decodeBag(t *testing.T, resp *http.Response) cerr.Bag {
+	t.Helper()
+
+	var bag cerr.Bag
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&bag))
+
+	return bag
+}
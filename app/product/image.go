@@ -0,0 +1,172 @@
+package product
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/pkg/thumbnail"
+)
+
+// ImageStorage keeps the files of the product images, see storage.Storage.
+type ImageStorage interface {
+	Put(ctx context.Context, key string, content io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// Image is an image of a product. The images of a product are shown in the
+// order of their Position, the first one being its main image. Key is the
+// storage key of the original file, the thumbnails are stored next to it.
+type Image struct {
+	ID          string
+	ProductID   string
+	Position    int
+	Key         string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	// ThumbnailSizes are the sizes of the square boxes the thumbnails of
+	// the image were scaled down to fit in.
+	ThumbnailSizes []int
+	CreatedAt      time.Time
+}
+
+// imageExtensions maps the accepted image content types to the extensions
+// of their files.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// imageContentType returns the content type of the image file sniffed from
+// its content, clients are not trusted with it.
+func imageContentType(content []byte) (string, bool) {
+	contentType := http.DetectContentType(content)
+	_, ok := imageExtensions[contentType]
+	return contentType, ok
+}
+
+func imageKey(productID, id, contentType string) string {
+	return fmt.Sprintf("products/%s/images/%s/original%s",
+		productID, id, imageExtensions[contentType])
+}
+
+// ThumbnailContentType is the content type the thumbnails of the image are
+// encoded in, PNG keeps the transparency of PNG and GIF images.
+func (i *Image) ThumbnailContentType() string {
+	if i.ContentType == "image/jpeg" {
+		return "image/jpeg"
+	}
+
+	return "image/png"
+}
+
+// ThumbnailKey returns the storage key of the thumbnail of the given size.
+func (i *Image) ThumbnailKey(size int) string {
+	return fmt.Sprintf("%s/%d%s",
+		path.Dir(i.Key), size, imageExtensions[i.ThumbnailContentType()])
+}
+
+// Keys returns the storage keys of the original and the thumbnails.
+func (i *Image) Keys() []string {
+	keys := []string{i.Key}
+	for _, size := range i.ThumbnailSizes {
+		keys = append(keys, i.ThumbnailKey(size))
+	}
+
+	return keys
+}
+
+// imageFile is a file of an image to be stored, the original or a thumbnail.
+type imageFile struct {
+	key         string
+	contentType string
+	content     []byte
+}
+
+// maxImagePixels bounds the images decoded, a small file can declare a huge
+// image that would take up gigabytes once decoded.
+const maxImagePixels = 50_000_000
+
+// newImage decodes the uploaded content and encodes its thumbnails in the
+// given sizes. It fails when the content is not a valid image of its sniffed
+// content type.
+func newImage(id, productID, contentType string,
+	content []byte, thumbnailSizes []int) (*Image, []imageFile, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if "image/"+format != contentType {
+		return nil, nil, fmt.Errorf("%s content is decoded as %s", contentType, format)
+	}
+
+	if config.Width*config.Height > maxImagePixels {
+		return nil, nil, fmt.Errorf("image of %dx%d pixels is too large",
+			config.Width, config.Height)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	img := &Image{
+		ID:          id,
+		ProductID:   productID,
+		Key:         imageKey(productID, id, contentType),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Width:       decoded.Bounds().Dx(),
+		Height:      decoded.Bounds().Dy(),
+	}
+	files := []imageFile{{key: img.Key, contentType: contentType, content: content}}
+
+	// every thumbnail is scaled down from the next larger one, which is
+	// much cheaper than scaling down the original again.
+	sizes := append([]int(nil), thumbnailSizes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	for i, size := range sizes {
+		if i > 0 && size == sizes[i-1] {
+			continue
+		}
+
+		decoded = thumbnail.Fit(decoded, size)
+
+		var buf bytes.Buffer
+		if err = encodeThumbnail(&buf, decoded, img.ThumbnailContentType()); err != nil {
+			return nil, nil, err
+		}
+
+		img.ThumbnailSizes = append(img.ThumbnailSizes, size)
+		files = append(files, imageFile{
+			key:         img.ThumbnailKey(size),
+			contentType: img.ThumbnailContentType(),
+			content:     buf.Bytes(),
+		})
+	}
+	sort.Ints(img.ThumbnailSizes)
+
+	return img, files, nil
+}
+
+func encodeThumbnail(w io.Writer, img image.Image, contentType string) error {
+	if contentType == "image/jpeg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	}
+
+	return png.Encode(w, img)
+}
@@ -0,0 +1,186 @@
+package product_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/app/persistence"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadImage(t *testing.T) {
+	s, dir := newImageService(t, persistence.NewMemoryRepository())
+	id := createProduct(t, s)
+	app := newApp(&product.NewHandlerOpts{S: s})
+
+	// the content type the client sends is not trusted, the file is a PNG.
+	resp := uploadImage(t, app, id, "image/jpeg", newPNG(t, 64, 32))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var image product.ImageResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&image))
+	assert.Equal(t, "image/png", image.ContentType)
+	assert.Equal(t, 64, image.Width)
+	assert.Equal(t, 32, image.Height)
+	require.Len(t, image.Thumbnails, 1)
+	assert.Equal(t, 16, image.Thumbnails[0].Size)
+	assert.Len(t, storedFiles(t, dir), 2, "the original and its thumbnail are stored")
+}
+
+func TestUploadImageRejectsInvalidFiles(t *testing.T) {
+	s, dir := newImageService(t, persistence.NewMemoryRepository())
+	id := createProduct(t, s)
+	app := newApp(&product.NewHandlerOpts{S: s, MaxImageSize: 1 << 10})
+
+	cases := []struct {
+		name     string
+		content  []byte
+		expected int
+		code     cerr.Code
+	}{
+		{"too large", append(newPNG(t, 1, 1), make([]byte, 1<<10)...),
+			http.StatusRequestEntityTooLarge, product.ImageTooLarge},
+		{"unsupported type", []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>"),
+			http.StatusUnsupportedMediaType, product.UnsupportedImageType},
+		{"decode bomb", withDeclaredSize(newPNG(t, 1, 1), 20_000, 20_000),
+			http.StatusUnprocessableEntity, product.InvalidImage},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp := uploadImage(t, app, id, "image/png", c.content)
+			assert.Equal(t, c.expected, resp.StatusCode)
+			assert.Equal(t, c.code, decodeBag(t, resp).Code)
+		})
+	}
+
+	assert.Empty(t, storedFiles(t, dir))
+}
+
+func TestUploadImageDeletesFilesWhenNotCreated(t *testing.T) {
+	repository := &failingImageRepository{persistence.NewMemoryRepository()}
+	s, dir := newImageService(t, repository)
+	id := createProduct(t, s)
+
+	_, err := s.UploadImage(context.Background(), id,
+		product.UploadImageRequest{Content: newPNG(t, 64, 32)})
+	assertBagCode(t, cerr.ProcessingErrCode, err)
+	assert.Empty(t, storedFiles(t, dir))
+}
+
+// failingImageRepository fails to create images, like a database going away
+// after the image files are stored.
+type failingImageRepository struct {
+	product.Repository
+}
+
+func (r *failingImageRepository) CreateImage(
+	context.Context, *product.Image) (*product.Image, error) {
+	return nil, errors.New("connection refused")
+}
+
+// newImageService returns a product service like newService storing the
+// image files in a temporary directory, along with the directory.
+func newImageService(t *testing.T, repository product.Repository) (product.Service, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	images, err := storage.NewLocal(&storage.NewLocalOpts{
+		Dir:     dir,
+		Route:   "/files",
+		BaseURL: "http://localhost/api/v1/files",
+	})
+	require.NoError(t, err)
+
+	logger, _ := test.NewNullLogger()
+	return product.NewService(&product.NewServiceOpts{
+		L:              logger,
+		R:              repository,
+		C:              persistence.NewMemoryCategoryRepository(repository),
+		I:              images,
+		ThumbnailSizes: []int{16},
+	}), dir
+}
+
+func uploadImage(t *testing.T, app *fiber.App,
+	productID, contentType string, content []byte) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="image"; filename="image.jpg"`)
+	header.Set(fiber.HeaderContentType, contentType)
+	part, err := form.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products/"+productID+"/images", &body)
+	req.Header.Set(fiber.HeaderContentType, form.FormDataContentType())
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func newPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	return buf.Bytes()
+}
+
+// withDeclaredSize rewrites the size the PNG declares in its header, which is
+// all it takes to claim a huge image in a small file.
+func withDeclaredSize(content []byte, width, height int) []byte {
+	// the IHDR chunk follows the 8 bytes signature: its length, type, the
+	// width and height, the rest of its data and its checksum.
+	binary.BigEndian.PutUint32(content[16:], uint32(width))
+	binary.BigEndian.PutUint32(content[20:], uint32(height))
+	binary.BigEndian.PutUint32(content[29:], crc32.ChecksumIEEE(content[12:29]))
+
+	return content
+}
+
+// storedFiles returns the files in the directory of the image storage.
+func storedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	var files []string
+	require.NoError(t, filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	}))
+
+	return files
+}
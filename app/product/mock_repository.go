@@ -7,6 +7,7 @@ package product
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,47 +35,402 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateImage mocks base method.
+func (m *MockRepository) CreateImage(ctx context.Context, image *Image) (*Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImage", ctx, image)
+	ret0, _ := ret[0].(*Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateImage indicates an expected call of CreateImage.
+func (mr *MockRepositoryMockRecorder) CreateImage(ctx, image interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImage", reflect.TypeOf((*MockRepository)(nil).CreateImage), ctx, image)
+}
+
 // CreateProduct mocks base method.
-func (m *MockRepository) CreateProduct(ctx context.Context, product *Product) (*Product, error) {
+func (m *MockRepository) CreateProduct(ctx context.Context, product *Product, changedBy string) (*Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateProduct", ctx, product)
+	ret := m.ctrl.Call(m, "CreateProduct", ctx, product, changedBy)
 	ret0, _ := ret[0].(*Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateProduct indicates an expected call of CreateProduct.
-func (mr *MockRepositoryMockRecorder) CreateProduct(ctx, product interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) CreateProduct(ctx, product, changedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockRepository)(nil).CreateProduct), ctx, product, changedBy)
+}
+
+// CreateProducts mocks base method.
+func (m *MockRepository) CreateProducts(ctx context.Context, products []*Product, changedBy string) ([]*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProducts", ctx, products, changedBy)
+	ret0, _ := ret[0].([]*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProducts indicates an expected call of CreateProducts.
+func (mr *MockRepositoryMockRecorder) CreateProducts(ctx, products, changedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProducts", reflect.TypeOf((*MockRepository)(nil).CreateProducts), ctx, products, changedBy)
+}
+
+// CreateProductsSkippingConflicts mocks base method.
+func (m *MockRepository) CreateProductsSkippingConflicts(ctx context.Context, products []*Product, changedBy string) ([]*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductsSkippingConflicts", ctx, products, changedBy)
+	ret0, _ := ret[0].([]*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProductsSkippingConflicts indicates an expected call of CreateProductsSkippingConflicts.
+func (mr *MockRepositoryMockRecorder) CreateProductsSkippingConflicts(ctx, products, changedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductsSkippingConflicts", reflect.TypeOf((*MockRepository)(nil).CreateProductsSkippingConflicts), ctx, products, changedBy)
+}
+
+// CreateScheduledPrice mocks base method.
+func (m *MockRepository) CreateScheduledPrice(ctx context.Context, price *ScheduledPrice) (*ScheduledPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledPrice", ctx, price)
+	ret0, _ := ret[0].(*ScheduledPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledPrice indicates an expected call of CreateScheduledPrice.
+func (mr *MockRepositoryMockRecorder) CreateScheduledPrice(ctx, price interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledPrice", reflect.TypeOf((*MockRepository)(nil).CreateScheduledPrice), ctx, price)
+}
+
+// CreateVariant mocks base method.
+func (m *MockRepository) CreateVariant(ctx context.Context, variant *Variant) (*Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVariant", ctx, variant)
+	ret0, _ := ret[0].(*Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVariant indicates an expected call of CreateVariant.
+func (mr *MockRepositoryMockRecorder) CreateVariant(ctx, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVariant", reflect.TypeOf((*MockRepository)(nil).CreateVariant), ctx, variant)
+}
+
+// DeleteImage mocks base method.
+func (m *MockRepository) DeleteImage(ctx context.Context, productID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteImage", ctx, productID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteImage indicates an expected call of DeleteImage.
+func (mr *MockRepositoryMockRecorder) DeleteImage(ctx, productID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockRepository)(nil).DeleteImage), ctx, productID, id)
+}
+
+// DeleteProduct mocks base method.
+func (m *MockRepository) DeleteProduct(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockRepositoryMockRecorder) DeleteProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockRepository)(nil).DeleteProduct), ctx, id)
+}
+
+// DeleteVariant mocks base method.
+func (m *MockRepository) DeleteVariant(ctx context.Context, productID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVariant", ctx, productID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVariant indicates an expected call of DeleteVariant.
+func (mr *MockRepositoryMockRecorder) DeleteVariant(ctx, productID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVariant", reflect.TypeOf((*MockRepository)(nil).DeleteVariant), ctx, productID, id)
+}
+
+// ExportProducts mocks base method.
+func (m *MockRepository) ExportProducts(ctx context.Context, filter ListFilter, fn func(*Product) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportProducts", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportProducts indicates an expected call of ExportProducts.
+func (mr *MockRepositoryMockRecorder) ExportProducts(ctx, filter, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockRepository)(nil).CreateProduct), ctx, product)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportProducts", reflect.TypeOf((*MockRepository)(nil).ExportProducts), ctx, filter, fn)
+}
+
+// GetImage mocks base method.
+func (m *MockRepository) GetImage(ctx context.Context, productID, id string) (*Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImage", ctx, productID, id)
+	ret0, _ := ret[0].(*Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImage indicates an expected call of GetImage.
+func (mr *MockRepositoryMockRecorder) GetImage(ctx, productID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImage", reflect.TypeOf((*MockRepository)(nil).GetImage), ctx, productID, id)
+}
+
+// GetImagesByProductIDs mocks base method.
+func (m *MockRepository) GetImagesByProductIDs(ctx context.Context, productIDs []string) ([]Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImagesByProductIDs", ctx, productIDs)
+	ret0, _ := ret[0].([]Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImagesByProductIDs indicates an expected call of GetImagesByProductIDs.
+func (mr *MockRepositoryMockRecorder) GetImagesByProductIDs(ctx, productIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImagesByProductIDs", reflect.TypeOf((*MockRepository)(nil).GetImagesByProductIDs), ctx, productIDs)
+}
+
+// GetProductByCode mocks base method.
+func (m *MockRepository) GetProductByCode(ctx context.Context, code string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductByCode", ctx, code)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductByCode indicates an expected call of GetProductByCode.
+func (mr *MockRepositoryMockRecorder) GetProductByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByCode", reflect.TypeOf((*MockRepository)(nil).GetProductByCode), ctx, code)
 }
 
 // GetProductByID mocks base method.
-func (m *MockRepository) GetProductByID(ctx context.Context, id string) (*Product, error) {
+func (m *MockRepository) GetProductByID(ctx context.Context, id string, opts FindOptions) (*Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProductByID", ctx, id)
+	ret := m.ctrl.Call(m, "GetProductByID", ctx, id, opts)
 	ret0, _ := ret[0].(*Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetProductByID indicates an expected call of GetProductByID.
-func (mr *MockRepositoryMockRecorder) GetProductByID(ctx, id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetProductByID(ctx, id, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByID", reflect.TypeOf((*MockRepository)(nil).GetProductByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByID", reflect.TypeOf((*MockRepository)(nil).GetProductByID), ctx, id, opts)
 }
 
 // GetProductsByIDs mocks base method.
-func (m *MockRepository) GetProductsByIDs(ctx context.Context, ids []string) ([]Product, error) {
+func (m *MockRepository) GetProductsByIDs(ctx context.Context, ids []string, opts FindOptions) ([]Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProductsByIDs", ctx, ids)
+	ret := m.ctrl.Call(m, "GetProductsByIDs", ctx, ids, opts)
 	ret0, _ := ret[0].([]Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetProductsByIDs indicates an expected call of GetProductsByIDs.
-func (mr *MockRepositoryMockRecorder) GetProductsByIDs(ctx, ids interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetProductsByIDs(ctx, ids, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsByIDs", reflect.TypeOf((*MockRepository)(nil).GetProductsByIDs), ctx, ids, opts)
+}
+
+// GetScheduledPricesAt mocks base method.
+func (m *MockRepository) GetScheduledPricesAt(ctx context.Context, productIDs []string, at time.Time) ([]ScheduledPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledPricesAt", ctx, productIDs, at)
+	ret0, _ := ret[0].([]ScheduledPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledPricesAt indicates an expected call of GetScheduledPricesAt.
+func (mr *MockRepositoryMockRecorder) GetScheduledPricesAt(ctx, productIDs, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledPricesAt", reflect.TypeOf((*MockRepository)(nil).GetScheduledPricesAt), ctx, productIDs, at)
+}
+
+// GetVariant mocks base method.
+func (m *MockRepository) GetVariant(ctx context.Context, productID, id string) (*Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariant", ctx, productID, id)
+	ret0, _ := ret[0].(*Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariant indicates an expected call of GetVariant.
+func (mr *MockRepositoryMockRecorder) GetVariant(ctx, productID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariant", reflect.TypeOf((*MockRepository)(nil).GetVariant), ctx, productID, id)
+}
+
+// GetVariantsBySKUs mocks base method.
+func (m *MockRepository) GetVariantsBySKUs(ctx context.Context, skus []string) ([]Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariantsBySKUs", ctx, skus)
+	ret0, _ := ret[0].([]Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariantsBySKUs indicates an expected call of GetVariantsBySKUs.
+func (mr *MockRepositoryMockRecorder) GetVariantsBySKUs(ctx, skus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariantsBySKUs", reflect.TypeOf((*MockRepository)(nil).GetVariantsBySKUs), ctx, skus)
+}
+
+// ListImages mocks base method.
+func (m *MockRepository) ListImages(ctx context.Context, productID string) ([]Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListImages", ctx, productID)
+	ret0, _ := ret[0].([]Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListImages indicates an expected call of ListImages.
+func (mr *MockRepositoryMockRecorder) ListImages(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImages", reflect.TypeOf((*MockRepository)(nil).ListImages), ctx, productID)
+}
+
+// ListProducts mocks base method.
+func (m *MockRepository) ListProducts(ctx context.Context, filter ListFilter) ([]Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProducts", ctx, filter)
+	ret0, _ := ret[0].([]Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProducts indicates an expected call of ListProducts.
+func (mr *MockRepositoryMockRecorder) ListProducts(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProducts", reflect.TypeOf((*MockRepository)(nil).ListProducts), ctx, filter)
+}
+
+// ListScheduledPrices mocks base method.
+func (m *MockRepository) ListScheduledPrices(ctx context.Context, productID string) ([]ScheduledPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledPrices", ctx, productID)
+	ret0, _ := ret[0].([]ScheduledPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledPrices indicates an expected call of ListScheduledPrices.
+func (mr *MockRepositoryMockRecorder) ListScheduledPrices(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledPrices", reflect.TypeOf((*MockRepository)(nil).ListScheduledPrices), ctx, productID)
+}
+
+// ListVariants mocks base method.
+func (m *MockRepository) ListVariants(ctx context.Context, productID string) ([]Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVariants", ctx, productID)
+	ret0, _ := ret[0].([]Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVariants indicates an expected call of ListVariants.
+func (mr *MockRepositoryMockRecorder) ListVariants(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVariants", reflect.TypeOf((*MockRepository)(nil).ListVariants), ctx, productID)
+}
+
+// ReorderImages mocks base method.
+func (m *MockRepository) ReorderImages(ctx context.Context, productID string, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderImages", ctx, productID, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderImages indicates an expected call of ReorderImages.
+func (mr *MockRepositoryMockRecorder) ReorderImages(ctx, productID, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderImages", reflect.TypeOf((*MockRepository)(nil).ReorderImages), ctx, productID, ids)
+}
+
+// RestoreProduct mocks base method.
+func (m *MockRepository) RestoreProduct(ctx context.Context, id string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreProduct", ctx, id)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreProduct indicates an expected call of RestoreProduct.
+func (mr *MockRepositoryMockRecorder) RestoreProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreProduct", reflect.TypeOf((*MockRepository)(nil).RestoreProduct), ctx, id)
+}
+
+// SearchProducts mocks base method.
+func (m *MockRepository) SearchProducts(ctx context.Context, filter SearchFilter) ([]Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", ctx, filter)
+	ret0, _ := ret[0].([]Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockRepositoryMockRecorder) SearchProducts(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockRepository)(nil).SearchProducts), ctx, filter)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockRepository) UpdateProduct(ctx context.Context, product *Product, changedBy string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, product, changedBy)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockRepositoryMockRecorder) UpdateProduct(ctx, product, changedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockRepository)(nil).UpdateProduct), ctx, product, changedBy)
+}
+
+// UpdateVariant mocks base method.
+func (m *MockRepository) UpdateVariant(ctx context.Context, variant *Variant) (*Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariant", ctx, variant)
+	ret0, _ := ret[0].(*Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVariant indicates an expected call of UpdateVariant.
+func (mr *MockRepositoryMockRecorder) UpdateVariant(ctx, variant interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsByIDs", reflect.TypeOf((*MockRepository)(nil).GetProductsByIDs), ctx, ids)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariant", reflect.TypeOf((*MockRepository)(nil).UpdateVariant), ctx, variant)
 }
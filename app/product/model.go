@@ -1,6 +1,11 @@
 package product
 
-import "time"
+import (
+	"time"
+
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/pact-cdc-example/product-service/pkg/validation"
+)
 
 type Product struct {
 	ID           string      `json:"-"`
@@ -9,15 +14,97 @@ type Product struct {
 	Color        string      `json:"-"`
 	CreatedAt    time.Time   `json:"-"`
 	UpdatedAt    time.Time   `json:"-"`
-	BuyingPrice  float64     `json:"-"`
-	SellingPrice float64     `json:"-"`
-	ImageURL     string      `json:"-"`
-	Type         ProductType `json:"-"`
-	Provider     string      `json:"-"`
-	Creator      string      `json:"-"`
-	Distributor  string      `json:"-"`
+	BuyingPrice  money.Money `json:"-"`
+	SellingPrice money.Money `json:"-"`
+	// Prices is the price list of the product, its selling price in other
+	// currencies than the one it is priced in.
+	Prices      []money.Money `json:"-"`
+	ImageURL    string        `json:"-"`
+	Type        ProductType   `json:"-"`
+	Provider    string        `json:"-"`
+	Creator     string        `json:"-"`
+	Distributor string        `json:"-"`
+	DeletedAt   *time.Time    `json:"-"`
+	// Schedule holds the scheduled prices of the product in effect at the
+	// time it is looked up for, it is filled separately from the product.
+	Schedule []ScheduledPrice `json:"-"`
+	// Discount is the discount of the promotions on the price in effect at
+	// the time the product is looked up for, nil when none applies.
+	Discount *Discount `json:"-"`
+}
+
+// DefaultCurrency is the currency of the prices given without one, every
+// price was in it before products could be priced in other currencies.
+const DefaultCurrency money.Currency = "TRY"
+
+// Currency is the currency the product is priced in.
+func (p *Product) Currency() money.Currency {
+	return p.SellingPrice.Currency
+}
+
+// ListedPrice returns the selling price of the product in the given currency,
+// either its own selling price or the one of its price list.
+func (p *Product) ListedPrice(currency money.Currency) (money.Money, bool) {
+	if currency == p.Currency() {
+		return p.SellingPrice, true
+	}
+
+	for _, price := range p.Prices {
+		if price.Currency == currency {
+			return price, true
+		}
+	}
+
+	return money.Money{}, false
 }
 
+// Variant is a purchasable version of a product, e.g. a size and color of a
+// shoe, identified by its SKU. Price overrides the selling price of the
+// product when set, it is in the currency of the product.
+type Variant struct {
+	ID        string
+	ProductID string
+	SKU       string
+	Size      string
+	Color     string
+	Barcode   string
+	Price     *money.Money
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// FindOptions narrows or widens the set of products a repository lookup
+// considers. Soft deleted products are skipped unless IncludeDeleted is set.
+type FindOptions struct {
+	IncludeDeleted bool
+}
+
+// ListFilter describes a page of the product listing. Empty filter slices
+// match everything, After continues the listing right after the given cursor.
+// The price bounds only match the products priced in their currency.
+type ListFilter struct {
+	Types          []string
+	Colors         []string
+	Providers      []string
+	Distributors   []string
+	MinPrice       *money.Money
+	MaxPrice       *money.Money
+	Sort           ListSort
+	After          *Cursor
+	Limit          int
+	IncludeDeleted bool
+}
+
+// SearchFilter describes a full-text search of the products. The most
+// relevant Limit products matching Query are returned, best match first.
+type SearchFilter struct {
+	Query          string
+	Limit          int
+	IncludeDeleted bool
+}
+
+// ProductType is the slug of the category of a product. The constants are
+// the root categories seeded by the categories migration.
 type ProductType string
 
 const (
@@ -33,25 +120,38 @@ const (
 	Shirt    ProductType = "shirt"
 )
 
-var availableProductTypes = []string{
-	string(Clothing),
-	string(Shoes),
-	string(Bag),
-	string(Watch),
-	string(Wallet),
-	string(Glasses),
-	string(Hat),
-	string(Jacket),
-	string(Pants),
-	string(Shirt),
-}
-
-func isValidProductType(productType string) bool {
-	for _, v := range availableProductTypes {
-		if v == productType {
-			return true
+// NewProduct creates the product of a validated request.
+func NewProduct(id string, req CreateProductRequest) *Product {
+	prices := req.parsePrices(validation.New())
+	return &Product{
+		ID:           id,
+		Name:         req.Name,
+		Code:         req.Code,
+		Color:        req.Color,
+		BuyingPrice:  prices.buying,
+		SellingPrice: prices.selling,
+		Prices:       prices.list,
+		ImageURL:     req.ImageURL,
+		Type:         ProductType(req.Type),
+		Provider:     req.Provider,
+		Creator:      req.Creator,
+		Distributor:  req.Distributor,
+	}
+}
+
+// UniqueIDs returns the given ids without repetitions, keeping the order of
+// their first occurrence.
+func UniqueIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
 		}
+
+		seen[id] = struct{}{}
+		unique = append(unique, id)
 	}
 
-	return false
+	return unique
 }
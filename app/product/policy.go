@@ -0,0 +1,230 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/pact-cdc-example/product-service/pkg/validation"
+)
+
+// PricingPolicy bounds the prices products are created and updated with,
+// beyond what makes a product request valid. A nil policy allows every price.
+type PricingPolicy struct {
+	// minMargin is the lowest margin of the products by their type, in
+	// percent of the selling price, defaultMinMargin applies to the rest.
+	minMargin        map[ProductType]*big.Rat
+	defaultMinMargin *big.Rat
+	// maxChange is the largest change of the selling price in a single
+	// update, in percent of the current one.
+	maxChange *big.Rat
+	// minPrice and maxPrice bound the selling prices in their currency.
+	minPrice map[money.Currency]money.Money
+	maxPrice map[money.Currency]money.Money
+}
+
+// NewPricingPolicyOpts holds the policy as configured, the percentages and
+// prices are strings so that they are read exactly. The empty ones do not
+// limit the prices.
+type NewPricingPolicyOpts struct {
+	// MinMarginPercent maps the product types to their minimum margin.
+	MinMarginPercent        map[string]string
+	DefaultMinMarginPercent string
+	MaxChangePercent        string
+	// MinPrice and MaxPrice map the currencies to the selling price bounds
+	// in them, e.g. {"TRY": "1.00"}.
+	MinPrice map[string]string
+	MaxPrice map[string]string
+}
+
+func NewPricingPolicy(opts *NewPricingPolicyOpts) (*PricingPolicy, error) {
+	policy := &PricingPolicy{
+		minMargin: make(map[ProductType]*big.Rat, len(opts.MinMarginPercent)),
+		minPrice:  make(map[money.Currency]money.Money, len(opts.MinPrice)),
+		maxPrice:  make(map[money.Currency]money.Money, len(opts.MaxPrice)),
+	}
+
+	var err error
+	for productType, percent := range opts.MinMarginPercent {
+		if policy.minMargin[ProductType(productType)], err = parseMargin(percent); err != nil {
+			return nil, fmt.Errorf("invalid minimum margin of %s: %w", productType, err)
+		}
+	}
+
+	if opts.DefaultMinMarginPercent != "" {
+		if policy.defaultMinMargin, err = parseMargin(opts.DefaultMinMarginPercent); err != nil {
+			return nil, fmt.Errorf("invalid default minimum margin: %w", err)
+		}
+	}
+
+	if opts.MaxChangePercent != "" {
+		if policy.maxChange, err = parsePercent(opts.MaxChangePercent); err != nil {
+			return nil, fmt.Errorf("invalid maximum price change: %w", err)
+		}
+	}
+
+	if err = parseBounds(opts.MinPrice, policy.minPrice); err != nil {
+		return nil, fmt.Errorf("invalid minimum price: %w", err)
+	}
+
+	if err = parseBounds(opts.MaxPrice, policy.maxPrice); err != nil {
+		return nil, fmt.Errorf("invalid maximum price: %w", err)
+	}
+
+	for currency, min := range policy.minPrice {
+		if max, ok := policy.maxPrice[currency]; ok && min.Amount > max.Amount {
+			return nil, fmt.Errorf("minimum price %s is above maximum price %s", min, max)
+		}
+	}
+
+	return policy, nil
+}
+
+func parsePercent(value string) (*big.Rat, error) {
+	percent, ok := new(big.Rat).SetString(value)
+	if !ok || percent.Sign() < 0 {
+		return nil, fmt.Errorf("%q is not a non-negative percentage", value)
+	}
+
+	return percent, nil
+}
+
+// parseMargin parses a margin percentage, which is below 100 as no selling
+// price has a margin of 100 percent unless it is bought for free.
+func parseMargin(value string) (*big.Rat, error) {
+	margin, err := parsePercent(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if margin.Cmp(big.NewRat(100, 1)) >= 0 {
+		return nil, errors.New("margin must be below 100 percent")
+	}
+
+	return margin, nil
+}
+
+func parseBounds(values map[string]string, bounds map[money.Currency]money.Money) error {
+	for code, value := range values {
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			return err
+		}
+
+		if bounds[currency], err = money.Parse(value, currency); err != nil {
+			return fmt.Errorf("%s %q: %w", currency, value, err)
+		}
+	}
+
+	return nil
+}
+
+// Check reports the violations of the policy by the prices of the product in
+// the errors of a PricingPolicyViolation bag. Current is the state the
+// product is updated from, nil for a product being created.
+func (p *PricingPolicy) Check(product, current *Product) error {
+	if p == nil {
+		return nil
+	}
+
+	var from *money.Money
+	if current != nil {
+		from = &current.SellingPrice
+	}
+
+	v := validation.New()
+	p.checkPrice(v, "selling_price", product, product.SellingPrice, from)
+	for i, price := range product.Prices {
+		p.checkBounds(v, fmt.Sprintf("prices[%d].amount", i), price)
+	}
+
+	return v.Err(PricingPolicyViolation, "Product prices violate the pricing policy.")
+}
+
+// CheckPrice reports the violations of the policy by another price the
+// product is sold at, e.g. a scheduled price or the price of a variant, as
+// the given field. The price must keep the margin over the buying price of
+// the product, and it may change at most as much from current as the selling
+// price may in an update. A nil current does not limit the change.
+func (p *PricingPolicy) CheckPrice(
+	product *Product, field string, price money.Money, current *money.Money) error {
+	if p == nil {
+		return nil
+	}
+
+	v := validation.New()
+	p.checkPrice(v, field, product, price, current)
+
+	return v.Err(PricingPolicyViolation, "Product prices violate the pricing policy.")
+}
+
+func (p *PricingPolicy) checkPrice(v *validation.Validator,
+	field string, product *Product, price money.Money, current *money.Money) {
+	p.checkBounds(v, field, price)
+
+	if margin := p.minMarginOf(product.Type); margin != nil {
+		v.Check(hasMargin(price, product.BuyingPrice, margin), field, MarginBelowMinimum,
+			fmt.Sprintf("Selling price must have a margin of at least %s%% for %s products.",
+				percentString(margin), product.Type))
+	}
+
+	if p.maxChange != nil && current != nil {
+		v.Check(!changesMoreThan(*current, price, p.maxChange),
+			field, PriceChangeTooLarge,
+			fmt.Sprintf("Selling price can change at most %s%% at once.",
+				percentString(p.maxChange)))
+	}
+}
+
+func (p *PricingPolicy) checkBounds(v *validation.Validator, field string, price money.Money) {
+	if min, ok := p.minPrice[price.Currency]; ok {
+		v.Check(price.Amount >= min.Amount, field, PriceBelowMinimum,
+			fmt.Sprintf("Price can not be lower than %s.", min))
+	}
+
+	if max, ok := p.maxPrice[price.Currency]; ok {
+		v.Check(price.Amount <= max.Amount, field, PriceAboveMaximum,
+			fmt.Sprintf("Price can not be higher than %s.", max))
+	}
+}
+
+func (p *PricingPolicy) minMarginOf(productType ProductType) *big.Rat {
+	if margin, ok := p.minMargin[productType]; ok {
+		return margin
+	}
+
+	return p.defaultMinMargin
+}
+
+// hasMargin reports whether the selling price is at least margin percent
+// over the buying price, (selling - buying) / selling. A free product has the
+// margin only when it is bought for free as well.
+func hasMargin(sellingPrice, buyingPrice money.Money, margin *big.Rat) bool {
+	selling := new(big.Rat).SetInt64(sellingPrice.Amount)
+	buying := new(big.Rat).SetInt64(buyingPrice.Amount)
+
+	profit := new(big.Rat).Sub(selling, buying)
+	profit.Mul(profit, big.NewRat(100, 1))
+	return profit.Cmp(new(big.Rat).Mul(selling, margin)) >= 0
+}
+
+// changesMoreThan reports whether the price changes more than percent of the
+// current one. Changes from a free price or to another currency are a
+// repricing of the product rather than a change, they are not limited.
+func changesMoreThan(current, price money.Money, percent *big.Rat) bool {
+	if current.Currency != price.Currency || current.Amount == 0 {
+		return false
+	}
+
+	change := new(big.Rat).SetInt64(price.Amount - current.Amount)
+	change.Abs(change).Mul(change, big.NewRat(100, 1))
+	return change.Cmp(new(big.Rat).Mul(new(big.Rat).SetInt64(current.Amount), percent)) > 0
+}
+
+// percentString formats a percentage without trailing zeros, e.g. 12.5.
+func percentString(percent *big.Rat) string {
+	s := percent.FloatString(2)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
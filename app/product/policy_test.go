@@ -0,0 +1,151 @@
+package product_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPricingPolicyCheck(t *testing.T) {
+	policy, err := product.NewPricingPolicy(&product.NewPricingPolicyOpts{
+		MinMarginPercent:        map[string]string{"watch": "20"},
+		DefaultMinMarginPercent: "10",
+		MaxChangePercent:        "50",
+		MinPrice:                map[string]string{"try": "1.00"},
+		MaxPrice:                map[string]string{"TRY": "10000.00", "EUR": "500.00"},
+	})
+	require.NoError(t, err)
+
+	priced := func(productType product.ProductType, buying, selling int64) *product.Product {
+		return &product.Product{
+			Type:         productType,
+			BuyingPrice:  money.New(buying, "TRY"),
+			SellingPrice: money.New(selling, "TRY"),
+		}
+	}
+	current := priced(product.Bag, 8000, 10000)
+
+	cases := []struct {
+		name     string
+		product  *product.Product
+		current  *product.Product
+		expected []cerr.Code
+	}{
+		{"default margin", priced(product.Bag, 9000, 10000), nil, nil},
+		{"below default margin", priced(product.Bag, 9001, 10000), nil,
+			[]cerr.Code{product.MarginBelowMinimum}},
+		{"type margin", priced(product.Watch, 8000, 10000), nil, nil},
+		{"below type margin", priced(product.Watch, 8001, 10000), nil,
+			[]cerr.Code{product.MarginBelowMinimum}},
+		{"free product", priced(product.Bag, 0, 0), nil,
+			[]cerr.Code{product.PriceBelowMinimum}},
+		{"above maximum", priced(product.Bag, 0, 1000001), nil,
+			[]cerr.Code{product.PriceAboveMaximum}},
+		{"largest change", priced(product.Bag, 8000, 15000), current, nil},
+		{"too large change", priced(product.Bag, 8000, 15001), current,
+			[]cerr.Code{product.PriceChangeTooLarge}},
+		{"too large drop", priced(product.Bag, 0, 4999), current,
+			[]cerr.Code{product.PriceChangeTooLarge}},
+		{"change of currency", &product.Product{
+			Type:         product.Bag,
+			BuyingPrice:  money.New(0, "EUR"),
+			SellingPrice: money.New(50000, "EUR"),
+			Prices:       []money.Money{money.New(99, "TRY")},
+		}, current, []cerr.Code{product.PriceBelowMinimum}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := policy.Check(c.product, c.current)
+			if c.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var bag cerr.Bag
+			require.ErrorAs(t, err, &bag)
+			assert.Equal(t, cerr.Code(product.PricingPolicyViolation), bag.Code)
+
+			var codes []cerr.Code
+			for _, fieldErr := range bag.Errors {
+				codes = append(codes, fieldErr.Code)
+			}
+			assert.Equal(t, c.expected, codes)
+		})
+	}
+}
+
+func TestPricingPolicyCheckPrice(t *testing.T) {
+	policy, err := product.NewPricingPolicy(&product.NewPricingPolicyOpts{
+		DefaultMinMarginPercent: "10",
+		MaxChangePercent:        "50",
+		MaxPrice:                map[string]string{"TRY": "10000.00"},
+	})
+	require.NoError(t, err)
+
+	bag := &product.Product{
+		Type:         product.Bag,
+		BuyingPrice:  money.New(8000, "TRY"),
+		SellingPrice: money.New(10000, "TRY"),
+	}
+	price := func(amount int64) money.Money { return money.New(amount, "TRY") }
+
+	cases := []struct {
+		name     string
+		price    money.Money
+		current  *money.Money
+		expected []cerr.Code
+	}{
+		{"allowed", price(9000), &bag.SellingPrice, nil},
+		{"below margin", price(8800), nil, []cerr.Code{product.MarginBelowMinimum}},
+		{"above maximum", price(1000001), nil, []cerr.Code{product.PriceAboveMaximum}},
+		{"unlimited change", price(25000), nil, nil},
+		{"too large change", price(25000), &bag.SellingPrice,
+			[]cerr.Code{product.PriceChangeTooLarge}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := policy.CheckPrice(bag, "price", c.price, c.current)
+			if c.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var bag cerr.Bag
+			require.ErrorAs(t, err, &bag)
+			assert.Equal(t, cerr.Code(product.PricingPolicyViolation), bag.Code)
+
+			var codes []cerr.Code
+			for _, fieldErr := range bag.Errors {
+				assert.Equal(t, "price", fieldErr.Field)
+				codes = append(codes, fieldErr.Code)
+			}
+			assert.Equal(t, c.expected, codes)
+		})
+	}
+}
+
+func TestNewPricingPolicyErrors(t *testing.T) {
+	invalid := []*product.NewPricingPolicyOpts{
+		{DefaultMinMarginPercent: "100"},
+		{MinMarginPercent: map[string]string{"bag": "-1"}},
+		{MaxChangePercent: "ten"},
+		{MinPrice: map[string]string{"XYZ": "1"}},
+		{MinPrice: map[string]string{"TRY": "1.001"}},
+		{MinPrice: map[string]string{"TRY": "10"}, MaxPrice: map[string]string{"TRY": "5"}},
+	}
+
+	for _, opts := range invalid {
+		_, err := product.NewPricingPolicy(opts)
+		assert.Error(t, err, "%+v", opts)
+	}
+
+	var policy *product.PricingPolicy
+	assert.NoError(t, policy.Check(&product.Product{}, nil))
+	assert.NoError(t, policy.CheckPrice(&product.Product{}, "price", money.Money{}, nil))
+}
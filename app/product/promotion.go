@@ -0,0 +1,29 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/pkg/money"
+)
+
+// Discounter applies the promotions running at the given time to the prices
+// of the items, the discounts are returned in the order of the items.
+type Discounter interface {
+	Discount(ctx context.Context, at time.Time, items []DiscountItem) ([]Discount, error)
+}
+
+// DiscountItem is a product priced at Price, its selling price in effect.
+type DiscountItem struct {
+	ProductID string
+	Type      string
+	Provider  string
+	Price     money.Money
+}
+
+// Discount is the price of an item after the promotions applied to it,
+// PromotionIDs is empty when none did.
+type Discount struct {
+	Price        money.Money
+	PromotionIDs []string
+}
@@ -2,11 +2,14 @@ package product_test
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/brianvoe/gofakeit"
+	"github.com/pact-cdc-example/product-service/app/persistence"
 	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/money"
 	"github.com/pact-cdc-example/product-service/pkg/server"
 
 	"log"
@@ -14,7 +17,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/pact-foundation/pact-go/dsl"
 	"github.com/pact-foundation/pact-go/types"
@@ -54,13 +56,21 @@ func (s *PactSettings) getPactURL() string {
 
 type ProviderTestSuite struct {
 	suite.Suite
-	ctrl         *gomock.Controller
 	pactSettings *PactSettings
 	ctx          context.Context
 	l            *logrus.Logger
 	app          server.Server
-	mockRepo     *product.MockRepository
+	repository   *providerRepository
 	serverPort   string
+	// seedRequested makes the product requested by the next interaction
+	// exist, see seedRequestedProduct.
+	seedRequested bool
+}
+
+// providerRepository is the repository of the provider states, every state
+// starts over with an empty memory repository.
+type providerRepository struct {
+	product.Repository
 }
 
 func TestProvider(t *testing.T) {
@@ -70,11 +80,10 @@ func TestProvider(t *testing.T) {
 func (s *ProviderTestSuite) SetupSuite() {
 	s.l, _ = test.NewNullLogger()
 	s.ctx = context.Background()
-	s.ctrl = gomock.NewController(s.T())
-	s.mockRepo = product.NewMockRepository(s.ctrl)
+	s.repository = &providerRepository{persistence.NewMemoryRepository()}
 
 	productService := product.NewService(&product.NewServiceOpts{
-		R: s.mockRepo,
+		R: s.repository,
 		L: s.l,
 	})
 
@@ -89,6 +98,7 @@ func (s *ProviderTestSuite) SetupSuite() {
 	s.serverPort = fmt.Sprintf("%d", sp)
 
 	s.app = server.New(&server.NewServerOpts{
+		L:    s.l,
 		Port: s.serverPort,
 	}, []server.RouteHandler{
 		productHandler,
@@ -141,6 +151,7 @@ func (s *ProviderTestSuite) TestProvider() {
 		FailIfNoPactsFound:         true,
 		PublishVerificationResults: true,
 		ProviderVersion:            s.pactSettings.ProviderVersion,
+		RequestFilter:              s.seedRequestedProduct,
 		StateHandlers: map[string]types.StateHandler{
 			// /products/{id} endpoints provider states
 			"i get product with given id": s.iGetProductWithGivenIDStateHandler,
@@ -148,8 +159,7 @@ func (s *ProviderTestSuite) TestProvider() {
 
 			"i get body parser error when no product id is given":                                 s.iGetBodyParserErrorWhenNoProductIDIsGivenStateHandler,
 			"i get product not found error when the one of product with given id does not exists": s.iGetProductNotFoundErrorWhenTheOneOfProductWithGivenIDDoesNotExistsStateHandler,
-			/*	"i get products with given ids":                                                       s.iGetProductsWithGivenIDsStateHandler,*/
-
+			"i get products with given ids":                                                       s.iGetProductsWithGivenIDsStateHandler,
 		},
 	}
 
@@ -165,72 +175,67 @@ func (s *ProviderTestSuite) TestProvider() {
 
 func (s *ProviderTestSuite) iGetBodyParserErrorWhenNoProductIDIsGivenStateHandler() error {
 	//no need to do anything, automatically captured on handler layer.
-	return nil
+	return s.givenProducts()
 }
 
 func (s *ProviderTestSuite) iGetProductWithGivenIDStateHandler() error {
-	s.mockRepo.EXPECT().GetProductByID(gomock.Any(), gomock.Any()).
-		Return(randomProduct(""), nil)
+	if err := s.givenProducts(); err != nil {
+		return err
+	}
 
+	s.seedRequested = true
 	return nil
 }
 
 func (s *ProviderTestSuite) iGetProductNotFoundErrorWhenTheProductWithGivenIDDoesNotExistsStateHandler() error {
-	s.mockRepo.EXPECT().GetProductByID(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
-	return nil
+	return s.givenProducts()
 }
 
 func (s *ProviderTestSuite) iGetProductNotFoundErrorWhenTheOneOfProductWithGivenIDDoesNotExistsStateHandler() error {
-	s.mockRepo.EXPECT().GetProductsByIDs(gomock.Any(), gomock.Any()).Return(nil, nil)
-	return nil
+	return s.givenProducts()
 }
 
 func (s *ProviderTestSuite) iGetProductsWithGivenIDsStateHandler() error {
-	/*
-			"ids": [
-				"9566c74d-1003-4c4d-bbbb-0407d1e2c649",
-				"81855ad8-681d-4d86-91e9-1e00167939cb",
-				"6694d2c4-22ac-4208-a007-2939487f6999"
-			]
-
-		ids expected in contract and we will give all of them to see if the state works as expected.
-	*/
-	//products := []*product.Product{
-	//	randomProduct("9566c74d-1003-4c4d-bbbb-0407d1e2c649"),
-	//	randomProduct("6694d2c4-22ac-4208-a007-2939487f6999"),
-	//	randomProduct("81855ad8-681d-4d86-91e9-1e00167939cb"),
-	//}
-	//s.mockRepo.EXPECT().GetProductByID(gomock.Any(), gomock.Any()).Return(products[0], nil).Times(1)
-	//s.mockRepo.EXPECT().GetProductByID(gomock.Any(), gomock.Any()).Return(products[1], nil).Times(1)
-	//s.mockRepo.EXPECT().GetProductByID(gomock.Any(), gomock.Any()).Return(products[2], nil).Times(1)
+	// the ids expected in the contract.
+	return s.givenProducts(
+		"9566c74d-1003-4c4d-bbbb-0407d1e2c649",
+		"81855ad8-681d-4d86-91e9-1e00167939cb",
+		"6694d2c4-22ac-4208-a007-2939487f6999",
+	)
+}
+
+// givenProducts starts the state over with the products of the given ids,
+// the ids a state leaves out do not exist.
+func (s *ProviderTestSuite) givenProducts(ids ...string) error {
+	s.repository.Repository = persistence.NewMemoryRepository()
+	s.seedRequested = false
+
+	for _, id := range ids {
+		if _, err := s.repository.CreateProduct(s.ctx, randomProduct(id), ""); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-/*
-func createProductTableOnDB(sql *sql.DB) error {
-	_, err := sql.Exec(`
-		CREATE TABLE IF NOT EXISTS products (
-			id VARCHAR(255) NOT NULL PRIMARY KEY,
-			name VARCHAR(255) NOT NULL,
-			price NUMERIC(10,2) NOT NULL,
-		    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-		    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
-		    buying_price NUMERIC(10,2) NOT NULL,
-		    selling_price NUMERIC(10,2) NOT NULL,
-		    image_url VARCHAR(255) NOT NULL,
-		    type VARCHAR(255) NOT NULL,
-		    provider VARCHAR(255) NOT NULL,
-		    creator VARCHAR(255) NOT NULL,
-		    distributor VARCHAR(255) NOT NULL,
-		    code VARCHAR(255) NOT NULL,
-		    color VARCHAR(255) NOT NULL
-		);
-	`)
-
-	return err
+// seedRequestedProduct creates the product an interaction gets by its id when
+// its state asks for it, the id is only known from the request.
+func (s *ProviderTestSuite) seedRequestedProduct(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/products/")
+		if s.seedRequested && r.Method == http.MethodGet &&
+			id != r.URL.Path && id != "" && !strings.Contains(id, "/") {
+			s.seedRequested = false
+			if err := s.givenProducts(id); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
 }
-*/
 
 func randomProduct(id string) *product.Product {
 	if id == "" {
@@ -239,12 +244,12 @@ func randomProduct(id string) *product.Product {
 	return &product.Product{
 		ID:           id,
 		Name:         gofakeit.Name(),
-		Code:         gofakeit.Word(),
+		Code:         gofakeit.Word() + "-" + id,
 		Color:        gofakeit.Color(),
 		CreatedAt:    gofakeit.Date(),
 		UpdatedAt:    gofakeit.Date(),
-		BuyingPrice:  gofakeit.Price(0, 3000),
-		SellingPrice: gofakeit.Price(3500, 10000),
+		BuyingPrice:  money.New(int64(gofakeit.Number(0, 300000)), product.DefaultCurrency),
+		SellingPrice: money.New(int64(gofakeit.Number(350000, 1000000)), product.DefaultCurrency),
 		ImageURL:     gofakeit.ImageURL(100, 200),
 		Type: product.ProductType(
 			gofakeit.RandString([]string{
@@ -1,10 +1,62 @@
 package product
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 //go:generate mockgen -source=repository.go -destination=mock_repository.go -package=product
 type Repository interface {
-	GetProductByID(ctx context.Context, id string) (*Product, error)
-	GetProductsByIDs(ctx context.Context, ids []string) ([]Product, error)
-	CreateProduct(ctx context.Context, product *Product) (*Product, error)
+	GetProductByID(ctx context.Context, id string, opts FindOptions) (*Product, error)
+	GetProductByCode(ctx context.Context, code string) (*Product, error)
+	GetProductsByIDs(ctx context.Context, ids []string, opts FindOptions) ([]Product, error)
+	ListProducts(ctx context.Context, filter ListFilter) ([]Product, error)
+	SearchProducts(ctx context.Context, filter SearchFilter) ([]Product, error)
+	ExportProducts(ctx context.Context, filter ListFilter, fn func(*Product) error) error
+	// CreateProduct creates the product and records its selling price in its
+	// price history as changed by changedBy, both at once.
+	CreateProduct(ctx context.Context, product *Product, changedBy string) (*Product, error)
+	// CreateProducts creates either all of the products, along with their
+	// price history like CreateProduct, or none of them. A taken code fails
+	// with a ProductCodeConflictError when the repository can tell the code,
+	// with ErrProductCodeAlreadyExists otherwise.
+	CreateProducts(ctx context.Context, products []*Product, changedBy string) ([]*Product, error)
+	// CreateProductsSkippingConflicts creates the products whose code is not
+	// taken like CreateProduct and returns them, the others are skipped. The
+	// products are not created atomically, the ones returned along with an
+	// error are created.
+	CreateProductsSkippingConflicts(
+		ctx context.Context, products []*Product, changedBy string) ([]*Product, error)
+	// UpdateProduct updates the product and records its selling price in its
+	// price history when it changes, both at once.
+	UpdateProduct(ctx context.Context, product *Product, changedBy string) (*Product, error)
+	DeleteProduct(ctx context.Context, id string) error
+	RestoreProduct(ctx context.Context, id string) (*Product, error)
+	GetVariant(ctx context.Context, productID, id string) (*Variant, error)
+	GetVariantsBySKUs(ctx context.Context, skus []string) ([]Variant, error)
+	ListVariants(ctx context.Context, productID string) ([]Variant, error)
+	CreateVariant(ctx context.Context, variant *Variant) (*Variant, error)
+	UpdateVariant(ctx context.Context, variant *Variant) (*Variant, error)
+	DeleteVariant(ctx context.Context, productID, id string) error
+	// ListScheduledPrices returns the price history of the product, the
+	// latest starting price first.
+	ListScheduledPrices(ctx context.Context, productID string) ([]ScheduledPrice, error)
+	// GetScheduledPricesAt returns the scheduled prices of the products in
+	// effect at the given time.
+	GetScheduledPricesAt(
+		ctx context.Context, productIDs []string, at time.Time) ([]ScheduledPrice, error)
+	CreateScheduledPrice(ctx context.Context, price *ScheduledPrice) (*ScheduledPrice, error)
+	// ListImages returns the images of the product in their order.
+	ListImages(ctx context.Context, productID string) ([]Image, error)
+	// GetImagesByProductIDs returns the images of the products, the ones of
+	// each product in their order.
+	GetImagesByProductIDs(ctx context.Context, productIDs []string) ([]Image, error)
+	GetImage(ctx context.Context, productID, id string) (*Image, error)
+	// CreateImage adds the image after the last image of its product. It
+	// returns sql.ErrNoRows when the product does not exist.
+	CreateImage(ctx context.Context, image *Image) (*Image, error)
+	// ReorderImages moves the images of the product to the positions of
+	// their ids in the given order.
+	ReorderImages(ctx context.Context, productID string, ids []string) error
+	DeleteImage(ctx context.Context, productID, id string) error
 }
@@ -1,39 +1,622 @@
 package product
 
-import "github.com/pact-cdc-example/product-service/pkg/cerr"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/pact-cdc-example/product-service/pkg/validation"
+)
+
+type GetProductRequest struct {
+	ID             string
+	IncludeDeleted bool
+	// Currency is the currency the price is returned in, the currency of the
+	// product when empty.
+	Currency string
+	// AsOf is the RFC 3339 time the price is resolved at, the time of the
+	// request when empty.
+	AsOf string
+}
+
+func (g GetProductRequest) Validate() error {
+	if g.Currency != "" {
+		if _, err := money.ParseCurrency(g.Currency); err != nil {
+			return invalidCurrency()
+		}
+	}
+
+	if _, err := g.at(time.Now()); err != nil {
+		return cerr.Bag{Code: InvalidAsOf, Message: "As of must be an RFC 3339 time."}
+	}
+
+	return nil
+}
+
+// at returns the time the price is resolved at, now unless AsOf is given.
+func (g GetProductRequest) at(now time.Time) (time.Time, error) {
+	if g.AsOf == "" {
+		return now, nil
+	}
+
+	return time.Parse(time.RFC3339, g.AsOf)
+}
+
+// GetProductsByIDsRequest looks up products by their ids or by the SKUs of
+// their variants.
 type GetProductsByIDsRequest struct {
-	IDs []string `json:"ids,omitempty"`
+	IDs            []string `json:"ids,omitempty"`
+	IncludeDeleted bool     `json:"-"`
+	// Partial returns the found products along with the missing ids instead
+	// of failing when some of the ids do not exist.
+	Partial bool `json:"-"`
 }
 
-func (g GetProductsByIDsRequest) Validate() error {
+const DefaultMaxBulkIDs = 500
+
+// Validate checks the request against the maximum number of distinct ids
+// that can be looked up at once.
+func (g GetProductsByIDsRequest) Validate(maxIDs int) error {
 	if len(g.IDs) < 1 {
 		return cerr.Bag{Code: AtLeastOneProductIDIsRequired,
 			Message: "At least one product id must be given."}
 	}
 
+	if len(UniqueIDs(g.IDs)) > maxIDs {
+		return cerr.Bag{Code: TooManyProductIDs,
+			Message: fmt.Sprintf("At most %d product ids can be given.", maxIDs)}
+	}
+
+	return nil
+}
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+type ListProductsRequest struct {
+	Types        []string `query:"type"`
+	Colors       []string `query:"color"`
+	Providers    []string `query:"provider"`
+	Distributors []string `query:"distributor"`
+	MinPrice     string   `query:"min_price"`
+	MaxPrice     string   `query:"max_price"`
+	// Currency is the currency of the price bounds, DefaultCurrency when
+	// empty.
+	Currency       string `query:"currency"`
+	Sort           string `query:"sort"`
+	Cursor         string `query:"cursor"`
+	Limit          int    `query:"limit"`
+	IncludeDeleted bool   `query:"-"`
+}
+
+func (l ListProductsRequest) Validate() error {
+	if l.Limit < 0 || l.Limit > MaxListLimit {
+		return cerr.Bag{Code: InvalidListLimit,
+			Message: fmt.Sprintf("Limit must be between 1 and %d.", MaxListLimit)}
+	}
+
+	if _, err := currencyOrDefault(l.Currency); err != nil {
+		return invalidCurrency()
+	}
+
+	minPrice, maxPrice, err := l.priceRange()
+	if err != nil || (minPrice != nil && minPrice.IsNegative()) ||
+		(maxPrice != nil && maxPrice.IsNegative()) ||
+		(minPrice != nil && maxPrice != nil && minPrice.Amount > maxPrice.Amount) {
+		return cerr.Bag{Code: InvalidPriceRange, Message: "Invalid price range."}
+	}
+
+	if _, ok := parseListSort(l.Sort); !ok {
+		return cerr.Bag{Code: InvalidSortOption, Message: "Invalid sort option."}
+	}
+
+	return nil
+}
+
+// Filter converts the request to a repository filter. The request is expected
+// to be validated beforehand.
+func (l ListProductsRequest) Filter() (ListFilter, error) {
+	sort, _ := parseListSort(l.Sort)
+
+	limit := l.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+
+	minPrice, maxPrice, _ := l.priceRange()
+	filter := ListFilter{
+		Types:          l.Types,
+		Colors:         l.Colors,
+		Providers:      l.Providers,
+		Distributors:   l.Distributors,
+		MinPrice:       minPrice,
+		MaxPrice:       maxPrice,
+		Sort:           sort,
+		Limit:          limit,
+		IncludeDeleted: l.IncludeDeleted,
+	}
+
+	if l.Cursor == "" {
+		return filter, nil
+	}
+
+	cursor, err := DecodeCursor(l.Cursor)
+	if err == nil && sort.Field == SortBySellingPrice {
+		_, err = cursor.Price()
+	}
+
+	if err != nil || cursor.Sort != sort.String() {
+		return ListFilter{}, cerr.Bag{Code: InvalidCursor, Message: "Invalid cursor."}
+	}
+
+	filter.After = cursor
+	return filter, nil
+}
+
+func (l ListProductsRequest) priceRange() (minPrice, maxPrice *money.Money, err error) {
+	currency, err := currencyOrDefault(l.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if minPrice, err = parseOptionalPrice(l.MinPrice, currency); err != nil {
+		return nil, nil, err
+	}
+
+	if maxPrice, err = parseOptionalPrice(l.MaxPrice, currency); err != nil {
+		return nil, nil, err
+	}
+
+	return minPrice, maxPrice, nil
+}
+
+func parseOptionalPrice(amount string, currency money.Currency) (*money.Money, error) {
+	if amount == "" {
+		return nil, nil
+	}
+
+	price, err := money.Parse(amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &price, nil
+}
+
+const MaxSearchQueryLength = 200
+
+type SearchProductsRequest struct {
+	Query          string `query:"q"`
+	Limit          int    `query:"limit"`
+	IncludeDeleted bool   `query:"-"`
+}
+
+func (s SearchProductsRequest) Validate() error {
+	query := strings.TrimSpace(s.Query)
+	if query == "" || len(query) > MaxSearchQueryLength {
+		return cerr.Bag{Code: InvalidSearchQuery,
+			Message: fmt.Sprintf("Search query must be between 1 and %d characters.",
+				MaxSearchQueryLength)}
+	}
+
+	if s.Limit < 0 || s.Limit > MaxListLimit {
+		return cerr.Bag{Code: InvalidListLimit,
+			Message: fmt.Sprintf("Limit must be between 1 and %d.", MaxListLimit)}
+	}
+
 	return nil
 }
 
+// Filter converts the request to a repository filter. The request is expected
+// to be validated beforehand.
+func (s SearchProductsRequest) Filter() SearchFilter {
+	limit := s.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+
+	return SearchFilter{
+		Query:          strings.TrimSpace(s.Query),
+		Limit:          limit,
+		IncludeDeleted: s.IncludeDeleted,
+	}
+}
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// ExportProductsRequest streams every product matching the listing filters.
+// The limit of the listing request is ignored.
+// DefaultExportTimeout bounds the exports, a slow or abandoned download must
+// not hold its database connection indefinitely.
+const DefaultExportTimeout = 10 * time.Minute
+
+type ExportProductsRequest struct {
+	Filter ListProductsRequest
+	Format ExportFormat
+	// IncludeInternal adds the buying price, provider, creator and
+	// distributor of the products to the export.
+	IncludeInternal bool
+}
+
+func (e ExportProductsRequest) Validate() error {
+	if e.Format != ExportFormatCSV && e.Format != ExportFormatNDJSON {
+		return cerr.Bag{Code: InvalidExportFormat,
+			Message: "Export format must be one of csv, ndjson."}
+	}
+
+	filter := e.Filter
+	filter.Limit = 0
+	return filter.Validate()
+}
+
+// CreateProductRequest holds the prices as json numbers, so that they are
+// read exactly instead of through floats. They are in Currency, or in
+// DefaultCurrency when it is empty. OverridePricingPolicy skips the pricing
+// policy checks, it is reserved to admins.
 type CreateProductRequest struct {
-	Name         string  `json:"name"`
-	Code         string  `json:"code"`
-	Color        string  `json:"color"`
-	BuyingPrice  float64 `json:"buying_price"`
-	SellingPrice float64 `json:"selling_price"`
-	ImageURL     string  `json:"image_url"`
-	Type         string  `json:"type"`
-	Provider     string  `json:"provider"`
-	Creator      string  `json:"creator"`
-	Distributor  string  `json:"distributor"`
-}
-
-func (c CreateProductRequest) Validate() error {
+	Name         string         `json:"name"`
+	Code         string         `json:"code"`
+	Color        string         `json:"color"`
+	Currency     string         `json:"currency,omitempty"`
+	BuyingPrice  json.Number    `json:"buying_price"`
+	SellingPrice json.Number    `json:"selling_price"`
+	Prices       []PriceRequest `json:"prices,omitempty"`
+	ImageURL     string         `json:"image_url"`
+	Type         string         `json:"type"`
+	Provider     string         `json:"provider"`
+	Creator      string         `json:"creator"`
+	Distributor  string         `json:"distributor"`
+
+	OverridePricingPolicy bool `json:"override_pricing_policy,omitempty"`
+}
+
+// PriceRequest is an entry of the price list of a product, its selling
+// price in another currency.
+type PriceRequest struct {
+	Currency string      `json:"currency"`
+	Amount   json.Number `json:"amount"`
+}
+
+// Validate checks every field of the request and reports all violations at
+// once in the errors of an InvalidProductRequest bag. The product type must
+// be one of knownTypes, see CategoryChecker.
+func (c CreateProductRequest) Validate(knownTypes map[string]bool) error {
+	v := validation.New()
+
+	v.Check(strings.TrimSpace(c.Name) != "", "name",
+		ProductNameIsRequired, "Product name is required.")
+	v.Check(strings.TrimSpace(c.Code) != "", "code",
+		ProductCodeIsRequired, "Product code is required.")
 	if c.Type == "" {
-		return cerr.Bag{Code: ProductTypeIsRequired, Message: "Product type is required."}
+		v.Add("type", ProductTypeIsRequired, "Product type is required.")
+	} else {
+		v.Check(knownTypes[c.Type], "type", InvalidProductType, "Invalid product type.")
+	}
+	prices := c.parsePrices(v)
+	v.Check(!prices.buying.IsNegative(), "buying_price",
+		NegativeBuyingPrice, "Buying price can not be negative.")
+	v.Check(!prices.selling.IsNegative(), "selling_price",
+		NegativeSellingPrice, "Selling price can not be negative.")
+	v.Check(prices.selling.Amount >= prices.buying.Amount, "selling_price",
+		SellingPriceBelowBuyingPrice, "Selling price can not be lower than buying price.")
+	v.Check(c.ImageURL == "" || validation.IsURL(c.ImageURL), "image_url",
+		InvalidImageURL, "Image url must be an absolute http or https url.")
+
+	return v.Err(InvalidProductRequest, "Product request is invalid.")
+}
+
+type productPrices struct {
+	buying  money.Money
+	selling money.Money
+	list    []money.Money
+}
+
+// parsePrices parses the prices of the request, recording the prices that
+// can not be parsed on v. The prices failing to parse are left zero.
+func (c CreateProductRequest) parsePrices(v *validation.Validator) productPrices {
+	currency, err := currencyOrDefault(c.Currency)
+	if err != nil {
+		v.Add("currency", InvalidCurrency, "Currency must be an ISO 4217 currency code.")
+		return productPrices{}
 	}
-	if !isValidProductType(c.Type) {
-		return cerr.Bag{Code: InvalidProductType, Message: "Invalid product type."}
+
+	prices := productPrices{
+		buying:  parsePrice(v, "buying_price", c.BuyingPrice, currency),
+		selling: parsePrice(v, "selling_price", c.SellingPrice, currency),
+	}
+
+	seen := map[money.Currency]bool{currency: true}
+	for i, entry := range c.Prices {
+		field := fmt.Sprintf("prices[%d]", i)
+		listCurrency, err := money.ParseCurrency(entry.Currency)
+		if err != nil {
+			v.Add(field+".currency", InvalidCurrency,
+				"Currency must be an ISO 4217 currency code.")
+			continue
+		}
+
+		if seen[listCurrency] {
+			v.Add(field+".currency", DuplicatePriceCurrency,
+				"Product can have a single price in a currency.")
+			continue
+		}
+		seen[listCurrency] = true
+
+		price := parsePrice(v, field+".amount", entry.Amount, listCurrency)
+		v.Check(!price.IsNegative(), field+".amount",
+			NegativeSellingPrice, "Selling price can not be negative.")
+		prices.list = append(prices.list, price)
+	}
+
+	return prices
+}
+
+// parsePrice parses an optional price, an empty amount being zero.
+func parsePrice(
+	v *validation.Validator, field string, amount json.Number, currency money.Currency) money.Money {
+	if amount == "" {
+		return money.New(0, currency)
+	}
+
+	price, err := money.Parse(amount.String(), currency)
+	if err != nil {
+		v.Add(field, InvalidPrice, invalidPriceMessage(err, currency))
+		return money.New(0, currency)
+	}
+
+	return price
+}
+
+func invalidPriceMessage(err error, currency money.Currency) string {
+	if errors.Is(err, money.ErrTooPrecise) {
+		return fmt.Sprintf("Price can have at most %d decimal places in %s.",
+			currency.Exponent(), currency)
+	}
+
+	return "Price must be a decimal number."
+}
+
+func currencyOrDefault(code string) (money.Currency, error) {
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+
+	return money.ParseCurrency(code)
+}
+
+const DefaultMaxBatchCreate = 1000
+
+type CreateProductsRequest struct {
+	Products []CreateProductRequest
+	// Atomic creates either all products or none, otherwise every valid
+	// product is created on its own.
+	Atomic bool
+}
+
+// Validate checks the size of the batch, the products themselves are
+// validated one by one while creating them.
+func (c CreateProductsRequest) Validate(maxProducts int) error {
+	if len(c.Products) < 1 {
+		return cerr.Bag{Code: AtLeastOneProductIsRequired,
+			Message: "At least one product must be given."}
+	}
+
+	if len(c.Products) > maxProducts {
+		return cerr.Bag{Code: TooManyProducts,
+			Message: fmt.Sprintf("At most %d products can be given.", maxProducts)}
+	}
+
+	return nil
+}
+
+type UpdateProductRequest CreateProductRequest
+
+func (u UpdateProductRequest) Validate(knownTypes map[string]bool) error {
+	return CreateProductRequest(u).Validate(knownTypes)
+}
+
+func newUpdateProductRequest(product *Product) UpdateProductRequest {
+	prices := make([]PriceRequest, 0, len(product.Prices))
+	for _, price := range product.Prices {
+		prices = append(prices, PriceRequest{
+			Currency: string(price.Currency),
+			Amount:   json.Number(price.Decimal()),
+		})
+	}
+
+	return UpdateProductRequest{
+		Name:         product.Name,
+		Code:         product.Code,
+		Color:        product.Color,
+		Currency:     string(product.Currency()),
+		BuyingPrice:  json.Number(product.BuyingPrice.Decimal()),
+		SellingPrice: json.Number(product.SellingPrice.Decimal()),
+		Prices:       prices,
+		ImageURL:     product.ImageURL,
+		Type:         string(product.Type),
+		Provider:     product.Provider,
+		Creator:      product.Creator,
+		Distributor:  product.Distributor,
+	}
+}
+
+// CreateVariantRequest adds a variant to a product. The price overrides the
+// selling price of the product, it is inherited when omitted. The price is in
+// the currency of the product and subject to the pricing policy like the
+// selling price, OverridePricingPolicy is reserved to admins.
+type CreateVariantRequest struct {
+	SKU     string       `json:"sku"`
+	Size    string       `json:"size"`
+	Color   string       `json:"color"`
+	Barcode string       `json:"barcode"`
+	Price   *json.Number `json:"price"`
+
+	OverridePricingPolicy bool `json:"override_pricing_policy,omitempty"`
+}
+
+// Validate checks the request for a product priced in the given currency.
+func (c CreateVariantRequest) Validate(currency money.Currency) error {
+	v := validation.New()
+
+	v.Check(strings.TrimSpace(c.SKU) != "", "sku",
+		VariantSKUIsRequired, "Variant sku is required.")
+	v.Check(c.Barcode == "" || isBarcode(c.Barcode), "barcode",
+		InvalidVariantBarcode, "Barcode must be an EAN-8, UPC-A, EAN-13 or GTIN-14 number.")
+	if c.Price != nil {
+		price := parsePrice(v, "price", *c.Price, currency)
+		v.Check(!price.IsNegative(), "price",
+			NegativeVariantPrice, "Variant price can not be negative.")
+	}
+
+	return v.Err(InvalidVariantRequest, "Variant request is invalid.")
+}
+
+// price parses the price of a validated request.
+func (c CreateVariantRequest) price(currency money.Currency) *money.Money {
+	if c.Price == nil {
+		return nil
+	}
+
+	price := parsePrice(validation.New(), "price", *c.Price, currency)
+	return &price
+}
+
+type UpdateVariantRequest CreateVariantRequest
+
+func (u UpdateVariantRequest) Validate(currency money.Currency) error {
+	return CreateVariantRequest(u).Validate(currency)
+}
+
+// SchedulePriceRequest schedules a selling price of a product from ValidFrom,
+// or from now when omitted, until ValidTo, e.g. a sale. The regular selling
+// price is changed by updating the product instead. The price is in the
+// currency of the product, Currency may only repeat it. The price is subject
+// to the pricing policy as a change of the selling price, OverridePricingPolicy
+// is reserved to admins.
+type SchedulePriceRequest struct {
+	Price     json.Number `json:"price"`
+	Currency  string      `json:"currency,omitempty"`
+	ValidFrom *time.Time  `json:"valid_from,omitempty"`
+	ValidTo   *time.Time  `json:"valid_to"`
+
+	OverridePricingPolicy bool `json:"override_pricing_policy,omitempty"`
+}
+
+// Validate checks the request for a product priced in the given currency.
+// Price history can not be rewritten, so prices can only be scheduled from
+// now on.
+func (s SchedulePriceRequest) Validate(currency money.Currency, now time.Time) error {
+	v := validation.New()
+
+	if s.Price == "" {
+		v.Add("price", ScheduledPriceIsRequired, "Price is required.")
+	} else {
+		price := parsePrice(v, "price", s.Price, currency)
+		v.Check(!price.IsNegative(), "price",
+			NegativeSellingPrice, "Selling price can not be negative.")
+	}
+	if s.Currency != "" {
+		requested, err := money.ParseCurrency(s.Currency)
+		v.Check(err == nil && requested == currency, "currency", InvalidCurrency,
+			fmt.Sprintf("Price must be in the currency of the product, %s.", currency))
+	}
+	v.Check(s.ValidFrom == nil || !s.ValidFrom.Before(now), "valid_from",
+		InvalidPriceWindow, "Price can not be scheduled in the past.")
+	if s.ValidTo == nil {
+		v.Add("valid_to", InvalidPriceWindow, "Scheduled price must have an end.")
+	} else {
+		v.Check(s.ValidTo.After(s.validFrom(now)), "valid_to",
+			InvalidPriceWindow, "Price must be valid until after it is valid from.")
+	}
+
+	return v.Err(InvalidScheduledPriceRequest, "Scheduled price request is invalid.")
+}
+
+func (s SchedulePriceRequest) validFrom(now time.Time) time.Time {
+	if s.ValidFrom == nil {
+		return now
+	}
+
+	return *s.ValidFrom
+}
+
+// scheduledPrice creates the scheduled price of a validated request.
+func (s SchedulePriceRequest) scheduledPrice(
+	id string, product *Product, changedBy string, now time.Time) *ScheduledPrice {
+	return &ScheduledPrice{
+		ID:        id,
+		ProductID: product.ID,
+		Price:     parsePrice(validation.New(), "price", s.Price, product.Currency()),
+		ValidFrom: s.validFrom(now),
+		ValidTo:   s.ValidTo,
+		ChangedBy: changedBy,
+	}
+}
+
+// isBarcode reports whether s is a GTIN of one of the common lengths.
+func isBarcode(s string) bool {
+	switch len(s) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+const DefaultMaxImageSize = 5 << 20
+
+// UploadImageRequest is an image file uploaded for a product. Its content
+// type is sniffed from the content rather than taken from the client.
+type UploadImageRequest struct {
+	Content []byte
+}
+
+// Validate checks the image file against the maximum file size in bytes and
+// the accepted content types, JPEG, PNG and GIF.
+func (u UploadImageRequest) Validate(maxSize int64) error {
+	if len(u.Content) == 0 {
+		return imageIsRequired()
+	}
+
+	if int64(len(u.Content)) > maxSize {
+		return imageTooLarge(maxSize)
+	}
+
+	if _, ok := imageContentType(u.Content); !ok {
+		return cerr.Bag{Code: UnsupportedImageType,
+			Message: "Image must be a JPEG, PNG or GIF file."}
+	}
+
+	return nil
+}
+
+// ReorderImagesRequest lists every image of a product in the order they are
+// to be shown in.
+type ReorderImagesRequest struct {
+	ImageIDs []string `json:"image_ids"`
+}
+
+func (r ReorderImagesRequest) Validate() error {
+	if len(r.ImageIDs) == 0 || len(UniqueIDs(r.ImageIDs)) != len(r.ImageIDs) {
+		return invalidImageOrder()
 	}
 
 	return nil
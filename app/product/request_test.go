@@ -0,0 +1,121 @@
+package product_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProductRequestValidate(t *testing.T) {
+	knownTypes := map[string]bool{"shoes": true}
+	valid := product.CreateProductRequest{
+		Name: "Runner", Code: "RUN-1", Type: "shoes", BuyingPrice: "50", SellingPrice: "100",
+	}
+
+	type fieldCode struct {
+		field string
+		code  cerr.Code
+	}
+
+	cases := []struct {
+		name     string
+		modify   func(r *product.CreateProductRequest)
+		expected []fieldCode
+	}{
+		{"valid", func(r *product.CreateProductRequest) {}, nil},
+		{"every field invalid", func(r *product.CreateProductRequest) {
+			r.Name, r.Code, r.Type = " ", "", ""
+			r.BuyingPrice, r.SellingPrice = "-1", "-2"
+			r.ImageURL = "ftp://example.com/a.png"
+		}, []fieldCode{
+			{"name", product.ProductNameIsRequired},
+			{"code", product.ProductCodeIsRequired},
+			{"type", product.ProductTypeIsRequired},
+			{"buying_price", product.NegativeBuyingPrice},
+			{"selling_price", product.NegativeSellingPrice},
+			{"selling_price", product.SellingPriceBelowBuyingPrice},
+			{"image_url", product.InvalidImageURL},
+		}},
+		{"unknown type and selling below buying", func(r *product.CreateProductRequest) {
+			r.Type, r.SellingPrice = "spaceship", "49.99"
+		}, []fieldCode{
+			{"type", product.InvalidProductType},
+			{"selling_price", product.SellingPriceBelowBuyingPrice},
+		}},
+		{"unparsable prices", func(r *product.CreateProductRequest) {
+			r.Name, r.BuyingPrice, r.SellingPrice = "", "1.234", "abc"
+		}, []fieldCode{
+			{"name", product.ProductNameIsRequired},
+			{"buying_price", product.InvalidPrice},
+			{"selling_price", product.InvalidPrice},
+		}},
+		{"invalid price list", func(r *product.CreateProductRequest) {
+			r.Prices = []product.PriceRequest{
+				{Currency: "EUR", Amount: "9.90"},
+				{Currency: "eur", Amount: "9.90"},
+				{Currency: "XYZ", Amount: "1"},
+				{Currency: "USD", Amount: "-1"},
+				{Currency: "TRY", Amount: "1"},
+			}
+		}, []fieldCode{
+			{"prices[1].currency", product.DuplicatePriceCurrency},
+			{"prices[2].currency", product.InvalidCurrency},
+			{"prices[3].amount", product.NegativeSellingPrice},
+			{"prices[4].currency", product.DuplicatePriceCurrency},
+		}},
+		{"invalid currency", func(r *product.CreateProductRequest) {
+			r.Currency, r.Code = "XYZ", ""
+		}, []fieldCode{
+			{"code", product.ProductCodeIsRequired},
+			{"currency", product.InvalidCurrency},
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := valid
+			c.modify(&req)
+
+			err := req.Validate(knownTypes)
+			if c.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var bag cerr.Bag
+			require.ErrorAs(t, err, &bag)
+			assert.Equal(t, cerr.Code(product.InvalidProductRequest), bag.Code)
+
+			var errs []fieldCode
+			for _, fieldErr := range bag.Errors {
+				assert.NotEmpty(t, fieldErr.Message)
+				errs = append(errs, fieldCode{fieldErr.Field, fieldErr.Code})
+			}
+			assert.Equal(t, c.expected, errs)
+		})
+	}
+}
+
+func TestListProductsRequestPriceCursor(t *testing.T) {
+	sort := product.ListSort{Field: product.SortBySellingPrice}
+	cursor := product.NewCursor(sort, &product.Product{
+		ID: "id", SellingPrice: money.New(1250, "EUR")})
+
+	filter, err := product.ListProductsRequest{Sort: "price", Cursor: cursor.Encode()}.Filter()
+	require.NoError(t, err)
+	price, err := filter.After.Price()
+	require.NoError(t, err)
+	assert.Equal(t, money.New(1250, "EUR"), price)
+
+	// price cursors carry the currency the products are sorted by first.
+	amountOnly := product.Cursor{Sort: "price", Value: "1250", ID: "id"}
+	_, err = product.ListProductsRequest{Sort: "price", Cursor: amountOnly.Encode()}.Filter()
+
+	var bag cerr.Bag
+	require.ErrorAs(t, err, &bag)
+	assert.Equal(t, cerr.Code(product.InvalidCursor), bag.Code)
+}
@@ -1,49 +1,86 @@
 package product
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+)
 
 type GetProductResponse struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Code      string    `json:"code"`
-	Color     string    `json:"color,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Price     float64   `json:"price"`
-	ImageURL  string    `json:"image_url,omitempty"`
-	Type      string    `json:"type"`
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Code      string      `json:"code"`
+	Color     string      `json:"color,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Price     json.Number `json:"price"`
+	Currency  string      `json:"currency"`
+	// OriginalPrice is the price before the promotions of PromotionIDs
+	// discounted it to Price, it is left out when no promotion applies.
+	OriginalPrice *json.Number `json:"original_price,omitempty"`
+	PromotionIDs  []string     `json:"promotion_ids,omitempty"`
+	ImageURL      string       `json:"image_url,omitempty"`
+	Type          string       `json:"type"`
+	DeletedAt     *time.Time   `json:"deleted_at,omitempty"`
+	// Variant is the variant the product was looked up by its SKU.
+	Variant *VariantResponse `json:"variant,omitempty"`
+	// Available is the quantity in stock not reserved by baskets, it is left
+	// out when the stock could not be looked up.
+	Available *int `json:"available,omitempty"`
+	// Images are the uploaded images of the product in their order, ImageURL
+	// is the url of the first one unless the product has an image url set.
+	Images []ImageResponse `json:"images,omitempty"`
 }
 
 type GetProductsResponse struct {
-	Products []GetProductResponse `json:"products"`
+	Products   []GetProductResponse `json:"products"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+	Missing    []string             `json:"missing,omitempty"`
 }
 
-func NewGetProductResponse(product *Product) *GetProductResponse {
+// NewGetProductResponse resolves the price of the product in effect at the
+// given time among its Schedule, discounted by its Discount.
+func NewGetProductResponse(product *Product, at time.Time) *GetProductResponse {
 	if product == nil {
 		return nil
 	}
 
+	price := product.PriceAt(at)
+	var originalPrice *json.Number
+	var promotionIDs []string
+	if product.Discount != nil {
+		original := decimal(price)
+		originalPrice, promotionIDs = &original, product.Discount.PromotionIDs
+		price = product.Discount.Price
+	}
+
 	return &GetProductResponse{
-		ID:        product.ID,
-		Name:      product.Name,
-		Code:      product.Code,
-		Color:     product.Color,
-		CreatedAt: product.CreatedAt,
-		UpdatedAt: product.UpdatedAt,
-		Price:     product.SellingPrice,
-		ImageURL:  product.ImageURL,
-		Type:      string(product.Type),
+		ID:            product.ID,
+		Name:          product.Name,
+		Code:          product.Code,
+		Color:         product.Color,
+		CreatedAt:     product.CreatedAt,
+		UpdatedAt:     product.UpdatedAt,
+		Price:         decimal(price),
+		Currency:      string(price.Currency),
+		OriginalPrice: originalPrice,
+		PromotionIDs:  promotionIDs,
+		ImageURL:      product.ImageURL,
+		Type:          string(product.Type),
+		DeletedAt:     product.DeletedAt,
 	}
 }
 
-func NewGetProductsResponse(products []Product) *GetProductsResponse {
+func NewGetProductsResponse(products []Product, at time.Time) *GetProductsResponse {
 	if products == nil {
 		return nil
 	}
 
-	var productResponses []GetProductResponse
-	for i, _ := range products {
-		productResponses = append(productResponses, *NewGetProductResponse(&products[i]))
+	productResponses := make([]GetProductResponse, 0, len(products))
+	for i := range products {
+		productResponses = append(productResponses, *NewGetProductResponse(&products[i], at))
 	}
 
 	return &GetProductsResponse{
@@ -52,19 +89,21 @@ func NewGetProductsResponse(products []Product) *GetProductsResponse {
 }
 
 type CreateProductResponse struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Code         string    `json:"code"`
-	Color        string    `json:"color,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	BuyingPrice  float64   `json:"buying_price"`
-	SellingPrice float64   `json:"selling_price"`
-	ImageURL     string    `json:"image_url,omitempty"`
-	Type         string    `json:"type"`
-	Provider     string    `json:"provider"`
-	Creator      string    `json:"creator"`
-	Distributor  string    `json:"distributor"`
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	Code         string          `json:"code"`
+	Color        string          `json:"color,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Currency     string          `json:"currency"`
+	BuyingPrice  json.Number     `json:"buying_price"`
+	SellingPrice json.Number     `json:"selling_price"`
+	Prices       []PriceResponse `json:"prices,omitempty"`
+	ImageURL     string          `json:"image_url,omitempty"`
+	Type         string          `json:"type"`
+	Provider     string          `json:"provider"`
+	Creator      string          `json:"creator"`
+	Distributor  string          `json:"distributor"`
 }
 
 func NewCreateProductResponse(product *Product) *CreateProductResponse {
@@ -79,8 +118,10 @@ func NewCreateProductResponse(product *Product) *CreateProductResponse {
 		Color:        product.Color,
 		CreatedAt:    product.CreatedAt,
 		UpdatedAt:    product.UpdatedAt,
-		BuyingPrice:  product.BuyingPrice,
-		SellingPrice: product.SellingPrice,
+		Currency:     string(product.Currency()),
+		BuyingPrice:  decimal(product.BuyingPrice),
+		SellingPrice: decimal(product.SellingPrice),
+		Prices:       newPriceResponses(product.Prices),
 		ImageURL:     product.ImageURL,
 		Type:         string(product.Type),
 		Provider:     product.Provider,
@@ -88,3 +129,197 @@ func NewCreateProductResponse(product *Product) *CreateProductResponse {
 		Distributor:  product.Distributor,
 	}
 }
+
+// PriceResponse is an entry of the price list of a product.
+type PriceResponse struct {
+	Currency string      `json:"currency"`
+	Amount   json.Number `json:"amount"`
+}
+
+func newPriceResponses(prices []money.Money) []PriceResponse {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	responses := make([]PriceResponse, 0, len(prices))
+	for _, price := range prices {
+		responses = append(responses,
+			PriceResponse{Currency: string(price.Currency), Amount: decimal(price)})
+	}
+
+	return responses
+}
+
+// decimal renders the amount as an exact json number in major units.
+func decimal(m money.Money) json.Number {
+	return json.Number(m.Decimal())
+}
+
+type CreateProductsResponse struct {
+	Created int                        `json:"created"`
+	Failed  int                        `json:"failed"`
+	Results []CreateProductsItemResult `json:"results"`
+}
+
+// CreateProductsItemResult is the outcome of the product at Index of a batch
+// create request, either the id of the created product or the error.
+type CreateProductsItemResult struct {
+	Index int       `json:"index"`
+	ID    string    `json:"id,omitempty"`
+	Error *cerr.Bag `json:"error,omitempty"`
+}
+
+type UpdateProductResponse CreateProductResponse
+
+func NewUpdateProductResponse(product *Product) *UpdateProductResponse {
+	return (*UpdateProductResponse)(NewCreateProductResponse(product))
+}
+
+type VariantResponse struct {
+	ID        string       `json:"id"`
+	ProductID string       `json:"product_id"`
+	SKU       string       `json:"sku"`
+	Size      string       `json:"size,omitempty"`
+	Color     string       `json:"color,omitempty"`
+	Barcode   string       `json:"barcode,omitempty"`
+	Price     *json.Number `json:"price,omitempty"`
+	// Currency is the currency of Price, it stays the one the price is set
+	// in when the currency of the product changes.
+	Currency  string    `json:"currency,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewVariantResponse(variant *Variant) *VariantResponse {
+	if variant == nil {
+		return nil
+	}
+
+	var price *json.Number
+	var currency string
+	if variant.Price != nil {
+		amount := decimal(*variant.Price)
+		price, currency = &amount, string(variant.Price.Currency)
+	}
+
+	return &VariantResponse{
+		ID:        variant.ID,
+		ProductID: variant.ProductID,
+		SKU:       variant.SKU,
+		Size:      variant.Size,
+		Color:     variant.Color,
+		Barcode:   variant.Barcode,
+		Price:     price,
+		Currency:  currency,
+		CreatedAt: variant.CreatedAt,
+		UpdatedAt: variant.UpdatedAt,
+	}
+}
+
+type GetVariantsResponse struct {
+	Variants []VariantResponse `json:"variants"`
+}
+
+func NewGetVariantsResponse(variants []Variant) *GetVariantsResponse {
+	responses := make([]VariantResponse, 0, len(variants))
+	for i := range variants {
+		responses = append(responses, *NewVariantResponse(&variants[i]))
+	}
+
+	return &GetVariantsResponse{Variants: responses}
+}
+
+type ScheduledPriceResponse struct {
+	ID        string      `json:"id"`
+	Price     json.Number `json:"price"`
+	Currency  string      `json:"currency"`
+	ValidFrom time.Time   `json:"valid_from"`
+	ValidTo   *time.Time  `json:"valid_to,omitempty"`
+	ChangedBy string      `json:"changed_by,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func NewScheduledPriceResponse(price *ScheduledPrice) *ScheduledPriceResponse {
+	if price == nil {
+		return nil
+	}
+
+	return &ScheduledPriceResponse{
+		ID:        price.ID,
+		Price:     decimal(price.Price),
+		Currency:  string(price.Price.Currency),
+		ValidFrom: price.ValidFrom,
+		ValidTo:   price.ValidTo,
+		ChangedBy: price.ChangedBy,
+		CreatedAt: price.CreatedAt,
+	}
+}
+
+type GetScheduledPricesResponse struct {
+	Prices []ScheduledPriceResponse `json:"prices"`
+}
+
+func NewGetScheduledPricesResponse(prices []ScheduledPrice) *GetScheduledPricesResponse {
+	responses := make([]ScheduledPriceResponse, 0, len(prices))
+	for i := range prices {
+		responses = append(responses, *NewScheduledPriceResponse(&prices[i]))
+	}
+
+	return &GetScheduledPricesResponse{Prices: responses}
+}
+
+type ImageResponse struct {
+	ID          string              `json:"id"`
+	URL         string              `json:"url"`
+	ContentType string              `json:"content_type"`
+	Size        int64               `json:"size"`
+	Width       int                 `json:"width"`
+	Height      int                 `json:"height"`
+	Position    int                 `json:"position"`
+	Thumbnails  []ThumbnailResponse `json:"thumbnails,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+type ThumbnailResponse struct {
+	Size int    `json:"size"`
+	URL  string `json:"url"`
+}
+
+// NewImageResponse resolves the urls of the image files by their storage
+// keys with url, see ImageStorage.
+func NewImageResponse(image *Image, url func(key string) string) *ImageResponse {
+	if image == nil {
+		return nil
+	}
+
+	thumbnails := make([]ThumbnailResponse, 0, len(image.ThumbnailSizes))
+	for _, size := range image.ThumbnailSizes {
+		thumbnails = append(thumbnails,
+			ThumbnailResponse{Size: size, URL: url(image.ThumbnailKey(size))})
+	}
+
+	return &ImageResponse{
+		ID:          image.ID,
+		URL:         url(image.Key),
+		ContentType: image.ContentType,
+		Size:        image.Size,
+		Width:       image.Width,
+		Height:      image.Height,
+		Position:    image.Position,
+		Thumbnails:  thumbnails,
+		CreatedAt:   image.CreatedAt,
+	}
+}
+
+type GetImagesResponse struct {
+	Images []ImageResponse `json:"images"`
+}
+
+func NewGetImagesResponse(images []Image, url func(key string) string) *GetImagesResponse {
+	responses := make([]ImageResponse, 0, len(images))
+	for i := range images {
+		responses = append(responses, *NewImageResponse(&images[i], url))
+	}
+
+	return &GetImagesResponse{Images: responses}
+}
@@ -0,0 +1,70 @@
+package product
+
+import (
+	"time"
+
+	"github.com/pact-cdc-example/product-service/pkg/money"
+)
+
+// ScheduledPrice is a selling price of a product valid from ValidFrom until
+// ValidTo, e.g. a sale. The changes of the regular selling price of a product
+// are recorded as scheduled prices without an end, valid until the next
+// change, so the scheduled prices of a product make up its price history.
+type ScheduledPrice struct {
+	ID        string
+	ProductID string
+	Price     money.Money
+	ValidFrom time.Time
+	ValidTo   *time.Time
+	// ChangedBy is the name of the api key holder who changed the price,
+	// empty for anonymous callers and imports.
+	ChangedBy string
+	CreatedAt time.Time
+}
+
+// InEffect reports whether the validity window of the price contains at.
+func (s *ScheduledPrice) InEffect(at time.Time) bool {
+	return !at.Before(s.ValidFrom) && (s.ValidTo == nil || at.Before(*s.ValidTo))
+}
+
+// overrides reports whether s takes precedence over o when both are in
+// effect, the later starting and then the later scheduled price wins.
+func (s *ScheduledPrice) overrides(o *ScheduledPrice) bool {
+	if !s.ValidFrom.Equal(o.ValidFrom) {
+		return s.ValidFrom.After(o.ValidFrom)
+	}
+
+	return s.CreatedAt.After(o.CreatedAt)
+}
+
+// PriceAt returns the selling price in effect at the given time. A scheduled
+// price of the Schedule of the product overrides the regular selling price,
+// which is the one of the price history before the last update of the product
+// and its SellingPrice after. Prices in another currency than the product is
+// priced in, left from before its currency changed, are ignored.
+func (p *Product) PriceAt(at time.Time) money.Money {
+	var scheduled, regular *ScheduledPrice
+	for i := range p.Schedule {
+		price := &p.Schedule[i]
+		if price.Price.Currency != p.Currency() || !price.InEffect(at) {
+			continue
+		}
+
+		if price.ValidTo != nil && (scheduled == nil || price.overrides(scheduled)) {
+			scheduled = price
+		}
+
+		if price.ValidTo == nil && (regular == nil || price.overrides(regular)) {
+			regular = price
+		}
+	}
+
+	switch {
+	case scheduled != nil:
+		return scheduled.Price
+	case regular != nil && at.Before(p.UpdatedAt):
+		return regular.Price
+	}
+
+	return p.SellingPrice
+}
@@ -0,0 +1,65 @@
+package product_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceAt(t *testing.T) {
+	updatedAt := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	saleEnd := time.Date(2026, time.November, 28, 0, 0, 0, 0, time.UTC)
+	p := &product.Product{
+		SellingPrice: money.New(3000, "TRY"),
+		UpdatedAt:    updatedAt,
+		Schedule: []product.ScheduledPrice{
+			{Price: money.New(2000, "TRY"), ValidFrom: updatedAt.AddDate(0, -2, 0)},
+			{Price: money.New(2500, "TRY"), ValidFrom: updatedAt.AddDate(0, -1, 0)},
+			{Price: money.New(3000, "TRY"), ValidFrom: updatedAt},
+			{Price: money.New(1500, "TRY"), ValidFrom: saleEnd.AddDate(0, 0, -1), ValidTo: &saleEnd},
+			{Price: money.New(10, "EUR"), ValidFrom: saleEnd.AddDate(0, 0, -1), ValidTo: &saleEnd},
+		},
+	}
+
+	cases := []struct {
+		at       time.Time
+		expected money.Money
+	}{
+		{updatedAt.AddDate(0, -3, 0), money.New(3000, "TRY")},
+		{updatedAt.AddDate(0, 0, -40), money.New(2000, "TRY")},
+		{updatedAt.AddDate(0, 0, -10), money.New(2500, "TRY")},
+		{updatedAt.AddDate(0, 0, 10), money.New(3000, "TRY")},
+		{saleEnd.Add(-time.Hour), money.New(1500, "TRY")},
+		{saleEnd, money.New(3000, "TRY")},
+	}
+
+	for _, c := range cases {
+		inEffect := *p
+		inEffect.Schedule = nil
+		for _, scheduled := range p.Schedule {
+			if scheduled.InEffect(c.at) {
+				inEffect.Schedule = append(inEffect.Schedule, scheduled)
+			}
+		}
+
+		assert.Equal(t, c.expected, inEffect.PriceAt(c.at), c.at)
+	}
+}
+
+func TestPriceAtIgnoresHistoryAfterLastUpdate(t *testing.T) {
+	updatedAt := time.Now()
+	p := &product.Product{
+		SellingPrice: money.New(3000, "TRY"),
+		UpdatedAt:    updatedAt,
+		Schedule: []product.ScheduledPrice{
+			{Price: money.New(2000, "TRY"), ValidFrom: updatedAt.Add(-time.Hour)},
+		},
+	}
+
+	assert.Equal(t, money.New(2000, "TRY"), p.PriceAt(updatedAt.Add(-time.Minute)))
+	assert.Equal(t, money.New(3000, "TRY"), p.PriceAt(updatedAt.Add(time.Minute)),
+		"the selling price applies when recording its change failed")
+}
@@ -1,43 +1,187 @@
 package product
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/pkg/auth"
 	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/mergepatch"
+	"github.com/pact-cdc-example/product-service/pkg/money"
 	"github.com/sirupsen/logrus"
 )
 
 type Service interface {
-	GetProductByID(ctx context.Context, id string) (*GetProductResponse, error)
+	GetProductByID(ctx context.Context, req GetProductRequest) (*GetProductResponse, error)
+	GetProductByCode(ctx context.Context, code string) (*GetProductResponse, error)
 	GetProductsByIDs(
 		ctx context.Context, req GetProductsByIDsRequest) (*GetProductsResponse, error)
+	ListProducts(ctx context.Context, req ListProductsRequest) (*GetProductsResponse, error)
+	SearchProducts(ctx context.Context, req SearchProductsRequest) (*GetProductsResponse, error)
+	ExportProducts(
+		ctx context.Context, req ExportProductsRequest, filter ListFilter, w io.Writer) error
 	CreateProduct(ctx context.Context, req CreateProductRequest) (*CreateProductResponse, error)
+	CreateProducts(
+		ctx context.Context, req CreateProductsRequest) (*CreateProductsResponse, error)
+	UpdateProduct(
+		ctx context.Context, id string, req UpdateProductRequest) (*UpdateProductResponse, error)
+	PatchProduct(ctx context.Context, id string, patch []byte) (*UpdateProductResponse, error)
+	DeleteProduct(ctx context.Context, id string) error
+	RestoreProduct(ctx context.Context, id string) (*GetProductResponse, error)
+	ListVariants(ctx context.Context, productID string) (*GetVariantsResponse, error)
+	GetVariant(ctx context.Context, productID, id string) (*VariantResponse, error)
+	CreateVariant(
+		ctx context.Context, productID string, req CreateVariantRequest) (*VariantResponse, error)
+	UpdateVariant(ctx context.Context,
+		productID, id string, req UpdateVariantRequest) (*VariantResponse, error)
+	DeleteVariant(ctx context.Context, productID, id string) error
+	ListScheduledPrices(ctx context.Context, productID string) (*GetScheduledPricesResponse, error)
+	SchedulePrice(ctx context.Context,
+		productID string, req SchedulePriceRequest) (*ScheduledPriceResponse, error)
+	ListImages(ctx context.Context, productID string) (*GetImagesResponse, error)
+	UploadImage(
+		ctx context.Context, productID string, req UploadImageRequest) (*ImageResponse, error)
+	ReorderImages(ctx context.Context,
+		productID string, req ReorderImagesRequest) (*GetImagesResponse, error)
+	DeleteImage(ctx context.Context, productID, id string) error
 }
 
 type service struct {
 	logger     *logrus.Logger
 	repository Repository
+	categories CategoryChecker
+	stock      AvailabilityChecker
+	rates      money.RateSource
+	promotions Discounter
+	policy     *PricingPolicy
+	images     ImageStorage
+	thumbnails []int
 }
 
 type NewServiceOpts struct {
 	L *logrus.Logger
 	R Repository
+	// C validates the product types against the categories.
+	C CategoryChecker
+	// A fills in the available stock of the products looked up, optional.
+	A AvailabilityChecker
+	// Rates converts the prices of products to the currencies they have no
+	// listed price in, optional.
+	Rates money.RateSource
+	// D discounts the prices of the products looked up, optional.
+	D Discounter
+	// P bounds the prices of the products created and updated, optional.
+	P *PricingPolicy
+	// I stores the files of the product images, the images are left out of
+	// the products looked up without it.
+	I ImageStorage
+	// ThumbnailSizes are the sizes of the thumbnails of the uploaded images,
+	// the thumbnails fit in squares of these sizes.
+	ThumbnailSizes []int
 }
 
 func NewService(opts *NewServiceOpts) Service {
 	return &service{
 		logger:     opts.L,
 		repository: opts.R,
+		categories: opts.C,
+		stock:      opts.A,
+		rates:      opts.Rates,
+		promotions: opts.D,
+		policy:     opts.P,
+		images:     opts.I,
+		thumbnails: opts.ThumbnailSizes,
 	}
 }
 
-func (s *service) GetProductByID(ctx context.Context, id string) (*GetProductResponse, error) {
-	product, err := s.repository.GetProductByID(ctx, id)
+func (s *service) GetProductByID(
+	ctx context.Context, req GetProductRequest) (*GetProductResponse, error) {
+	product, err := s.repository.GetProductByID(ctx, req.ID, FindOptions{
+		IncludeDeleted: req.IncludeDeleted,
+	})
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		s.logger.WithField("product_id", id).Errorf("could not get product: %v", err)
+		s.logger.WithField("product_id", req.ID).Errorf("could not get product: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	if product == nil {
+		return nil, cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
+	}
+
+	at, _ := req.at(time.Now())
+	if err = s.fillPrices(ctx, at, product); err != nil {
+		return nil, err
+	}
+
+	response := NewGetProductResponse(product, at)
+	if req.Currency != "" {
+		currency, _ := money.ParseCurrency(req.Currency)
+		price, err := s.priceIn(ctx, product, product.PriceAt(at), currency)
+		if err != nil {
+			return nil, err
+		}
+
+		if product.Discount != nil {
+			original := decimal(price)
+			response.OriginalPrice = &original
+			if price, err = s.priceIn(ctx, product, product.Discount.Price, currency); err != nil {
+				return nil, err
+			}
+		}
+
+		response.Price, response.Currency = decimal(price), string(price.Currency)
+	}
+	s.fillAvailability(ctx, response)
+	s.fillImages(ctx, response)
+
+	return response, nil
+}
+
+// priceIn returns the given price of the product in the given currency. The
+// price list holds the regular selling price in other currencies, so it only
+// applies to that, other prices, e.g. sales and discounts, are converted.
+func (s *service) priceIn(ctx context.Context,
+	product *Product, price money.Money, currency money.Currency) (money.Money, error) {
+	if price.Currency == currency {
+		return price, nil
+	}
+
+	if price == product.SellingPrice {
+		if listed, ok := product.ListedPrice(currency); ok {
+			return listed, nil
+		}
+	}
+
+	notAvailable := cerr.Bag{Code: PriceNotAvailable,
+		Message: fmt.Sprintf("Product has no price in %s.", currency)}
+	if s.rates == nil {
+		return money.Money{}, notAvailable
+	}
+
+	converted, err := money.Exchange(ctx, s.rates, price, currency)
+	if errors.Is(err, money.ErrRateNotFound) {
+		return money.Money{}, notAvailable
+	}
+
+	if err != nil {
+		s.logger.WithField("product_id", product.ID).Errorf("could not convert price: %v", err)
+		return money.Money{}, cerr.Processing()
+	}
+
+	return converted, nil
+}
+
+func (s *service) GetProductByCode(ctx context.Context, code string) (*GetProductResponse, error) {
+	product, err := s.repository.GetProductByCode(ctx, code)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.logger.WithField("product_code", code).Errorf("could not get product: %v", err)
 		return nil, cerr.Processing()
 	}
 
@@ -45,41 +189,388 @@ func (s *service) GetProductByID(ctx context.Context, id string) (*GetProductRes
 		return nil, cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
 	}
 
-	return NewGetProductResponse(product), nil
+	now := time.Now()
+	if err = s.fillPrices(ctx, now, product); err != nil {
+		return nil, err
+	}
+
+	response := NewGetProductResponse(product, now)
+	s.fillAvailability(ctx, response)
+	s.fillImages(ctx, response)
+
+	return response, nil
 }
 
+// GetProductsByIDs looks up the products by their ids, and the ids that are
+// not product ids by the SKUs of their variants. Product ids are UUIDs, the
+// missing UUIDs are not looked up as SKUs. The products are returned in the
+// order of the ids, the ones found by a SKU along with the variant.
 func (s *service) GetProductsByIDs(
 	ctx context.Context, req GetProductsByIDsRequest) (*GetProductsResponse, error) {
-	products, err := s.repository.GetProductsByIDs(ctx, req.IDs)
+	opts := FindOptions{IncludeDeleted: req.IncludeDeleted}
+	products, err := s.repository.GetProductsByIDs(ctx, req.IDs, opts)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		s.logger.Errorf("could not get products: %v", err)
 		return nil, cerr.Processing()
 	}
 
-	if products == nil || len(products) != len(req.IDs) {
+	now := time.Now()
+	if err = s.fillProductsPrices(ctx, now, products); err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*GetProductResponse, len(req.IDs))
+	for i := range products {
+		found[products[i].ID] = NewGetProductResponse(&products[i], now)
+	}
+
+	err = s.resolveSKUs(ctx, possibleSKUs(missingIDs(req.IDs, found)), opts, now, found)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetProductsResponse{Products: []GetProductResponse{}}
+	response.Missing = missingIDs(req.IDs, found)
+	if len(response.Missing) > 0 && !req.Partial {
 		return nil, cerr.Bag{Code: OneOrMoreProductsNotFoundErrCode,
-			Message: "At least one of given product ids does not exist."}
+			Message: "At least one of given product ids does not exist.",
+			Details: map[string]interface{}{"missing_ids": response.Missing}}
+	}
+
+	for _, id := range UniqueIDs(req.IDs) {
+		if product, ok := found[id]; ok {
+			response.Products = append(response.Products, *product)
+		}
+	}
+	s.fillProductsAvailability(ctx, response.Products)
+	s.fillProductsImages(ctx, response.Products)
+
+	return response, nil
+}
+
+// resolveSKUs adds the products of the variants with the given SKUs to found,
+// keyed by the SKU, priced at the given time.
+func (s *service) resolveSKUs(ctx context.Context, skus []string,
+	opts FindOptions, at time.Time, found map[string]*GetProductResponse) error {
+	if len(skus) == 0 {
+		return nil
+	}
+
+	variants, err := s.repository.GetVariantsBySKUs(ctx, skus)
+	if err != nil {
+		s.logger.Errorf("could not get variants: %v", err)
+		return cerr.Processing()
+	}
+
+	if len(variants) == 0 {
+		return nil
+	}
+
+	productIDs := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		productIDs = append(productIDs, variant.ProductID)
+	}
+
+	products, err := s.repository.GetProductsByIDs(ctx, productIDs, opts)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.logger.Errorf("could not get products: %v", err)
+		return cerr.Processing()
+	}
+
+	if err = s.fillProductsPrices(ctx, at, products); err != nil {
+		return err
+	}
+
+	byID := make(map[string]*Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	for i := range variants {
+		product, ok := byID[variants[i].ProductID]
+		if !ok {
+			continue
+		}
+
+		response := NewGetProductResponse(product, at)
+		response.Variant = NewVariantResponse(&variants[i])
+		found[variants[i].SKU] = response
+	}
+
+	return nil
+}
+
+// possibleSKUs returns the values that can not be product ids, which are
+// UUIDs.
+func possibleSKUs(values []string) []string {
+	var skus []string
+	for _, value := range values {
+		if _, err := uuid.Parse(value); err != nil {
+			skus = append(skus, value)
+		}
+	}
+
+	return skus
+}
+
+func missingIDs(ids []string, found map[string]*GetProductResponse) []string {
+	var missing []string
+	for _, id := range UniqueIDs(ids) {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	return missing
+}
+
+func (s *service) ListProducts(
+	ctx context.Context, req ListProductsRequest) (*GetProductsResponse, error) {
+	filter, err := req.Filter()
+	if err != nil {
+		return nil, err
+	}
+
+	// one extra product is fetched to find out whether there is a next page.
+	pageSize := filter.Limit
+	filter.Limit++
+
+	products, err := s.repository.ListProducts(ctx, filter)
+	if err != nil {
+		s.logger.Errorf("could not list products: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	var nextCursor string
+	if len(products) > pageSize {
+		products = products[:pageSize]
+		nextCursor = NewCursor(filter.Sort, &products[pageSize-1]).Encode()
+	}
+
+	now := time.Now()
+	if err = s.fillProductsPrices(ctx, now, products); err != nil {
+		return nil, err
+	}
+
+	response := NewGetProductsResponse(products, now)
+	if response == nil {
+		response = &GetProductsResponse{Products: []GetProductResponse{}}
+	}
+	response.NextCursor = nextCursor
+	s.fillProductsAvailability(ctx, response.Products)
+	s.fillProductsImages(ctx, response.Products)
+
+	return response, nil
+}
+
+func (s *service) SearchProducts(
+	ctx context.Context, req SearchProductsRequest) (*GetProductsResponse, error) {
+	products, err := s.repository.SearchProducts(ctx, req.Filter())
+	if err != nil {
+		s.logger.Errorf("could not search products: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	now := time.Now()
+	if err = s.fillProductsPrices(ctx, now, products); err != nil {
+		return nil, err
+	}
+
+	response := NewGetProductsResponse(products, now)
+	if response == nil {
+		response = &GetProductsResponse{Products: []GetProductResponse{}}
+	}
+	s.fillProductsAvailability(ctx, response.Products)
+	s.fillProductsImages(ctx, response.Products)
+
+	return response, nil
+}
+
+// fillPrices looks up the scheduled prices of the products in effect at the
+// given time and the discounts of the promotions running at it, for their
+// price to be resolved at it.
+func (s *service) fillPrices(ctx context.Context, at time.Time, products ...*Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+
+	prices, err := s.repository.GetScheduledPricesAt(ctx, UniqueIDs(ids), at)
+	if err != nil {
+		s.logger.Errorf("could not get scheduled prices: %v", err)
+		return cerr.Processing()
+	}
+
+	schedules := make(map[string][]ScheduledPrice, len(products))
+	for _, price := range prices {
+		schedules[price.ProductID] = append(schedules[price.ProductID], price)
+	}
+
+	for _, product := range products {
+		product.Schedule = schedules[product.ID]
+	}
+
+	return s.fillDiscounts(ctx, at, products)
+}
+
+func (s *service) fillDiscounts(ctx context.Context, at time.Time, products []*Product) error {
+	if s.promotions == nil {
+		return nil
+	}
+
+	items := make([]DiscountItem, 0, len(products))
+	for _, product := range products {
+		items = append(items, DiscountItem{
+			ProductID: product.ID,
+			Type:      string(product.Type),
+			Provider:  product.Provider,
+			Price:     product.PriceAt(at),
+		})
+	}
+
+	discounts, err := s.promotions.Discount(ctx, at, items)
+	if err != nil {
+		s.logger.Errorf("could not apply promotions: %v", err)
+		return cerr.Processing()
+	}
+
+	for i, product := range products {
+		if len(discounts[i].PromotionIDs) > 0 {
+			product.Discount = &discounts[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *service) fillProductsPrices(ctx context.Context, at time.Time, products []Product) error {
+	pointers := make([]*Product, 0, len(products))
+	for i := range products {
+		pointers = append(pointers, &products[i])
+	}
+
+	return s.fillPrices(ctx, at, pointers...)
+}
+
+func (s *service) fillAvailability(ctx context.Context, responses ...*GetProductResponse) {
+	if s.stock == nil || len(responses) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(responses))
+	for _, response := range responses {
+		ids = append(ids, response.ID)
+	}
+
+	// the products are served without their availability rather than not at
+	// all when the stock can not be looked up.
+	available, err := s.stock.Available(ctx, UniqueIDs(ids))
+	if err != nil {
+		s.logger.Errorf("could not get available stock: %v", err)
+		return
+	}
+
+	for _, response := range responses {
+		quantity := available[response.ID]
+		response.Available = &quantity
+	}
+}
+
+// fillImages adds the images of the products to their responses, the main
+// image being the image url of the products that have none set. The products
+// are served without their images when they can not be looked up.
+func (s *service) fillImages(ctx context.Context, responses ...*GetProductResponse) {
+	if s.images == nil || len(responses) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(responses))
+	for _, response := range responses {
+		ids = append(ids, response.ID)
 	}
 
-	return NewGetProductsResponse(products), nil
+	images, err := s.repository.GetImagesByProductIDs(ctx, UniqueIDs(ids))
+	if err != nil {
+		s.logger.Errorf("could not get images: %v", err)
+		return
+	}
+
+	byProduct := make(map[string][]ImageResponse, len(responses))
+	for i := range images {
+		byProduct[images[i].ProductID] = append(byProduct[images[i].ProductID],
+			*NewImageResponse(&images[i], s.imageURL))
+	}
+
+	for _, response := range responses {
+		response.Images = byProduct[response.ID]
+		if response.ImageURL == "" && len(response.Images) > 0 {
+			response.ImageURL = response.Images[0].URL
+		}
+	}
+}
+
+func (s *service) fillProductsAvailability(ctx context.Context, products []GetProductResponse) {
+	responses := make([]*GetProductResponse, 0, len(products))
+	for i := range products {
+		responses = append(responses, &products[i])
+	}
+
+	s.fillAvailability(ctx, responses...)
+}
+
+func (s *service) fillProductsImages(ctx context.Context, products []GetProductResponse) {
+	responses := make([]*GetProductResponse, 0, len(products))
+	for i := range products {
+		responses = append(responses, &products[i])
+	}
+
+	s.fillImages(ctx, responses...)
+}
+
+// ExportProducts writes every product matching filter, the decoded filter of
+// the request, to w in the requested format. Products are streamed from the
+// repository, so the export is not bounded by the listing limit. The errors
+// are returned as they are, the response is already started by then.
+func (s *service) ExportProducts(
+	ctx context.Context, req ExportProductsRequest, filter ListFilter, w io.Writer) error {
+	writer := newExportWriter(w, req.Format, req.IncludeInternal)
+	if err := s.repository.ExportProducts(ctx, filter, writer.Write); err != nil {
+		return err
+	}
+
+	return writer.Flush()
 }
 
 func (s *service) CreateProduct(
 	ctx context.Context, req CreateProductRequest) (*CreateProductResponse, error) {
-	productID := uuid.New().String()
-	product, err := s.repository.CreateProduct(ctx, &Product{
-		ID:           productID,
-		Name:         req.Name,
-		Code:         req.Code,
-		Color:        req.Color,
-		BuyingPrice:  req.BuyingPrice,
-		SellingPrice: req.SellingPrice,
-		ImageURL:     req.ImageURL,
-		Type:         ProductType(req.Type),
-		Provider:     req.Provider,
-		Creator:      req.Creator,
-		Distributor:  req.Distributor,
-	})
+	knownTypes, err := s.knownTypes(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = req.Validate(knownTypes); err != nil {
+		return nil, err
+	}
+
+	product := NewProduct(uuid.New().String(), req)
+	if err = s.checkPricing(ctx, req.OverridePricingPolicy, product.ID,
+		s.policy.Check(product, nil)); err != nil {
+		return nil, err
+	}
+
+	product, err = s.repository.CreateProduct(ctx, product, auth.NameFrom(ctx))
+	if errors.Is(err, ErrProductCodeAlreadyExists) {
+		return nil, productCodeAlreadyExists()
+	}
+
+	if errors.Is(err, ErrProductTypeNotFound) {
+		return nil, productTypeNotFound()
+	}
+
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		s.logger.Errorf("could not create product: %v", err)
 		return nil, cerr.Processing()
@@ -87,3 +578,673 @@ func (s *service) CreateProduct(
 
 	return NewCreateProductResponse(product), nil
 }
+
+// CreateProducts validates every product of the batch and reports the outcome
+// per product. Atomic batches are inserted in a single transaction and fail
+// as a whole when any of the products is invalid.
+func (s *service) CreateProducts(
+	ctx context.Context, req CreateProductsRequest) (*CreateProductsResponse, error) {
+	knownTypes, err := s.knownTypes(ctx, req.Products...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CreateProductsItemResult, len(req.Products))
+	products := make([]*Product, 0, len(req.Products))
+	indexes := make([]int, 0, len(req.Products))
+	codes := make(map[string]struct{}, len(req.Products))
+
+	for i, productReq := range req.Products {
+		results[i].Index = i
+
+		product := NewProduct(uuid.New().String(), productReq)
+		err := productReq.Validate(knownTypes)
+		if _, ok := codes[productReq.Code]; ok && err == nil {
+			err = productCodeAlreadyExists()
+		}
+
+		if err == nil {
+			err = s.checkPricing(ctx, productReq.OverridePricingPolicy, product.ID,
+				s.policy.Check(product, nil))
+		}
+
+		var bag cerr.Bag
+		if errors.As(err, &bag) {
+			results[i].Error = &bag
+			continue
+		}
+
+		codes[productReq.Code] = struct{}{}
+		products = append(products, product)
+		indexes = append(indexes, i)
+	}
+
+	if req.Atomic {
+		if len(products) != len(req.Products) {
+			return nil, invalidBatch(results)
+		}
+
+		_, err := s.repository.CreateProducts(ctx, products, auth.NameFrom(ctx))
+		// the code is taken by a product outside of the batch, it is reported
+		// on the product of the batch just like the codes repeated in it.
+		var conflict *ProductCodeConflictError
+		if errors.As(err, &conflict) {
+			for i, product := range products {
+				if product.Code == conflict.Code {
+					bag := productCodeAlreadyExists()
+					results[indexes[i]].Error = &bag
+				}
+			}
+
+			return nil, invalidBatch(results)
+		}
+
+		if errors.Is(err, ErrProductCodeAlreadyExists) {
+			return nil, productCodeAlreadyExists()
+		}
+
+		if errors.Is(err, ErrProductTypeNotFound) {
+			return nil, productTypeNotFound()
+		}
+
+		if err != nil {
+			s.logger.Errorf("could not create products: %v", err)
+			return nil, cerr.Processing()
+		}
+
+		for i, product := range products {
+			results[indexes[i]].ID = product.ID
+		}
+
+		return newCreateProductsResponse(results), nil
+	}
+
+	created, err := s.repository.CreateProductsSkippingConflicts(
+		ctx, products, auth.NameFrom(ctx))
+	if err != nil {
+		s.logger.Errorf("could not create products: %v", err)
+	}
+
+	createdIDs := make(map[string]bool, len(created))
+	for _, product := range created {
+		createdIDs[product.ID] = true
+	}
+
+	for i, product := range products {
+		result := &results[indexes[i]]
+		switch {
+		case createdIDs[product.ID]:
+			result.ID = product.ID
+		case err != nil:
+			bag := cerr.Processing()
+			result.Error = &bag
+		default:
+			bag := productCodeAlreadyExists()
+			result.Error = &bag
+		}
+	}
+
+	return newCreateProductsResponse(results), nil
+}
+
+func newCreateProductsResponse(results []CreateProductsItemResult) *CreateProductsResponse {
+	response := &CreateProductsResponse{Results: results}
+	for _, result := range results {
+		if result.Error != nil {
+			response.Failed++
+		} else {
+			response.Created++
+		}
+	}
+
+	return response
+}
+
+// invalidBatch fails an atomic batch, reporting the results of the products
+// failing it.
+func invalidBatch(results []CreateProductsItemResult) cerr.Bag {
+	return cerr.Bag{Code: InvalidBatchCreateRequest,
+		Message: "At least one of given products is invalid, none of them is created.",
+		Details: map[string]interface{}{"results": failedResults(results)}}
+}
+
+func failedResults(results []CreateProductsItemResult) []CreateProductsItemResult {
+	var failed []CreateProductsItemResult
+	for _, result := range results {
+		if result.Error != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+func (s *service) UpdateProduct(
+	ctx context.Context, id string, req UpdateProductRequest) (*UpdateProductResponse, error) {
+	current, err := s.activeProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateProduct(ctx, current, req)
+}
+
+// updateProduct replaces the current state of the product with the request,
+// recording the change of its selling price in the price history.
+func (s *service) updateProduct(ctx context.Context,
+	current *Product, req UpdateProductRequest) (*UpdateProductResponse, error) {
+	id := current.ID
+	knownTypes, err := s.knownTypes(ctx, CreateProductRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = req.Validate(knownTypes); err != nil {
+		return nil, err
+	}
+
+	product := NewProduct(id, CreateProductRequest(req))
+	if err = s.checkPricing(ctx, req.OverridePricingPolicy, product.ID,
+		s.policy.Check(product, current)); err != nil {
+		return nil, err
+	}
+
+	product, err = s.repository.UpdateProduct(ctx, product, auth.NameFrom(ctx))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
+	}
+
+	if errors.Is(err, ErrProductCodeAlreadyExists) {
+		return nil, productCodeAlreadyExists()
+	}
+
+	if errors.Is(err, ErrProductTypeNotFound) {
+		return nil, productTypeNotFound()
+	}
+
+	if err != nil {
+		s.logger.WithField("product_id", id).Errorf("could not update product: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewUpdateProductResponse(product), nil
+}
+
+// PatchProduct applies the given JSON merge patch (RFC 7396) on top of the
+// current state of the product and stores the result as a full update.
+func (s *service) PatchProduct(
+	ctx context.Context, id string, patch []byte) (*UpdateProductResponse, error) {
+	product, err := s.repository.GetProductByID(ctx, id, FindOptions{})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.logger.WithField("product_id", id).Errorf("could not get product: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	if product == nil {
+		return nil, cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
+	}
+
+	current, err := json.Marshal(newUpdateProductRequest(product))
+	if err != nil {
+		s.logger.WithField("product_id", id).Errorf("could not marshal product: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	patched, err := mergepatch.Apply(current, patch)
+	if err != nil {
+		return nil, cerr.BodyParser()
+	}
+
+	var req UpdateProductRequest
+	if err = json.Unmarshal(patched, &req); err != nil {
+		return nil, cerr.BodyParser()
+	}
+
+	return s.updateProduct(ctx, product, req)
+}
+
+// checkPricing enforces the violation of the pricing policy by the prices of
+// the product, if any, unless an admin overrides the policy. Only admins can
+// ask for an override, even when there is no violation.
+func (s *service) checkPricing(
+	ctx context.Context, override bool, productID string, violation error) error {
+	if !override {
+		return violation
+	}
+
+	if auth.RoleFrom(ctx) != auth.RoleAdmin {
+		return cerr.Bag{Code: PricingOverrideNotAllowed,
+			Message: "Only admins can override the pricing policy."}
+	}
+
+	if violation != nil {
+		s.logger.WithField("product_id", productID).
+			Infof("pricing policy is overridden by %s: %v", auth.NameFrom(ctx), violation)
+	}
+
+	return nil
+}
+
+// knownTypes looks up which of the types of the given requests are
+// categories, at once for all of them.
+func (s *service) knownTypes(
+	ctx context.Context, reqs ...CreateProductRequest) (map[string]bool, error) {
+	types := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		if req.Type != "" {
+			types = append(types, req.Type)
+		}
+	}
+
+	if len(types) == 0 {
+		return map[string]bool{}, nil
+	}
+
+	knownTypes, err := s.categories.ExistingCategories(ctx, UniqueIDs(types))
+	if err != nil {
+		s.logger.Errorf("could not check product types: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return knownTypes, nil
+}
+
+func (s *service) DeleteProduct(ctx context.Context, id string) error {
+	err := s.repository.DeleteProduct(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
+	}
+
+	if err != nil {
+		s.logger.WithField("product_id", id).Errorf("could not delete product: %v", err)
+		return cerr.Processing()
+	}
+
+	return nil
+}
+
+func (s *service) RestoreProduct(ctx context.Context, id string) (*GetProductResponse, error) {
+	product, err := s.repository.RestoreProduct(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, cerr.Bag{Code: DeletedProductNotFoundErrCode, Message: "Deleted product not found."}
+	}
+
+	if err != nil {
+		s.logger.WithField("product_id", id).Errorf("could not restore product: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	now := time.Now()
+	if err = s.fillPrices(ctx, now, product); err != nil {
+		return nil, err
+	}
+
+	return NewGetProductResponse(product, now), nil
+}
+
+func (s *service) ListVariants(
+	ctx context.Context, productID string) (*GetVariantsResponse, error) {
+	if err := s.productExists(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	variants, err := s.repository.ListVariants(ctx, productID)
+	if err != nil {
+		s.logger.WithField("product_id", productID).Errorf("could not list variants: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewGetVariantsResponse(variants), nil
+}
+
+func (s *service) GetVariant(
+	ctx context.Context, productID, id string) (*VariantResponse, error) {
+	if err := s.productExists(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.repository.GetVariant(ctx, productID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, variantNotFound()
+	}
+
+	if err != nil {
+		s.logger.WithField("variant_id", id).Errorf("could not get variant: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewVariantResponse(variant), nil
+}
+
+func (s *service) CreateVariant(
+	ctx context.Context, productID string, req CreateVariantRequest) (*VariantResponse, error) {
+	product, err := s.activeProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = req.Validate(product.Currency()); err != nil {
+		return nil, err
+	}
+
+	price := req.price(product.Currency())
+	if err = s.checkVariantPricing(ctx, req.OverridePricingPolicy, product, price); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.repository.CreateVariant(ctx, &Variant{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		SKU:       req.SKU,
+		Size:      req.Size,
+		Color:     req.Color,
+		Barcode:   req.Barcode,
+		Price:     price,
+	})
+	if errors.Is(err, ErrVariantSKUAlreadyExists) {
+		return nil, variantSKUAlreadyExists()
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
+	}
+
+	if err != nil {
+		s.logger.WithField("product_id", productID).Errorf("could not create variant: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewVariantResponse(variant), nil
+}
+
+func (s *service) UpdateVariant(ctx context.Context,
+	productID, id string, req UpdateVariantRequest) (*VariantResponse, error) {
+	product, err := s.activeProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = req.Validate(product.Currency()); err != nil {
+		return nil, err
+	}
+
+	price := CreateVariantRequest(req).price(product.Currency())
+	if err = s.checkVariantPricing(ctx, req.OverridePricingPolicy, product, price); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.repository.UpdateVariant(ctx, &Variant{
+		ID:        id,
+		ProductID: productID,
+		SKU:       req.SKU,
+		Size:      req.Size,
+		Color:     req.Color,
+		Barcode:   req.Barcode,
+		Price:     price,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, variantNotFound()
+	}
+
+	if errors.Is(err, ErrVariantSKUAlreadyExists) {
+		return nil, variantSKUAlreadyExists()
+	}
+
+	if err != nil {
+		s.logger.WithField("variant_id", id).Errorf("could not update variant: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewVariantResponse(variant), nil
+}
+
+// checkVariantPricing enforces the pricing policy on the price a variant
+// overrides the selling price of its product with. The variant price is not a
+// change of the selling price, it is not limited in how much it differs.
+func (s *service) checkVariantPricing(
+	ctx context.Context, override bool, product *Product, price *money.Money) error {
+	var violation error
+	if price != nil {
+		violation = s.policy.CheckPrice(product, "price", *price, nil)
+	}
+
+	return s.checkPricing(ctx, override, product.ID, violation)
+}
+
+func (s *service) DeleteVariant(ctx context.Context, productID, id string) error {
+	if err := s.productExists(ctx, productID); err != nil {
+		return err
+	}
+
+	err := s.repository.DeleteVariant(ctx, productID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return variantNotFound()
+	}
+
+	if err != nil {
+		s.logger.WithField("variant_id", id).Errorf("could not delete variant: %v", err)
+		return cerr.Processing()
+	}
+
+	return nil
+}
+
+func (s *service) ListScheduledPrices(
+	ctx context.Context, productID string) (*GetScheduledPricesResponse, error) {
+	if err := s.productExists(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	prices, err := s.repository.ListScheduledPrices(ctx, productID)
+	if err != nil {
+		s.logger.WithField("product_id", productID).Errorf("could not list prices: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewGetScheduledPricesResponse(prices), nil
+}
+
+func (s *service) SchedulePrice(ctx context.Context,
+	productID string, req SchedulePriceRequest) (*ScheduledPriceResponse, error) {
+	product, err := s.activeProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err = req.Validate(product.Currency(), now); err != nil {
+		return nil, err
+	}
+
+	scheduled := req.scheduledPrice(uuid.New().String(), product, auth.NameFrom(ctx), now)
+	if err = s.checkPricing(ctx, req.OverridePricingPolicy, product.ID,
+		s.policy.CheckPrice(product, "price", scheduled.Price, &product.SellingPrice)); err != nil {
+		return nil, err
+	}
+
+	price, err := s.repository.CreateScheduledPrice(ctx, scheduled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
+	}
+
+	if err != nil {
+		s.logger.WithField("product_id", productID).Errorf("could not schedule price: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewScheduledPriceResponse(price), nil
+}
+
+func (s *service) ListImages(ctx context.Context, productID string) (*GetImagesResponse, error) {
+	if err := s.productExists(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.repository.ListImages(ctx, productID)
+	if err != nil {
+		s.logger.WithField("product_id", productID).Errorf("could not list images: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewGetImagesResponse(images, s.imageURL), nil
+}
+
+// UploadImage stores the image and its thumbnails after the existing images
+// of the product. The files are stored before the image is saved, they are
+// removed again when it can not be.
+func (s *service) UploadImage(
+	ctx context.Context, productID string, req UploadImageRequest) (*ImageResponse, error) {
+	if s.images == nil {
+		s.logger.Errorf("could not upload image: image storage is not configured")
+		return nil, cerr.Processing()
+	}
+
+	if _, err := s.activeProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	contentType, _ := imageContentType(req.Content)
+	image, files, err := newImage(
+		uuid.New().String(), productID, contentType, req.Content, s.thumbnails)
+	if err != nil {
+		s.logger.WithField("product_id", productID).Infof("could not decode image: %v", err)
+		return nil, cerr.Bag{Code: InvalidImage, Message: "Image file could not be decoded."}
+	}
+
+	stored := make([]string, 0, len(files))
+	for _, file := range files {
+		err = s.images.Put(ctx, file.key, bytes.NewReader(file.content), file.contentType)
+		if err != nil {
+			s.logger.WithField("product_id", productID).Errorf("could not store image: %v", err)
+			s.deleteImageFiles(ctx, stored)
+			return nil, cerr.Processing()
+		}
+		stored = append(stored, file.key)
+	}
+
+	created, err := s.repository.CreateImage(ctx, image)
+	if err != nil {
+		s.deleteImageFiles(ctx, image.Keys())
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
+	}
+
+	if err != nil {
+		s.logger.WithField("product_id", productID).Errorf("could not create image: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewImageResponse(created, s.imageURL), nil
+}
+
+// ReorderImages reorders the images of the product, the request must list
+// every one of them.
+func (s *service) ReorderImages(ctx context.Context,
+	productID string, req ReorderImagesRequest) (*GetImagesResponse, error) {
+	if err := s.productExists(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.repository.ListImages(ctx, productID)
+	if err != nil {
+		s.logger.WithField("product_id", productID).Errorf("could not list images: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	requested := make(map[string]bool, len(req.ImageIDs))
+	for _, id := range req.ImageIDs {
+		requested[id] = true
+	}
+
+	for _, image := range images {
+		if !requested[image.ID] {
+			return nil, invalidImageOrder()
+		}
+	}
+
+	if len(images) != len(req.ImageIDs) {
+		return nil, invalidImageOrder()
+	}
+
+	if err = s.repository.ReorderImages(ctx, productID, req.ImageIDs); err != nil {
+		s.logger.WithField("product_id", productID).Errorf("could not reorder images: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return s.ListImages(ctx, productID)
+}
+
+// DeleteImage deletes the image, and then its files. A failure to delete the
+// files only leaves them unreferenced, so it is only logged.
+func (s *service) DeleteImage(ctx context.Context, productID, id string) error {
+	if s.images == nil {
+		s.logger.Errorf("could not delete image: image storage is not configured")
+		return cerr.Processing()
+	}
+
+	if err := s.productExists(ctx, productID); err != nil {
+		return err
+	}
+
+	image, err := s.repository.GetImage(ctx, productID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return imageNotFound()
+	}
+
+	if err != nil {
+		s.logger.WithField("image_id", id).Errorf("could not get image: %v", err)
+		return cerr.Processing()
+	}
+
+	err = s.repository.DeleteImage(ctx, productID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return imageNotFound()
+	}
+
+	if err != nil {
+		s.logger.WithField("image_id", id).Errorf("could not delete image: %v", err)
+		return cerr.Processing()
+	}
+
+	s.deleteImageFiles(ctx, image.Keys())
+	return nil
+}
+
+func (s *service) deleteImageFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.images.Delete(ctx, key); err != nil {
+			s.logger.WithField("key", key).Errorf("could not delete image file: %v", err)
+		}
+	}
+}
+
+func (s *service) imageURL(key string) string {
+	if s.images == nil {
+		return ""
+	}
+
+	return s.images.URL(key)
+}
+
+// productExists fails with a not found bag unless the product exists and is
+// not deleted, variants are only reachable through their product.
+func (s *service) productExists(ctx context.Context, productID string) error {
+	_, err := s.activeProduct(ctx, productID)
+	return err
+}
+
+func (s *service) activeProduct(ctx context.Context, productID string) (*Product, error) {
+	product, err := s.repository.GetProductByID(ctx, productID, FindOptions{})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.logger.WithField("product_id", productID).Errorf("could not get product: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	if product == nil {
+		return nil, cerr.Bag{Code: ProductNotFoundErrCode, Message: "Product not found."}
+	}
+
+	return product, nil
+}
@@ -0,0 +1,359 @@
+package product_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/persistence"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/pkg/auth"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateProduct(t *testing.T) {
+	s, repository := newService(t)
+	id := createProduct(t, s)
+
+	response, err := s.UpdateProduct(context.Background(), id, product.UpdateProductRequest{
+		Name:         "Trail Runner",
+		Code:         "RUN-1",
+		Type:         "shoes",
+		BuyingPrice:  "50",
+		SellingPrice: "120.50",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Trail Runner", response.Name)
+	assert.Empty(t, response.Color)
+
+	updated, err := repository.GetProductByID(context.Background(), id, product.FindOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Trail Runner", updated.Name)
+	assert.Equal(t, money.New(12050, product.DefaultCurrency), updated.SellingPrice)
+}
+
+func TestUpdateProductErrors(t *testing.T) {
+	s, _ := newService(t)
+	id := createProduct(t, s)
+
+	_, err := s.UpdateProduct(context.Background(), "missing", product.UpdateProductRequest{
+		Name: "Runner", Code: "RUN-1", Type: "shoes"})
+	assertBagCode(t, product.ProductNotFoundErrCode, err)
+
+	_, err = s.UpdateProduct(context.Background(), id, product.UpdateProductRequest{
+		Code: "RUN-1", Type: "shoes"})
+	assertBagCode(t, product.InvalidProductRequest, err)
+}
+
+func TestPatchProduct(t *testing.T) {
+	s, repository := newService(t)
+	id := createProduct(t, s)
+
+	response, err := s.PatchProduct(context.Background(), id,
+		[]byte(`{"name":"Trail Runner","color":null,"prices":[{"currency":"EUR","amount":"9.90"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Trail Runner", response.Name)
+	assert.Equal(t, "RUN-1", response.Code)
+	assert.Empty(t, response.Color)
+
+	patched, err := repository.GetProductByID(context.Background(), id, product.FindOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, money.New(10000, product.DefaultCurrency), patched.SellingPrice)
+	assert.Equal(t, []money.Money{money.New(990, "EUR")}, patched.Prices)
+}
+
+func TestPatchProductErrors(t *testing.T) {
+	s, _ := newService(t)
+	id := createProduct(t, s)
+
+	cases := []struct {
+		name     string
+		id       string
+		patch    string
+		expected cerr.Code
+	}{
+		{"missing product", "missing", `{"name":"Runner"}`, product.ProductNotFoundErrCode},
+		{"invalid json", id, `{"name":`, cerr.BodyParserErrCode},
+		{"non-object patch", id, `["name"]`, cerr.BodyParserErrCode},
+		{"mistyped field", id, `{"name":1}`, cerr.BodyParserErrCode},
+		{"deleted required field", id, `{"name":null}`, product.InvalidProductRequest},
+		{"too precise price", id, `{"selling_price":"1.234"}`, product.InvalidProductRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := s.PatchProduct(context.Background(), c.id, []byte(c.patch))
+			assertBagCode(t, c.expected, err)
+		})
+	}
+}
+
+func TestPriceHistory(t *testing.T) {
+	s, _ := newService(t)
+	id := createProduct(t, s)
+
+	_, err := s.PatchProduct(context.Background(), id, []byte(`{"color":"blue"}`))
+	require.NoError(t, err)
+	_, err = s.PatchProduct(context.Background(), id, []byte(`{"selling_price":"120"}`))
+	require.NoError(t, err)
+
+	history, err := s.ListScheduledPrices(context.Background(), id)
+	require.NoError(t, err)
+	require.Len(t, history.Prices, 2, "the unchanged price is not recorded again")
+	assert.Equal(t, "120.00", history.Prices[0].Price.String())
+	assert.Equal(t, "100.00", history.Prices[1].Price.String())
+}
+
+func TestPricingPolicyOverride(t *testing.T) {
+	policy, err := product.NewPricingPolicy(&product.NewPricingPolicyOpts{
+		DefaultMinMarginPercent: "20",
+		MaxChangePercent:        "30",
+	})
+	require.NoError(t, err)
+
+	s, _, hook := newServiceWithPolicy(t, policy)
+	id := createProduct(t, s)
+
+	user := auth.NewContext(context.Background(), auth.Principal{Name: "joe"})
+	admin := auth.NewContext(context.Background(),
+		auth.Principal{Name: "root", Role: auth.RoleAdmin})
+	validTo := time.Now().Add(24 * time.Hour)
+	// 55 is below the minimum margin over the buying price of 50.
+	price := json.Number("55")
+
+	requests := []struct {
+		name string
+		send func(ctx context.Context, override bool) error
+	}{
+		{"create product", func(ctx context.Context, override bool) error {
+			req := newProductRequest("RUN-2")
+			req.SellingPrice, req.OverridePricingPolicy = price, override
+			_, err := s.CreateProduct(ctx, req)
+			return err
+		}},
+		{"update product", func(ctx context.Context, override bool) error {
+			_, err := s.UpdateProduct(ctx, id, product.UpdateProductRequest{
+				Name: "Runner", Code: "RUN-1", Type: "shoes", BuyingPrice: "50",
+				SellingPrice: price, OverridePricingPolicy: override})
+			return err
+		}},
+		{"schedule price", func(ctx context.Context, override bool) error {
+			_, err := s.SchedulePrice(ctx, id, product.SchedulePriceRequest{
+				Price: price, ValidTo: &validTo, OverridePricingPolicy: override})
+			return err
+		}},
+		{"create variant", func(ctx context.Context, override bool) error {
+			_, err := s.CreateVariant(ctx, id, product.CreateVariantRequest{
+				SKU: "RUN-1-42", Price: &price, OverridePricingPolicy: override})
+			return err
+		}},
+	}
+
+	for _, r := range requests {
+		t.Run(r.name, func(t *testing.T) {
+			hook.Reset()
+
+			err := r.send(user, false)
+			assertBagCode(t, product.PricingPolicyViolation, err)
+
+			err = r.send(user, true)
+			assertBagCode(t, product.PricingOverrideNotAllowed, err)
+			assert.Equal(t, http.StatusForbidden, err.(cerr.Bag).Status())
+
+			require.NoError(t, r.send(admin, true))
+			require.NotNil(t, hook.LastEntry())
+			assert.Equal(t, logrus.InfoLevel, hook.LastEntry().Level)
+			assert.Contains(t, hook.LastEntry().Message, "pricing policy is overridden by root")
+		})
+	}
+}
+
+func TestVariantPriceKeepsCurrency(t *testing.T) {
+	s, _ := newService(t)
+	id := createProduct(t, s)
+
+	price := json.Number("45")
+	variant, err := s.CreateVariant(context.Background(), id, product.CreateVariantRequest{
+		SKU: "RUN-1-42", Price: &price})
+	require.NoError(t, err)
+	assert.Equal(t, string(product.DefaultCurrency), variant.Currency)
+
+	_, err = s.PatchProduct(context.Background(), id,
+		[]byte(`{"currency":"EUR","buying_price":"5","selling_price":"10"}`))
+	require.NoError(t, err)
+
+	found, err := s.GetVariant(context.Background(), id, variant.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.Price)
+	assert.Equal(t, "45.00", found.Price.String())
+	assert.Equal(t, string(product.DefaultCurrency), found.Currency)
+}
+
+func TestGetProductsByIDsResolvesSKUs(t *testing.T) {
+	repository := &skuCountingRepository{Repository: persistence.NewMemoryRepository()}
+	logger, _ := test.NewNullLogger()
+	s := product.NewService(&product.NewServiceOpts{
+		L: logger,
+		R: repository,
+		C: persistence.NewMemoryCategoryRepository(repository),
+	})
+	id := createProduct(t, s)
+	_, err := s.CreateVariant(context.Background(), id, product.CreateVariantRequest{SKU: "RUN-1-42"})
+	require.NoError(t, err)
+
+	missing := uuid.New().String()
+	response, err := s.GetProductsByIDs(context.Background(), product.GetProductsByIDsRequest{
+		IDs: []string{missing}, Partial: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{missing}, response.Missing)
+	assert.Zero(t, repository.skuLookups, "missing product ids are not looked up as SKUs")
+
+	response, err = s.GetProductsByIDs(context.Background(), product.GetProductsByIDsRequest{
+		IDs: []string{"RUN-1-42", id, missing, "RUN-1-43"}, Partial: true})
+	require.NoError(t, err)
+	require.Len(t, response.Products, 2)
+	require.NotNil(t, response.Products[0].Variant)
+	assert.Equal(t, "RUN-1-42", response.Products[0].Variant.SKU)
+	assert.Equal(t, id, response.Products[1].ID)
+	assert.Equal(t, []string{missing, "RUN-1-43"}, response.Missing)
+	assert.Equal(t, 1, repository.skuLookups)
+}
+
+// skuCountingRepository counts the lookups of variants by their SKUs.
+type skuCountingRepository struct {
+	product.Repository
+	skuLookups int
+}
+
+func (r *skuCountingRepository) GetVariantsBySKUs(
+	ctx context.Context, skus []string) ([]product.Variant, error) {
+	r.skuLookups++
+	return r.Repository.GetVariantsBySKUs(ctx, skus)
+}
+
+func TestCreateProductsAtomic(t *testing.T) {
+	s, repository := newService(t)
+	createProduct(t, s)
+
+	// RUN-1 is taken by a product outside of the batch.
+	_, err := s.CreateProducts(context.Background(), product.CreateProductsRequest{
+		Atomic:   true,
+		Products: []product.CreateProductRequest{newProductRequest("RUN-2"), newProductRequest("RUN-1")},
+	})
+
+	var bag cerr.Bag
+	require.ErrorAs(t, err, &bag)
+	assert.Equal(t, cerr.Code(product.InvalidBatchCreateRequest), bag.Code)
+
+	results, ok := bag.Details["results"].([]product.CreateProductsItemResult)
+	require.True(t, ok)
+	require.Len(t, results, 1)
+	assert.Equal(t, 1, results[0].Index)
+	assert.Equal(t, cerr.Code(product.ProductCodeAlreadyExists), results[0].Error.Code)
+
+	_, err = repository.GetProductByCode(context.Background(), "RUN-2")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateProductsNonAtomic(t *testing.T) {
+	s, repository := newService(t)
+	createProduct(t, s)
+
+	invalid := newProductRequest("RUN-4")
+	invalid.Name = ""
+
+	response, err := s.CreateProducts(context.Background(), product.CreateProductsRequest{
+		Products: []product.CreateProductRequest{
+			newProductRequest("RUN-2"),
+			newProductRequest("RUN-1"),
+			newProductRequest("RUN-2"),
+			invalid,
+			newProductRequest("RUN-3"),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 3, response.Failed)
+
+	expected := []cerr.Code{0, product.ProductCodeAlreadyExists,
+		product.ProductCodeAlreadyExists, product.InvalidProductRequest, 0}
+	for i, result := range response.Results {
+		assert.Equal(t, i, result.Index)
+		if expected[i] == 0 {
+			require.Nil(t, result.Error)
+			created, err := repository.GetProductByID(
+				context.Background(), result.ID, product.FindOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, result.ID, created.ID)
+			continue
+		}
+
+		require.NotNil(t, result.Error)
+		assert.Equal(t, expected[i], result.Error.Code)
+		assert.Empty(t, result.ID)
+	}
+}
+
+// newService returns a product service over in-memory repositories, seeded
+// with the root categories.
+func newService(t *testing.T) (product.Service, product.Repository) {
+	t.Helper()
+
+	s, repository, _ := newServiceWithPolicy(t, nil)
+	return s, repository
+}
+
+// newServiceWithPolicy returns a product service like newService enforcing
+// the pricing policy, along with the hook of its logger.
+func newServiceWithPolicy(t *testing.T,
+	policy *product.PricingPolicy) (product.Service, product.Repository, *test.Hook) {
+	t.Helper()
+
+	logger, hook := test.NewNullLogger()
+	repository := persistence.NewMemoryRepository()
+	return product.NewService(&product.NewServiceOpts{
+		L: logger,
+		R: repository,
+		C: persistence.NewMemoryCategoryRepository(repository),
+		P: policy,
+	}), repository, hook
+}
+
+func createProduct(t *testing.T, s product.Service) string {
+	t.Helper()
+
+	response, err := s.CreateProduct(context.Background(), product.CreateProductRequest{
+		Name:         "Runner",
+		Code:         "RUN-1",
+		Color:        "red",
+		Type:         "shoes",
+		BuyingPrice:  "50",
+		SellingPrice: "100",
+	})
+	require.NoError(t, err)
+
+	return response.ID
+}
+
+func newProductRequest(code string) product.CreateProductRequest {
+	return product.CreateProductRequest{
+		Name: "Runner", Code: code, Type: "shoes", BuyingPrice: "50", SellingPrice: "100",
+	}
+}
+
+func assertBagCode(t *testing.T, expected cerr.Code, err error) {
+	t.Helper()
+
+	var bag cerr.Bag
+	require.ErrorAs(t, err, &bag)
+	assert.Equal(t, expected, bag.Code)
+}
@@ -0,0 +1,172 @@
+// Package engine evaluates promotion rules on product prices. It is pure, the
+// same rules, product and time always yield the same price, so it is tested
+// without a server or a database.
+package engine
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/pkg/money"
+)
+
+type Kind string
+
+const (
+	// Percentage rules take Percentage percent off the price.
+	Percentage Kind = "percentage"
+	// FixedAmount rules take Amount off the prices in its currency, they do
+	// not apply to products priced in other currencies.
+	FixedAmount Kind = "fixed_amount"
+)
+
+// Rule is a discount on the products in its scope while it runs. Empty scope
+// lists match every product, the non empty ones must all match. Product types
+// match exactly, a rule of a category does not apply to its subcategories.
+type Rule struct {
+	ID         string
+	Kind       Kind
+	Percentage *big.Rat
+	Amount     money.Money
+	// Stackable rules apply together, see Evaluate.
+	Stackable    bool
+	ProductIDs   []string
+	ProductTypes []string
+	Providers    []string
+	// StartsAt and EndsAt bound when the rule runs, the rule runs from
+	// StartsAt on until before EndsAt. Nil bounds are open.
+	StartsAt *time.Time
+	EndsAt   *time.Time
+}
+
+// Runs reports whether the rule runs at the given time.
+func (r *Rule) Runs(at time.Time) bool {
+	return (r.StartsAt == nil || !at.Before(*r.StartsAt)) &&
+		(r.EndsAt == nil || at.Before(*r.EndsAt))
+}
+
+// Matches reports whether the product is in the scope of the rule.
+func (r *Rule) Matches(item Item) bool {
+	return matches(r.ProductIDs, item.ProductID) &&
+		matches(r.ProductTypes, item.ProductType) &&
+		matches(r.Providers, item.Provider)
+}
+
+func matches(scope []string, value string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+
+	for _, candidate := range scope {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// apply returns the price with the discount of the rule taken off, never
+// below zero. It reports false when the rule does not apply to the price.
+func (r *Rule) apply(price money.Money) (money.Money, bool) {
+	var discounted money.Money
+	switch r.Kind {
+	case Percentage:
+		if r.Percentage == nil {
+			return price, false
+		}
+
+		off := new(big.Rat).Quo(r.Percentage, big.NewRat(100, 1))
+		var err error
+		discounted, err = price.Convert(price.Currency, off.Sub(big.NewRat(1, 1), off))
+		if err != nil {
+			return price, false
+		}
+	case FixedAmount:
+		var err error
+		if discounted, err = price.Sub(r.Amount); err != nil {
+			return price, false
+		}
+	default:
+		return price, false
+	}
+
+	if discounted.IsNegative() {
+		discounted = money.New(0, price.Currency)
+	}
+
+	return discounted, true
+}
+
+// Item is a product to evaluate the rules on.
+type Item struct {
+	ProductID   string
+	ProductType string
+	Provider    string
+	Price       money.Money
+}
+
+// Result is the price of an item after the rules applied, RuleIDs lists the
+// applied rules in the order they applied. An item no rule applies to keeps
+// its price and has no RuleIDs.
+type Result struct {
+	Price   money.Money
+	RuleIDs []string
+}
+
+// Discounted reports whether any rule applied to the item.
+func (r Result) Discounted() bool {
+	return len(r.RuleIDs) > 0
+}
+
+// Evaluate applies the rules running at the given time and matching the item
+// to its price. The stackable rules apply one after the other, percentages
+// before fixed amounts, then in the order of their ids. The other rules are
+// exclusive, the best of them applies alone. Whichever of the stack and the
+// best exclusive rule ends in the lower price wins, the exclusive rule on a
+// tie.
+func Evaluate(rules []Rule, item Item, at time.Time) Result {
+	var stackable, exclusive []*Rule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Runs(at) || !rule.Matches(item) {
+			continue
+		}
+
+		if rule.Stackable {
+			stackable = append(stackable, rule)
+		} else {
+			exclusive = append(exclusive, rule)
+		}
+	}
+
+	best := Result{Price: item.Price}
+	sort.Slice(exclusive, func(i, j int) bool { return exclusive[i].ID < exclusive[j].ID })
+	for _, rule := range exclusive {
+		price, ok := rule.apply(item.Price)
+		if ok && (!best.Discounted() || price.Amount < best.Price.Amount) {
+			best = Result{Price: price, RuleIDs: []string{rule.ID}}
+		}
+	}
+
+	sort.Slice(stackable, func(i, j int) bool {
+		if stackable[i].Kind != stackable[j].Kind {
+			return stackable[i].Kind == Percentage
+		}
+
+		return stackable[i].ID < stackable[j].ID
+	})
+	stacked := Result{Price: item.Price}
+	for _, rule := range stackable {
+		if price, ok := rule.apply(stacked.Price); ok {
+			stacked = Result{Price: price, RuleIDs: append(stacked.RuleIDs, rule.ID)}
+		}
+	}
+
+	if stacked.Discounted() && (!best.Discounted() || stacked.Price.Amount < best.Price.Amount) {
+		return stacked
+	}
+
+	return best
+}
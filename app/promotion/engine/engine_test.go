@@ -0,0 +1,132 @@
+package engine_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/app/promotion/engine"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2026, time.November, 27, 12, 0, 0, 0, time.UTC)
+
+func percentage(id string, value int64) engine.Rule {
+	return engine.Rule{ID: id, Kind: engine.Percentage, Percentage: big.NewRat(value, 1)}
+}
+
+func fixed(id string, amount int64) engine.Rule {
+	return engine.Rule{ID: id, Kind: engine.FixedAmount, Amount: money.New(amount, "TRY")}
+}
+
+func stackable(r engine.Rule) engine.Rule {
+	r.Stackable = true
+	return r
+}
+
+func item(amount int64) engine.Item {
+	return engine.Item{ProductID: "p1", ProductType: "shoes", Provider: "acme",
+		Price: money.New(amount, "TRY")}
+}
+
+func TestEvaluateWithoutRules(t *testing.T) {
+	result := engine.Evaluate(nil, item(1000), now)
+
+	assert.Equal(t, money.New(1000, "TRY"), result.Price)
+	assert.False(t, result.Discounted())
+}
+
+func TestEvaluatePercentageRoundsHalfAwayFromZero(t *testing.T) {
+	rule := engine.Rule{ID: "a", Kind: engine.Percentage, Percentage: big.NewRat(25, 2)}
+
+	result := engine.Evaluate([]engine.Rule{rule}, item(1999), now)
+
+	assert.Equal(t, money.New(1749, "TRY"), result.Price, "19.99 - 12.5% = 17.49125")
+	assert.Equal(t, []string{"a"}, result.RuleIDs)
+}
+
+func TestEvaluateScope(t *testing.T) {
+	byType := percentage("type", 10)
+	byType.ProductTypes = []string{"bag", "shoes"}
+	byProvider := percentage("provider", 20)
+	byProvider.Providers = []string{"other"}
+	byProduct := percentage("product", 30)
+	byProduct.ProductIDs = []string{"p1"}
+	byProduct.ProductTypes = []string{"bag"}
+
+	result := engine.Evaluate([]engine.Rule{byType, byProvider, byProduct}, item(1000), now)
+
+	assert.Equal(t, []string{"type"}, result.RuleIDs)
+	assert.Equal(t, money.New(900, "TRY"), result.Price)
+}
+
+func TestEvaluateDateRange(t *testing.T) {
+	start, end, later := now.Add(-time.Hour), now, now.Add(time.Hour)
+	ended := percentage("ended", 50)
+	ended.StartsAt, ended.EndsAt = &start, &end
+	upcoming := percentage("upcoming", 50)
+	upcoming.StartsAt = &later
+	running := percentage("running", 10)
+	running.StartsAt = &start
+
+	result := engine.Evaluate([]engine.Rule{ended, upcoming, running}, item(1000), now)
+	assert.Equal(t, []string{"running"}, result.RuleIDs)
+
+	result = engine.Evaluate([]engine.Rule{ended, upcoming, running}, item(1000), start)
+	assert.Equal(t, []string{"ended"}, result.RuleIDs)
+}
+
+func TestEvaluateBestExclusiveRuleApplies(t *testing.T) {
+	rules := []engine.Rule{percentage("b", 10), fixed("c", 300), percentage("a", 30)}
+
+	result := engine.Evaluate(rules, item(1000), now)
+
+	assert.Equal(t, money.New(700, "TRY"), result.Price)
+	assert.Equal(t, []string{"a"}, result.RuleIDs, "ties go to the lower id")
+}
+
+func TestEvaluateStacksPercentagesBeforeFixedAmounts(t *testing.T) {
+	rules := []engine.Rule{stackable(fixed("a", 100)), stackable(percentage("b", 10)),
+		stackable(percentage("c", 50))}
+
+	result := engine.Evaluate(rules, item(1000), now)
+
+	assert.Equal(t, money.New(350, "TRY"), result.Price)
+	assert.Equal(t, []string{"b", "c", "a"}, result.RuleIDs)
+}
+
+func TestEvaluateStackAgainstExclusiveRule(t *testing.T) {
+	stack := []engine.Rule{stackable(percentage("s1", 10)), stackable(percentage("s2", 10))}
+
+	result := engine.Evaluate(append(stack, percentage("x", 18)), item(1000), now)
+	assert.Equal(t, []string{"s1", "s2"}, result.RuleIDs)
+	assert.Equal(t, money.New(810, "TRY"), result.Price)
+
+	result = engine.Evaluate(append(stack, percentage("x", 20)), item(1000), now)
+	assert.Equal(t, []string{"x"}, result.RuleIDs)
+
+	result = engine.Evaluate(append(stack, fixed("x", 190)), item(1000), now)
+	assert.Equal(t, []string{"x"}, result.RuleIDs, "the exclusive rule wins a tie")
+}
+
+func TestEvaluateFixedAmount(t *testing.T) {
+	result := engine.Evaluate([]engine.Rule{fixed("a", 1500)}, item(1000), now)
+	assert.Equal(t, money.New(0, "TRY"), result.Price, "prices do not go below zero")
+
+	eur := engine.Rule{ID: "eur", Kind: engine.FixedAmount, Amount: money.New(100, "EUR")}
+	result = engine.Evaluate([]engine.Rule{eur}, item(1000), now)
+	assert.False(t, result.Discounted(), "fixed amounts only apply in their currency")
+}
+
+func TestEvaluateIsDeterministic(t *testing.T) {
+	rules := []engine.Rule{stackable(percentage("c", 5)), percentage("b", 15),
+		stackable(fixed("a", 50)), stackable(percentage("d", 5)), fixed("e", 150)}
+	reversed := make([]engine.Rule, len(rules))
+	for i := range rules {
+		reversed[len(rules)-1-i] = rules[i]
+	}
+
+	assert.Equal(t,
+		engine.Evaluate(rules, item(1000), now), engine.Evaluate(reversed, item(1000), now))
+}
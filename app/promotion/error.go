@@ -0,0 +1,30 @@
+package promotion
+
+import (
+	"net/http"
+
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+)
+
+const (
+	PromotionNotFoundErrCode   = 50001
+	InvalidPromotionRequest    = 50002
+	PromotionNameIsRequired    = 50003
+	InvalidPromotionKind       = 50004
+	InvalidPromotionPercentage = 50005
+	InvalidPromotionAmount     = 50006
+	InvalidPromotionPeriod     = 50007
+)
+
+func promotionNotFound() cerr.Bag {
+	return cerr.Bag{Code: PromotionNotFoundErrCode, Message: "Promotion not found."}
+}
+
+func init() {
+	cerr.RegisterStatus(http.StatusNotFound,
+		PromotionNotFoundErrCode,
+	)
+	cerr.RegisterStatus(http.StatusUnprocessableEntity,
+		InvalidPromotionRequest,
+	)
+}
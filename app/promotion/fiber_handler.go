@@ -0,0 +1,118 @@
+package promotion
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus"
+)
+
+type Handler interface {
+	SetupRoutes(fr fiber.Router)
+	GetPromotion(c *fiber.Ctx) error
+	ListPromotions(c *fiber.Ctx) error
+	CreatePromotion(c *fiber.Ctx) error
+	UpdatePromotion(c *fiber.Ctx) error
+	DeletePromotion(c *fiber.Ctx) error
+}
+
+type handler struct {
+	logger  *logrus.Logger
+	service Service
+}
+
+type NewHandlerOpts struct {
+	L *logrus.Logger
+	S Service
+}
+
+func NewHandler(opts *NewHandlerOpts) Handler {
+	return &handler{
+		logger:  opts.L,
+		service: opts.S,
+	}
+}
+
+func (h *handler) GetPromotion(c *fiber.Ctx) error {
+	promotionID := c.Params("id")
+	h.logger.Infof("Get Promotion request arrived! Promotion ID: %s", promotionID)
+
+	promotion, err := h.service.GetPromotion(c.Context(), promotionID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(promotion)
+}
+
+func (h *handler) ListPromotions(c *fiber.Ctx) error {
+	h.logger.Infof("List Promotions request arrived!")
+
+	promotions, err := h.service.ListPromotions(c.Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(promotions)
+}
+
+func (h *handler) CreatePromotion(c *fiber.Ctx) error {
+	h.logger.Infof("Create Promotion request arrived!")
+
+	var req CreatePromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	promotion, err := h.service.CreatePromotion(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(promotion)
+}
+
+func (h *handler) UpdatePromotion(c *fiber.Ctx) error {
+	promotionID := c.Params("id")
+	h.logger.Infof("Update Promotion request arrived! Promotion ID: %s", promotionID)
+
+	var req UpdatePromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return cerr.BodyParser()
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	promotion, err := h.service.UpdatePromotion(c.Context(), promotionID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(promotion)
+}
+
+func (h *handler) DeletePromotion(c *fiber.Ctx) error {
+	promotionID := c.Params("id")
+	h.logger.Infof("Delete Promotion request arrived! Promotion ID: %s", promotionID)
+
+	if err := h.service.DeletePromotion(c.Context(), promotionID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *handler) SetupRoutes(fr fiber.Router) {
+	promotionsGroup := fr.Group("/promotions")
+
+	promotionsGroup.Get("/", h.ListPromotions)
+	promotionsGroup.Get("/:id", h.GetPromotion)
+	promotionsGroup.Post("/", h.CreatePromotion)
+	promotionsGroup.Put("/:id", h.UpdatePromotion)
+	promotionsGroup.Delete("/:id", h.DeletePromotion)
+}
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package promotion is a generated GoMock package.
+package promotion
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePromotion mocks base method.
+func (m *MockRepository) CreatePromotion(ctx context.Context, promotion *Promotion) (*Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromotion", ctx, promotion)
+	ret0, _ := ret[0].(*Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromotion indicates an expected call of CreatePromotion.
+func (mr *MockRepositoryMockRecorder) CreatePromotion(ctx, promotion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromotion", reflect.TypeOf((*MockRepository)(nil).CreatePromotion), ctx, promotion)
+}
+
+// DeletePromotion mocks base method.
+func (m *MockRepository) DeletePromotion(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePromotion", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePromotion indicates an expected call of DeletePromotion.
+func (mr *MockRepositoryMockRecorder) DeletePromotion(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePromotion", reflect.TypeOf((*MockRepository)(nil).DeletePromotion), ctx, id)
+}
+
+// GetPromotion mocks base method.
+func (m *MockRepository) GetPromotion(ctx context.Context, id string) (*Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromotion", ctx, id)
+	ret0, _ := ret[0].(*Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromotion indicates an expected call of GetPromotion.
+func (mr *MockRepositoryMockRecorder) GetPromotion(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotion", reflect.TypeOf((*MockRepository)(nil).GetPromotion), ctx, id)
+}
+
+// ListPromotions mocks base method.
+func (m *MockRepository) ListPromotions(ctx context.Context) ([]Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromotions", ctx)
+	ret0, _ := ret[0].([]Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromotions indicates an expected call of ListPromotions.
+func (mr *MockRepositoryMockRecorder) ListPromotions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromotions", reflect.TypeOf((*MockRepository)(nil).ListPromotions), ctx)
+}
+
+// ListRunningPromotions mocks base method.
+func (m *MockRepository) ListRunningPromotions(ctx context.Context, at time.Time) ([]Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRunningPromotions", ctx, at)
+	ret0, _ := ret[0].([]Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRunningPromotions indicates an expected call of ListRunningPromotions.
+func (mr *MockRepositoryMockRecorder) ListRunningPromotions(ctx, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRunningPromotions", reflect.TypeOf((*MockRepository)(nil).ListRunningPromotions), ctx, at)
+}
+
+// UpdatePromotion mocks base method.
+func (m *MockRepository) UpdatePromotion(ctx context.Context, promotion *Promotion) (*Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePromotion", ctx, promotion)
+	ret0, _ := ret[0].(*Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePromotion indicates an expected call of UpdatePromotion.
+func (mr *MockRepositoryMockRecorder) UpdatePromotion(ctx, promotion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePromotion", reflect.TypeOf((*MockRepository)(nil).UpdatePromotion), ctx, promotion)
+}
@@ -0,0 +1,52 @@
+package promotion
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/app/promotion/engine"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+)
+
+// Promotion is a discount on the products in its scope while it runs, see
+// engine.Rule for how it applies. Percentage is set for percentage
+// promotions, Amount for fixed amount ones.
+type Promotion struct {
+	ID           string
+	Name         string
+	Kind         engine.Kind
+	Percentage   *big.Rat
+	Amount       money.Money
+	Stackable    bool
+	ProductIDs   []string
+	ProductTypes []string
+	Providers    []string
+	StartsAt     *time.Time
+	EndsAt       *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (p *Promotion) Rule() engine.Rule {
+	return engine.Rule{
+		ID:           p.ID,
+		Kind:         p.Kind,
+		Percentage:   p.Percentage,
+		Amount:       p.Amount,
+		Stackable:    p.Stackable,
+		ProductIDs:   p.ProductIDs,
+		ProductTypes: p.ProductTypes,
+		Providers:    p.Providers,
+		StartsAt:     p.StartsAt,
+		EndsAt:       p.EndsAt,
+	}
+}
+
+func rules(promotions []Promotion) []engine.Rule {
+	rules := make([]engine.Rule, 0, len(promotions))
+	for i := range promotions {
+		rules = append(rules, promotions[i].Rule())
+	}
+
+	return rules
+}
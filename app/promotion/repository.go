@@ -0,0 +1,17 @@
+package promotion
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate mockgen -source=repository.go -destination=mock_repository.go -package=promotion
+type Repository interface {
+	GetPromotion(ctx context.Context, id string) (*Promotion, error)
+	ListPromotions(ctx context.Context) ([]Promotion, error)
+	// ListRunningPromotions returns the promotions running at the given time.
+	ListRunningPromotions(ctx context.Context, at time.Time) ([]Promotion, error)
+	CreatePromotion(ctx context.Context, promotion *Promotion) (*Promotion, error)
+	UpdatePromotion(ctx context.Context, promotion *Promotion) (*Promotion, error)
+	DeletePromotion(ctx context.Context, id string) error
+}
@@ -0,0 +1,123 @@
+package promotion
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/app/promotion/engine"
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/pact-cdc-example/product-service/pkg/validation"
+)
+
+// maxPercentageDecimals is the precision percentages are stored with.
+const maxPercentageDecimals = 2
+
+// CreatePromotionRequest holds the percentage of percentage promotions, and
+// the amount and its currency, DefaultCurrency of products when empty, of
+// fixed amount ones. The scope lists and the period bounds are optional.
+type CreatePromotionRequest struct {
+	Name         string      `json:"name"`
+	Kind         string      `json:"kind"`
+	Percentage   json.Number `json:"percentage,omitempty"`
+	Amount       json.Number `json:"amount,omitempty"`
+	Currency     string      `json:"currency,omitempty"`
+	Stackable    bool        `json:"stackable"`
+	ProductIDs   []string    `json:"product_ids,omitempty"`
+	ProductTypes []string    `json:"product_types,omitempty"`
+	Providers    []string    `json:"providers,omitempty"`
+	StartsAt     *time.Time  `json:"starts_at,omitempty"`
+	EndsAt       *time.Time  `json:"ends_at,omitempty"`
+}
+
+func (c CreatePromotionRequest) Validate() error {
+	v := validation.New()
+
+	v.Check(strings.TrimSpace(c.Name) != "", "name",
+		PromotionNameIsRequired, "Promotion name is required.")
+	switch engine.Kind(c.Kind) {
+	case engine.Percentage:
+		_, err := parsePercentage(c.Percentage)
+		v.Check(err == nil, "percentage", InvalidPromotionPercentage, fmt.Sprintf(
+			"Percentage must be above 0 and at most 100 with at most %d decimal places.",
+			maxPercentageDecimals))
+	case engine.FixedAmount:
+		amount, err := c.amount()
+		v.Check(err == nil && amount.Amount > 0, "amount", InvalidPromotionAmount,
+			"Amount must be a positive price in the currency of the promotion.")
+	default:
+		v.Add("kind", InvalidPromotionKind, fmt.Sprintf("Promotion kind must be one of %s, %s.",
+			engine.Percentage, engine.FixedAmount))
+	}
+	v.Check(c.StartsAt == nil || c.EndsAt == nil || c.EndsAt.After(*c.StartsAt), "ends_at",
+		InvalidPromotionPeriod, "Promotion must end after it starts.")
+
+	return v.Err(InvalidPromotionRequest, "Promotion request is invalid.")
+}
+
+// promotion creates the promotion of a validated request.
+func (c CreatePromotionRequest) promotion(id string) *Promotion {
+	p := &Promotion{
+		ID:           id,
+		Name:         c.Name,
+		Kind:         engine.Kind(c.Kind),
+		Stackable:    c.Stackable,
+		ProductIDs:   product.UniqueIDs(c.ProductIDs),
+		ProductTypes: product.UniqueIDs(c.ProductTypes),
+		Providers:    product.UniqueIDs(c.Providers),
+		StartsAt:     c.StartsAt,
+		EndsAt:       c.EndsAt,
+	}
+
+	switch p.Kind {
+	case engine.Percentage:
+		p.Percentage, _ = parsePercentage(c.Percentage)
+	case engine.FixedAmount:
+		p.Amount, _ = c.amount()
+	}
+
+	return p
+}
+
+func (c CreatePromotionRequest) amount() (money.Money, error) {
+	currency := product.DefaultCurrency
+	if c.Currency != "" {
+		var err error
+		if currency, err = money.ParseCurrency(c.Currency); err != nil {
+			return money.Money{}, err
+		}
+	}
+
+	return money.Parse(c.Amount.String(), currency)
+}
+
+// parsePercentage reads a percentage above 0 and at most 100.
+func parsePercentage(value json.Number) (*big.Rat, error) {
+	// the float parse bounds the exponent before the exact parse.
+	f, err := strconv.ParseFloat(value.String(), 64)
+	if err != nil || f <= 0 || f > 100 {
+		return nil, fmt.Errorf("invalid percentage %q", value)
+	}
+
+	percentage, ok := new(big.Rat).SetString(value.String())
+	if !ok || percentage.Sign() <= 0 || percentage.Cmp(big.NewRat(100, 1)) > 0 {
+		return nil, fmt.Errorf("invalid percentage %q", value)
+	}
+
+	scaled := new(big.Rat).Mul(percentage, big.NewRat(100, 1))
+	if !scaled.IsInt() {
+		return nil, fmt.Errorf("percentage %q is too precise", value)
+	}
+
+	return percentage, nil
+}
+
+type UpdatePromotionRequest CreatePromotionRequest
+
+func (u UpdatePromotionRequest) Validate() error {
+	return CreatePromotionRequest(u).Validate()
+}
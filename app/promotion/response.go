@@ -0,0 +1,71 @@
+package promotion
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pact-cdc-example/product-service/app/promotion/engine"
+)
+
+type PromotionResponse struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	Kind         string       `json:"kind"`
+	Percentage   *json.Number `json:"percentage,omitempty"`
+	Amount       *json.Number `json:"amount,omitempty"`
+	Currency     string       `json:"currency,omitempty"`
+	Stackable    bool         `json:"stackable"`
+	ProductIDs   []string     `json:"product_ids,omitempty"`
+	ProductTypes []string     `json:"product_types,omitempty"`
+	Providers    []string     `json:"providers,omitempty"`
+	StartsAt     *time.Time   `json:"starts_at,omitempty"`
+	EndsAt       *time.Time   `json:"ends_at,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+func NewPromotionResponse(promotion *Promotion) *PromotionResponse {
+	if promotion == nil {
+		return nil
+	}
+
+	response := &PromotionResponse{
+		ID:           promotion.ID,
+		Name:         promotion.Name,
+		Kind:         string(promotion.Kind),
+		Stackable:    promotion.Stackable,
+		ProductIDs:   promotion.ProductIDs,
+		ProductTypes: promotion.ProductTypes,
+		Providers:    promotion.Providers,
+		StartsAt:     promotion.StartsAt,
+		EndsAt:       promotion.EndsAt,
+		CreatedAt:    promotion.CreatedAt,
+		UpdatedAt:    promotion.UpdatedAt,
+	}
+
+	switch promotion.Kind {
+	case engine.Percentage:
+		if promotion.Percentage != nil {
+			percentage := json.Number(promotion.Percentage.FloatString(maxPercentageDecimals))
+			response.Percentage = &percentage
+		}
+	case engine.FixedAmount:
+		amount := json.Number(promotion.Amount.Decimal())
+		response.Amount, response.Currency = &amount, string(promotion.Amount.Currency)
+	}
+
+	return response
+}
+
+type GetPromotionsResponse struct {
+	Promotions []PromotionResponse `json:"promotions"`
+}
+
+func NewGetPromotionsResponse(promotions []Promotion) *GetPromotionsResponse {
+	responses := make([]PromotionResponse, 0, len(promotions))
+	for i := range promotions {
+		responses = append(responses, *NewPromotionResponse(&promotions[i]))
+	}
+
+	return &GetPromotionsResponse{Promotions: responses}
+}
@@ -0,0 +1,135 @@
+package promotion
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/app/promotion/engine"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus"
+)
+
+type Service interface {
+	GetPromotion(ctx context.Context, id string) (*PromotionResponse, error)
+	ListPromotions(ctx context.Context) (*GetPromotionsResponse, error)
+	CreatePromotion(ctx context.Context, req CreatePromotionRequest) (*PromotionResponse, error)
+	UpdatePromotion(
+		ctx context.Context, id string, req UpdatePromotionRequest) (*PromotionResponse, error)
+	DeletePromotion(ctx context.Context, id string) error
+	// Discount evaluates the promotions running at the given time on the
+	// items, it is how the products are discounted.
+	Discount(ctx context.Context,
+		at time.Time, items []product.DiscountItem) ([]product.Discount, error)
+}
+
+type service struct {
+	logger     *logrus.Logger
+	repository Repository
+}
+
+type NewServiceOpts struct {
+	L *logrus.Logger
+	R Repository
+}
+
+func NewService(opts *NewServiceOpts) Service {
+	return &service{
+		logger:     opts.L,
+		repository: opts.R,
+	}
+}
+
+func (s *service) GetPromotion(ctx context.Context, id string) (*PromotionResponse, error) {
+	promotion, err := s.repository.GetPromotion(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, promotionNotFound()
+	}
+
+	if err != nil {
+		s.logger.WithField("promotion_id", id).Errorf("could not get promotion: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewPromotionResponse(promotion), nil
+}
+
+func (s *service) ListPromotions(ctx context.Context) (*GetPromotionsResponse, error) {
+	promotions, err := s.repository.ListPromotions(ctx)
+	if err != nil {
+		s.logger.Errorf("could not list promotions: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewGetPromotionsResponse(promotions), nil
+}
+
+func (s *service) CreatePromotion(
+	ctx context.Context, req CreatePromotionRequest) (*PromotionResponse, error) {
+	promotion, err := s.repository.CreatePromotion(ctx, req.promotion(uuid.New().String()))
+	if err != nil {
+		s.logger.Errorf("could not create promotion: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewPromotionResponse(promotion), nil
+}
+
+func (s *service) UpdatePromotion(
+	ctx context.Context, id string, req UpdatePromotionRequest) (*PromotionResponse, error) {
+	promotion, err := s.repository.UpdatePromotion(ctx, CreatePromotionRequest(req).promotion(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, promotionNotFound()
+	}
+
+	if err != nil {
+		s.logger.WithField("promotion_id", id).Errorf("could not update promotion: %v", err)
+		return nil, cerr.Processing()
+	}
+
+	return NewPromotionResponse(promotion), nil
+}
+
+func (s *service) DeletePromotion(ctx context.Context, id string) error {
+	err := s.repository.DeletePromotion(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return promotionNotFound()
+	}
+
+	if err != nil {
+		s.logger.WithField("promotion_id", id).Errorf("could not delete promotion: %v", err)
+		return cerr.Processing()
+	}
+
+	return nil
+}
+
+func (s *service) Discount(
+	ctx context.Context, at time.Time, items []product.DiscountItem) ([]product.Discount, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	promotions, err := s.repository.ListRunningPromotions(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	running := rules(promotions)
+	discounts := make([]product.Discount, 0, len(items))
+	for _, item := range items {
+		result := engine.Evaluate(running, engine.Item{
+			ProductID:   item.ProductID,
+			ProductType: item.Type,
+			Provider:    item.Provider,
+			Price:       item.Price,
+		}, at)
+		discounts = append(discounts,
+			product.Discount{Price: result.Price, PromotionIDs: result.RuleIDs})
+	}
+
+	return discounts, nil
+}
@@ -27,10 +27,19 @@ func New() Manager {
 		panic(fmt.Sprintf("error while unmarshalling config file: %s", err))
 	}
 
+	if key := os.Getenv(adminAPIKeyEnv); key != "" {
+		config.Auth.APIKeys = append(config.Auth.APIKeys,
+			APIKey{Key: key, Role: "admin", Name: "admin"})
+	}
+
 	global = &manager{config: &config}
 	return global
 }
 
+// adminAPIKeyEnv holds an admin api key added to the configured ones, admin
+// keys are not to be committed to the config files.
+const adminAPIKeyEnv = "PRODUCT_SERVICE_ADMIN_API_KEY"
+
 const (
 	path  = "/.config"
 	local = "local"
@@ -6,6 +6,14 @@ var global Manager
 type Manager interface {
 	Server() Server
 	Postgres() Postgres
+	Auth() Auth
+	Product() Product
+	Persistence() Persistence
+	Idempotency() Idempotency
+	Inventory() Inventory
+	Currency() Currency
+	Pricing() Pricing
+	Images() Images
 }
 
 type manager struct {
@@ -19,3 +27,35 @@ func (m *manager) Server() Server {
 func (m *manager) Postgres() Postgres {
 	return m.config.Postgres
 }
+
+func (m *manager) Auth() Auth {
+	return m.config.Auth
+}
+
+func (m *manager) Product() Product {
+	return m.config.Product
+}
+
+func (m *manager) Persistence() Persistence {
+	return m.config.Persistence
+}
+
+func (m *manager) Idempotency() Idempotency {
+	return m.config.Idempotency
+}
+
+func (m *manager) Inventory() Inventory {
+	return m.config.Inventory
+}
+
+func (m *manager) Currency() Currency {
+	return m.config.Currency
+}
+
+func (m *manager) Pricing() Pricing {
+	return m.config.Pricing
+}
+
+func (m *manager) Images() Images {
+	return m.config.Images
+}
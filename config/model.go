@@ -1,9 +1,19 @@
 package config
 
+import "time"
+
 type config struct {
 	Postgres    Postgres    `mapstructure:"postgres"`
 	Server      Server      `mapstructure:"server"`
 	ExternalURL ExternalURL `mapstructure:"externalURL"`
+	Auth        Auth        `mapstructure:"auth"`
+	Product     Product     `mapstructure:"product"`
+	Persistence Persistence `mapstructure:"persistence"`
+	Idempotency Idempotency `mapstructure:"idempotency"`
+	Inventory   Inventory   `mapstructure:"inventory"`
+	Currency    Currency    `mapstructure:"currency"`
+	Pricing     Pricing     `mapstructure:"pricing"`
+	Images      Images      `mapstructure:"images"`
 }
 
 type Postgres struct {
@@ -12,12 +22,94 @@ type Postgres struct {
 	Username string
 	Password string
 	DBName   string
+	// AutoMigrate applies pending schema migrations when the server boots.
+	AutoMigrate bool
 }
 
 type Server struct {
-	Port string
+	Port               string
+	ProblemDetails     bool
+	ProblemTypeBaseURI string
+	// BodyLimit is the largest request body in bytes. When zero it is 4 MiB,
+	// or enough for an image of Images.MaxSize when that is larger.
+	BodyLimit int
 }
 
 type ExternalURL struct {
 	ProductAPI string
 }
+
+type Auth struct {
+	APIKeys []APIKey
+}
+
+type APIKey struct {
+	Key  string
+	Role string
+	// Name identifies the holder of the key in price history.
+	Name string
+}
+
+type Product struct {
+	MaxBulkIDs     int
+	MaxBatchCreate int
+	// ExportTimeout bounds the product exports, 10 minutes when zero.
+	ExportTimeout time.Duration
+}
+
+const (
+	PostgresDriver = "postgres"
+	MemoryDriver   = "memory"
+)
+
+type Persistence struct {
+	// Driver selects the repository implementation, postgres or memory.
+	Driver string
+}
+
+type Idempotency struct {
+	// TTL is how long responses are replayed for an idempotency key.
+	TTL time.Duration
+}
+
+type Inventory struct {
+	// ReservationTTL is how long stock stays reserved unless the request asks
+	// for another duration, which can not exceed MaxReservationTTL.
+	ReservationTTL    time.Duration
+	MaxReservationTTL time.Duration
+}
+
+type Currency struct {
+	// Base is the currency the exchange rates are given in.
+	Base string
+	// ExchangeRates is the price of one unit of each currency in Base, e.g.
+	// {"EUR": "35.10"}. They are strings so that they are read exactly.
+	ExchangeRates map[string]string
+}
+
+// Pricing is the pricing policy of the products, the percentages and prices
+// are strings so that they are read exactly. See product.PricingPolicy.
+type Pricing struct {
+	// MinMarginPercent maps the product types to the lowest margin of their
+	// selling price over the buying price, DefaultMinMarginPercent applies
+	// to the types not listed.
+	MinMarginPercent        map[string]string
+	DefaultMinMarginPercent string
+	// MaxChangePercent limits the change of the selling price in an update.
+	MaxChangePercent string
+	// MinPrice and MaxPrice bound the selling prices by their currency.
+	MinPrice map[string]string
+	MaxPrice map[string]string
+}
+
+type Images struct {
+	// Dir is the directory the image files are stored in, they are served
+	// under the files route of the api at BaseURL.
+	Dir     string
+	BaseURL string
+	// MaxSize limits the size of the uploaded image files in bytes.
+	MaxSize int64
+	// ThumbnailSizes are the sizes of the squares the thumbnails of the
+	// images are scaled down to fit in.
+	ThumbnailSizes []int
+}
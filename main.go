@@ -1,51 +1,379 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/app/category"
+	"github.com/pact-cdc-example/product-service/app/importer"
+	"github.com/pact-cdc-example/product-service/app/inventory"
 	"github.com/pact-cdc-example/product-service/app/persistence"
 	"github.com/pact-cdc-example/product-service/app/product"
+	"github.com/pact-cdc-example/product-service/app/promotion"
 	"github.com/pact-cdc-example/product-service/config"
+	"github.com/pact-cdc-example/product-service/pkg/auth"
+	"github.com/pact-cdc-example/product-service/pkg/idempotency"
+	"github.com/pact-cdc-example/product-service/pkg/migrate"
+	"github.com/pact-cdc-example/product-service/pkg/money"
 	"github.com/pact-cdc-example/product-service/pkg/postgres"
 	"github.com/pact-cdc-example/product-service/pkg/server"
+	filestorage "github.com/pact-cdc-example/product-service/pkg/storage"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
 	c := config.New()
+	logger := logrus.New()
 
-	db := postgres.New(&postgres.NewPostgresOpts{
-		Host:     c.Postgres().Host,
-		Port:     c.Postgres().Port,
-		DBName:   c.Postgres().DBName,
-		Password: c.Postgres().Password,
-		Username: c.Postgres().Username,
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "serve":
+		serve(c, logger)
+	case "migrate":
+		runMigrate(c, logger, os.Args[2:])
+	case "import":
+		runImport(c, logger, os.Args[2:])
+	default:
+		log.Fatalf("unknown command %q, expected one of serve, migrate, import", command)
+	}
+}
+
+func serve(c config.Manager, logger *logrus.Logger) {
+	s := newStorage(c, logger)
+
+	categoryService := category.NewService(&category.NewServiceOpts{
+		R: s.categories,
+		L: logger,
 	})
 
-	logger := logrus.New()
+	inventoryService := inventory.NewService(&inventory.NewServiceOpts{
+		R:                 s.inventory,
+		L:                 logger,
+		ReservationTTL:    c.Inventory().ReservationTTL,
+		MaxReservationTTL: c.Inventory().MaxReservationTTL,
+	})
 
-	productRepository := persistence.NewPostgresRepository(&persistence.NewPostgresRepositoryOpts{
-		DB: db,
-		L:  logger,
+	promotionService := promotion.NewService(&promotion.NewServiceOpts{
+		R: s.promotions,
+		L: logger,
 	})
 
+	images, err := filestorage.NewLocal(&filestorage.NewLocalOpts{
+		Dir:     c.Images().Dir,
+		Route:   "/files",
+		BaseURL: c.Images().BaseURL,
+	})
+	if err != nil {
+		log.Fatalf("could not create image storage: %v", err)
+	}
+
 	productService := product.NewService(&product.NewServiceOpts{
-		R: productRepository,
-		L: logger,
+		R:              s.products,
+		C:              categoryService,
+		A:              inventoryService,
+		D:              promotionService,
+		P:              newPricingPolicy(c),
+		I:              images,
+		ThumbnailSizes: c.Images().ThumbnailSizes,
+		Rates:          newExchangeRates(c),
+		L:              logger,
 	})
 
 	productHandler := product.NewHandler(&product.NewHandlerOpts{
-		S: productService,
-		L: logger,
+		S:              productService,
+		L:              logger,
+		MaxBulkIDs:     c.Product().MaxBulkIDs,
+		MaxBatchCreate: c.Product().MaxBatchCreate,
+		MaxImageSize:   c.Images().MaxSize,
+		ExportTimeout:  c.Product().ExportTimeout,
+		Idempotency: idempotency.New(&idempotency.NewMiddlewareOpts{
+			Store: s.idempotency,
+			TTL:   c.Idempotency().TTL,
+			L:     logger,
+		}),
 	})
 
+	apiKeys := make(map[string]auth.Principal, len(c.Auth().APIKeys))
+	for _, apiKey := range c.Auth().APIKeys {
+		apiKeys[apiKey.Key] = auth.Principal{Name: apiKey.Name, Role: auth.Role(apiKey.Role)}
+	}
+
 	app := server.New(&server.NewServerOpts{
-		Port: c.Server().Port,
+		L:                  logger,
+		Port:               c.Server().Port,
+		ProblemDetails:     c.Server().ProblemDetails,
+		ProblemTypeBaseURI: c.Server().ProblemTypeBaseURI,
+		BodyLimit:          bodyLimit(c),
+		Middlewares: []fiber.Handler{
+			auth.New(&auth.NewMiddlewareOpts{Keys: apiKeys}),
+		},
 	}, []server.RouteHandler{
 		productHandler,
+		category.NewHandler(&category.NewHandlerOpts{
+			S: categoryService,
+			L: logger,
+		}),
+		inventory.NewHandler(&inventory.NewHandlerOpts{
+			S: inventoryService,
+			L: logger,
+		}),
+		promotion.NewHandler(&promotion.NewHandlerOpts{
+			S: promotionService,
+			L: logger,
+		}),
+		images,
 	})
 
 	if err := app.Run(); err != nil {
 		log.Fatalf("server is closed: %v", err)
 	}
 }
+
+// runMigrate handles `migrate up`, `migrate down [steps]` and `migrate status`.
+func runMigrate(c config.Manager, logger *logrus.Logger, args []string) {
+	if len(args) < 1 {
+		log.Fatalf("usage: migrate up|down [steps]|status")
+	}
+
+	ctx := context.Background()
+	migrator := newMigrator(newDB(c), logger)
+
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			log.Fatalf("could not apply migrations: %v", err)
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("invalid number of steps %q", args[1])
+			}
+		}
+
+		if err := migrator.Down(ctx, steps); err != nil {
+			log.Fatalf("could not revert migrations: %v", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("could not get migration status: %v", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied() {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		log.Fatalf("unknown migrate command %q, expected one of up, down, status", args[0])
+	}
+}
+
+// runImport handles `import [-format csv|ndjson] [-rejects file] [-batch n] file`.
+func runImport(c config.Manager, logger *logrus.Logger, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "input format, csv or ndjson (default: file extension)")
+	rejectsPath := flags.String("rejects", "rejects.csv", "file the rejected rows are written to")
+	batchSize := flags.Int("batch", importer.DefaultBatchSize, "number of products written at once")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		log.Fatalf("usage: import [-format csv|ndjson] [-rejects file] [-batch n] file")
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	input, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("could not open import file: %v", err)
+	}
+	defer input.Close()
+
+	rejects, err := os.Create(*rejectsPath)
+	if err != nil {
+		log.Fatalf("could not create rejects file: %v", err)
+	}
+	defer rejects.Close()
+
+	s := newStorage(c, logger)
+	summary, err := importer.New(&importer.NewImporterOpts{
+		R:         s.products,
+		C:         s.categories,
+		P:         newPricingPolicy(c),
+		L:         logger,
+		BatchSize: *batchSize,
+	}).Import(context.Background(), input, importer.Format(*format), rejects)
+	if summary != nil {
+		fmt.Printf("rows: %d, imported: %d, rejected: %d\n",
+			summary.Rows, summary.Imported, summary.Rejected)
+		if summary.Rejected > 0 {
+			fmt.Printf("rejected rows are written to %s\n", *rejectsPath)
+		}
+	}
+
+	if err != nil {
+		log.Fatalf("import failed: %v", err)
+	}
+}
+
+// storage holds the persistence backends selected by the persistence driver.
+type storage struct {
+	products    product.Repository
+	categories  category.Repository
+	inventory   inventory.Repository
+	promotions  promotion.Repository
+	idempotency idempotency.Store
+}
+
+// newStorage builds the backends of the configured persistence driver,
+// migrating the database first when configured to.
+func newStorage(c config.Manager, logger *logrus.Logger) storage {
+	switch c.Persistence().Driver {
+	case config.MemoryDriver:
+		products := persistence.NewMemoryRepository()
+		return storage{
+			products:    products,
+			categories:  persistence.NewMemoryCategoryRepository(products),
+			inventory:   persistence.NewMemoryInventoryRepository(products),
+			promotions:  persistence.NewMemoryPromotionRepository(),
+			idempotency: idempotency.NewMemoryStore(),
+		}
+	case config.PostgresDriver, "":
+	default:
+		log.Fatalf("unknown persistence driver %q", c.Persistence().Driver)
+	}
+
+	db := newDB(c)
+
+	if c.Postgres().AutoMigrate {
+		if err := newMigrator(db, logger).Up(context.Background()); err != nil {
+			log.Fatalf("could not migrate database: %v", err)
+		}
+	}
+
+	return storage{
+		products: persistence.NewPostgresRepository(&persistence.NewPostgresRepositoryOpts{
+			DB: db,
+			L:  logger,
+		}),
+		categories: persistence.NewPostgresCategoryRepository(
+			&persistence.NewPostgresCategoryRepositoryOpts{
+				DB: db,
+				L:  logger,
+			}),
+		inventory: persistence.NewPostgresInventoryRepository(
+			&persistence.NewPostgresInventoryRepositoryOpts{
+				DB: db,
+				L:  logger,
+			}),
+		promotions: persistence.NewPostgresPromotionRepository(
+			&persistence.NewPostgresPromotionRepositoryOpts{
+				DB: db,
+				L:  logger,
+			}),
+		idempotency: idempotency.NewPostgresStore(&idempotency.NewPostgresStoreOpts{
+			DB: db,
+			L:  logger,
+		}),
+	}
+}
+
+// newExchangeRates returns the configured exchange rates, given in the
+// default product currency unless configured otherwise.
+func newExchangeRates(c config.Manager) money.RateSource {
+	base := product.DefaultCurrency
+	if c.Currency().Base != "" {
+		var err error
+		if base, err = money.ParseCurrency(c.Currency().Base); err != nil {
+			log.Fatalf("invalid base currency %q: %v", c.Currency().Base, err)
+		}
+	}
+
+	rates, err := money.NewStaticRates(base, c.Currency().ExchangeRates)
+	if err != nil {
+		log.Fatalf("invalid exchange rates: %v", err)
+	}
+
+	return rates
+}
+
+// multipartOverhead is the room left in request bodies for the boundaries and
+// headers of the multipart form of an image upload.
+const multipartOverhead = 64 << 10
+
+// bodyLimit returns the configured body limit, by default fiber's one raised
+// to fit the largest image upload.
+func bodyLimit(c config.Manager) int {
+	if c.Server().BodyLimit != 0 {
+		return c.Server().BodyLimit
+	}
+
+	maxImageSize := c.Images().MaxSize
+	if maxImageSize == 0 {
+		maxImageSize = product.DefaultMaxImageSize
+	}
+
+	if limit := int(maxImageSize) + multipartOverhead; limit > fiber.DefaultBodyLimit {
+		return limit
+	}
+
+	return fiber.DefaultBodyLimit
+}
+
+func newPricingPolicy(c config.Manager) *product.PricingPolicy {
+	policy, err := product.NewPricingPolicy(&product.NewPricingPolicyOpts{
+		MinMarginPercent:        c.Pricing().MinMarginPercent,
+		DefaultMinMarginPercent: c.Pricing().DefaultMinMarginPercent,
+		MaxChangePercent:        c.Pricing().MaxChangePercent,
+		MinPrice:                c.Pricing().MinPrice,
+		MaxPrice:                c.Pricing().MaxPrice,
+	})
+	if err != nil {
+		log.Fatalf("invalid pricing policy: %v", err)
+	}
+
+	return policy
+}
+
+func newDB(c config.Manager) *sql.DB {
+	return postgres.New(&postgres.NewPostgresOpts{
+		Host:     c.Postgres().Host,
+		Port:     c.Postgres().Port,
+		DBName:   c.Postgres().DBName,
+		Password: c.Postgres().Password,
+		Username: c.Postgres().Username,
+	})
+}
+
+func newMigrator(db *sql.DB, logger *logrus.Logger) migrate.Migrator {
+	return migrate.New(&migrate.NewMigratorOpts{
+		DB: db,
+		FS: persistence.Migrations(),
+		L:  logger,
+	})
+}
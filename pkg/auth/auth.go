@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+)
+
+type Role string
+
+const (
+	RoleAnonymous Role = "anonymous"
+	RoleAdmin     Role = "admin"
+)
+
+const (
+	HeaderAPIKey      = "X-API-Key"
+	roleLocalKey      = "auth.role"
+	principalLocalKey = "auth.principal"
+)
+
+// Principal is the holder of an api key.
+type Principal struct {
+	// Name identifies the holder in the changes they make, e.g. price history.
+	Name string
+	Role Role
+}
+
+type NewMiddlewareOpts struct {
+	// Keys maps the accepted api keys to their holders.
+	Keys map[string]Principal
+}
+
+// New returns a middleware resolving the caller role from the api key header.
+// Requests without a key are served as anonymous, requests with an unknown
+// key are rejected.
+func New(opts *NewMiddlewareOpts) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderAPIKey)
+		if key == "" {
+			c.Locals(roleLocalKey, RoleAnonymous)
+			return c.Next()
+		}
+
+		principal, ok := opts.Keys[key]
+		if !ok {
+			return cerr.Unauthorized()
+		}
+
+		c.Locals(roleLocalKey, principal.Role)
+		c.Locals(principalLocalKey, principal.Name)
+		return c.Next()
+	}
+}
+
+// RequireAdmin serves the route to admins only, anonymous callers are asked
+// for an api key and the other ones are forbidden.
+func RequireAdmin(c *fiber.Ctx) error {
+	switch RoleOf(c) {
+	case RoleAdmin:
+		return c.Next()
+	case RoleAnonymous:
+		return cerr.APIKeyRequired()
+	default:
+		return cerr.Forbidden()
+	}
+}
+
+func RoleOf(c *fiber.Ctx) Role {
+	role, ok := c.Locals(roleLocalKey).(Role)
+	if !ok {
+		return RoleAnonymous
+	}
+
+	return role
+}
+
+func IsAdmin(c *fiber.Ctx) bool {
+	return RoleOf(c) == RoleAdmin
+}
+
+// NameFrom returns the name of the caller of the request ctx belongs to, empty
+// for anonymous callers. It works on the request context of fiber handlers,
+// c.Context(), which exposes the locals as its values.
+func NameFrom(ctx context.Context) string {
+	name, _ := ctx.Value(principalLocalKey).(string)
+	return name
+}
+
+// RoleFrom returns the role of the caller of the request ctx belongs to, see
+// NameFrom.
+func RoleFrom(ctx context.Context) Role {
+	role, ok := ctx.Value(roleLocalKey).(Role)
+	if !ok {
+		return RoleAnonymous
+	}
+
+	return role
+}
+
+// NewContext returns a copy of ctx carrying the principal as the caller, the
+// way the middleware does for the request context of fiber handlers. It lets
+// services be called on behalf of a principal outside of requests, e.g. in
+// tests.
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	ctx = context.WithValue(ctx, roleLocalKey, principal.Role)
+	return context.WithValue(ctx, principalLocalKey, principal.Name)
+}
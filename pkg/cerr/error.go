@@ -1,10 +1,46 @@
 package cerr
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"net/http"
+)
 
 type Bag struct {
 	Code    Code   `json:"code"`
 	Message string `json:"message"`
+	// Details carries structured, error specific information for clients.
+	Details map[string]interface{} `json:"details,omitempty"`
+	// Errors lists the field level violations of an invalid request.
+	Errors []FieldError `json:"errors,omitempty"`
+	// status overrides the status code registered for the code, see WithStatus.
+	status int
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+// Status returns the http status code registered for the bag's code,
+// 400 Bad Request when there is none.
+func (b Bag) Status() int {
+	if b.status != 0 {
+		return b.status
+	}
+
+	if status, ok := statusCodes[b.Code]; ok {
+		return status
+	}
+
+	return http.StatusBadRequest
+}
+
+// WithStatus returns the bag responded with the given status code instead of
+// the registered one, e.g. the status a published contract of a route expects.
+func (b Bag) WithStatus(status int) Bag {
+	b.status = status
+	return b
 }
 
 func (b Bag) Error() string {
@@ -21,10 +57,37 @@ type Code int
 // common response errors
 
 const (
-	BodyParserErrCode Code = 10001
-	ProcessingErrCode Code = 10002
+	BodyParserErrCode   Code = 10001
+	ProcessingErrCode   Code = 10002
+	UnauthorizedErrCode Code = 10003
+
+	InvalidIdempotencyKeyErrCode       Code = 10004
+	IdempotencyKeyReusedErrCode        Code = 10005
+	IdempotentRequestInProgressErrCode Code = 10006
+
+	ForbiddenErrCode Code = 10007
 )
 
+var statusCodes = map[Code]int{
+	BodyParserErrCode:   http.StatusBadRequest,
+	ProcessingErrCode:   http.StatusInternalServerError,
+	UnauthorizedErrCode: http.StatusUnauthorized,
+
+	InvalidIdempotencyKeyErrCode:       http.StatusBadRequest,
+	IdempotencyKeyReusedErrCode:        http.StatusUnprocessableEntity,
+	IdempotentRequestInProgressErrCode: http.StatusConflict,
+
+	ForbiddenErrCode: http.StatusForbidden,
+}
+
+// RegisterStatus maps the given codes to the http status code their bags are
+// responded with. It is meant to be called from package init functions.
+func RegisterStatus(status int, codes ...Code) {
+	for _, code := range codes {
+		statusCodes[code] = status
+	}
+}
+
 func BodyParser() Bag {
 	return Bag{
 		Code:    BodyParserErrCode,
@@ -38,3 +101,45 @@ func Processing() Bag {
 		Message: "Error occurred when processing the request.",
 	}
 }
+
+func Unauthorized() Bag {
+	return Bag{
+		Code:    UnauthorizedErrCode,
+		Message: "Invalid api key.",
+	}
+}
+
+func APIKeyRequired() Bag {
+	return Bag{
+		Code:    UnauthorizedErrCode,
+		Message: "Api key is required.",
+	}
+}
+
+func Forbidden() Bag {
+	return Bag{
+		Code:    ForbiddenErrCode,
+		Message: "Api key is not allowed to perform the request.",
+	}
+}
+
+func InvalidIdempotencyKey() Bag {
+	return Bag{
+		Code:    InvalidIdempotencyKeyErrCode,
+		Message: "Idempotency key must be at most 255 characters.",
+	}
+}
+
+func IdempotencyKeyReused() Bag {
+	return Bag{
+		Code:    IdempotencyKeyReusedErrCode,
+		Message: "Idempotency key was already used for a different request.",
+	}
+}
+
+func IdempotentRequestInProgress() Bag {
+	return Bag{
+		Code:    IdempotentRequestInProgressErrCode,
+		Message: "A request with the same idempotency key is still being processed.",
+	}
+}
@@ -0,0 +1,41 @@
+package cerr_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBagStatus(t *testing.T) {
+	cerr.RegisterStatus(http.StatusTeapot, 99001, 99002)
+
+	cases := []struct {
+		name     string
+		bag      cerr.Bag
+		expected int
+	}{
+		{"common code", cerr.Processing(), http.StatusInternalServerError},
+		{"registered code", cerr.Bag{Code: 99001}, http.StatusTeapot},
+		{"another registered code", cerr.Bag{Code: 99002}, http.StatusTeapot},
+		{"unregistered code", cerr.Bag{Code: 99003}, http.StatusBadRequest},
+		{"overridden status", cerr.Bag{Code: 99001}.WithStatus(http.StatusBadRequest),
+			http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.bag.Status())
+			assert.Equal(t, c.expected, c.bag.Problem("", "").Status)
+		})
+	}
+}
+
+func TestBagWithStatusKeepsBag(t *testing.T) {
+	bag := cerr.Bag{Code: 99001, Message: "Teapot."}
+
+	assert.Equal(t, `{"code":99001,"message":"Teapot."}`,
+		bag.WithStatus(http.StatusBadRequest).Error())
+	assert.Equal(t, http.StatusTeapot, bag.Status())
+}
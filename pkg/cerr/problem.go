@@ -0,0 +1,44 @@
+package cerr
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Problem is the RFC 7807 problem details representation of a Bag. The bag
+// code, details and field errors are kept as extension members.
+type Problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty"`
+	Code     Code                   `json:"code"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	Errors   []FieldError           `json:"errors,omitempty"`
+}
+
+// Problem converts the bag to a problem document. The problem type is the bag
+// code under typeBaseURI, or about:blank when no base uri is given.
+func (b Bag) Problem(typeBaseURI, instance string) Problem {
+	problemType := "about:blank"
+	if typeBaseURI != "" {
+		problemType = fmt.Sprintf("%s/%d", strings.TrimSuffix(typeBaseURI, "/"), b.Code)
+	}
+
+	status := b.Status()
+
+	return Problem{
+		Type:     problemType,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   b.Message,
+		Instance: instance,
+		Code:     b.Code,
+		Details:  b.Details,
+		Errors:   b.Errors,
+	}
+}
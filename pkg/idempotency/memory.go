@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+	// purgedAt is when the expired records were deleted last.
+	purgedAt time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		records: make(map[string]Record),
+	}
+}
+
+func (ms *memoryStore) Reserve(ctx context.Context, record Record) (*Record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now()
+	ms.purge(now)
+
+	if existing, ok := ms.records[record.Key]; ok && existing.ExpiresAt.After(now) {
+		return &existing, nil
+	}
+
+	ms.records[record.Key] = record
+	return nil, nil
+}
+
+func (ms *memoryStore) Complete(ctx context.Context, record Record) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	record.Completed = true
+	ms.records[record.Key] = record
+
+	return nil
+}
+
+func (ms *memoryStore) Release(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.records, key)
+	return nil
+}
+
+// purge deletes the expired records, at most once per purgeInterval. It is
+// called with the lock held.
+func (ms *memoryStore) purge(now time.Time) {
+	if now.Sub(ms.purgedAt) < purgeInterval {
+		return
+	}
+
+	ms.purgedAt = now
+	for key, record := range ms.records {
+		if !record.ExpiresAt.After(now) {
+			delete(ms.records, key)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorePurgesExpiredRecords(t *testing.T) {
+	store := NewMemoryStore().(*memoryStore)
+	ctx := context.Background()
+
+	_, err := store.Reserve(ctx, Record{Key: "expired", ExpiresAt: time.Now().Add(time.Millisecond)})
+	require.NoError(t, err)
+	_, err = store.Reserve(ctx, Record{Key: "live", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+
+	// the records are purged once per purgeInterval.
+	_, err = store.Reserve(ctx, Record{Key: "other", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Contains(t, store.records, "expired")
+
+	store.purgedAt = time.Now().Add(-purgeInterval)
+	_, err = store.Reserve(ctx, Record{Key: "another", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.NotContains(t, store.records, "expired")
+	assert.Contains(t, store.records, "live")
+}
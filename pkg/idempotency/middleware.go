@@ -0,0 +1,129 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/pkg/auth"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+	maxKeyLength         = 255
+	DefaultTTL           = 24 * time.Hour
+)
+
+type NewMiddlewareOpts struct {
+	Store Store
+	// TTL is how long a response is replayed for its key, DefaultTTL when zero.
+	TTL time.Duration
+	L   *logrus.Logger
+}
+
+// New returns a middleware making requests with an Idempotency-Key header
+// safe to retry. The first successful response of a key is stored and
+// replayed for retries carrying the same request, while reusing the key for a
+// different request is rejected. Failed requests release their key. Keys are
+// scoped to the api key holder sending them, so that callers can not replay
+// the responses of each other, anonymous callers share a scope.
+func New(opts *NewMiddlewareOpts) fiber.Handler {
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+
+		if len(key) > maxKeyLength {
+			return cerr.InvalidIdempotencyKey()
+		}
+
+		ctx := c.Context()
+		record := Record{
+			Key:         scopedKey(auth.NameFrom(ctx), key),
+			RequestHash: requestHash(c),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+
+		existing, err := opts.Store.Reserve(ctx, record)
+		if err != nil {
+			opts.L.Errorf("could not reserve idempotency key %s: %v", key, err)
+			return cerr.Processing()
+		}
+
+		if existing != nil {
+			return replay(c, record, existing)
+		}
+
+		if err = c.Next(); err != nil || !isSuccess(c.Response().StatusCode()) {
+			if releaseErr := opts.Store.Release(context.Background(), record.Key); releaseErr != nil {
+				opts.L.Errorf("could not release idempotency key %s: %v", key, releaseErr)
+			}
+
+			return err
+		}
+
+		record.Completed = true
+		record.StatusCode = c.Response().StatusCode()
+		record.ContentType = string(c.Response().Header.ContentType())
+		record.Body = append([]byte(nil), c.Response().Body()...)
+
+		if err = opts.Store.Complete(context.Background(), record); err != nil {
+			opts.L.Errorf("could not complete idempotency key %s: %v", key, err)
+		}
+
+		return nil
+	}
+}
+
+func replay(c *fiber.Ctx, record Record, existing *Record) error {
+	if existing.RequestHash != record.RequestHash {
+		return cerr.IdempotencyKeyReused()
+	}
+
+	if !existing.Completed {
+		return cerr.IdempotentRequestInProgress()
+	}
+
+	c.Status(existing.StatusCode)
+	c.Set(fiber.HeaderContentType, existing.ContentType)
+	c.Set(HeaderReplayed, "true")
+
+	return c.Send(existing.Body)
+}
+
+// scopedKey returns the key the record of the caller's idempotency key is
+// stored under. It is hashed to fit in the key column whatever the name is.
+func scopedKey(name, key string) string {
+	hash := sha256.New()
+	hash.Write([]byte(name))
+	hash.Write([]byte{0})
+	hash.Write([]byte(key))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func requestHash(c *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Path()))
+	hash.Write([]byte{0})
+	hash.Write(c.Body())
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func isSuccess(status int) bool {
+	return status >= 200 && status < 300
+}
@@ -0,0 +1,176 @@
+package idempotency_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/pkg/auth"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/idempotency"
+	"github.com/pact-cdc-example/product-service/pkg/server"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareReplaysResponse(t *testing.T) {
+	var calls int32
+	app := newApp(func(c *fiber.Ctx) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.Status(http.StatusCreated).JSON(fiber.Map{"n": n})
+	})
+
+	first := send(t, app, "key-1", "", `{"name":"a"}`)
+	assert.Equal(t, http.StatusCreated, first.status)
+	assert.Empty(t, first.replayed)
+
+	retry := send(t, app, "key-1", "", `{"name":"a"}`)
+	assert.Equal(t, http.StatusCreated, retry.status)
+	assert.Equal(t, "true", retry.replayed)
+	assert.Equal(t, first.body, retry.body)
+	assert.Equal(t, fiber.MIMEApplicationJSON, retry.contentType)
+
+	other := send(t, app, "key-2", "", `{"name":"a"}`)
+	assert.Equal(t, `{"n":2}`, other.body)
+
+	unkeyed := send(t, app, "", "", `{"name":"a"}`)
+	assert.Equal(t, `{"n":3}`, unkeyed.body)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestMiddlewareRejectsKeyReuse(t *testing.T) {
+	app := newApp(func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	send(t, app, "key-1", "", `{"name":"a"}`)
+
+	reused := send(t, app, "key-1", "", `{"name":"b"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.status)
+	assert.Contains(t, reused.body, fmt.Sprint(cerr.IdempotencyKeyReusedErrCode))
+}
+
+func TestMiddlewareRejectsRequestInProgress(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	app := newApp(func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	done := make(chan response)
+	go func() { done <- send(t, app, "key-1", "", `{"name":"a"}`) }()
+	<-started
+
+	concurrent := send(t, app, "key-1", "", `{"name":"a"}`)
+	assert.Equal(t, http.StatusConflict, concurrent.status)
+	assert.Contains(t, concurrent.body, fmt.Sprint(cerr.IdempotentRequestInProgressErrCode))
+
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-done).status)
+}
+
+func TestMiddlewareReleasesKeyOnFailure(t *testing.T) {
+	var calls int32
+	app := newApp(func(c *fiber.Ctx) error {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			return cerr.Processing()
+		case 2:
+			return c.SendStatus(http.StatusServiceUnavailable)
+		}
+
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	assert.Equal(t, http.StatusInternalServerError,
+		send(t, app, "key-1", "", `{"name":"a"}`).status)
+	assert.Equal(t, http.StatusServiceUnavailable,
+		send(t, app, "key-1", "", `{"name":"a"}`).status)
+	assert.Equal(t, http.StatusCreated, send(t, app, "key-1", "", `{"name":"a"}`).status)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestMiddlewareScopesKeysByCaller(t *testing.T) {
+	var calls int32
+	app := newApp(func(c *fiber.Ctx) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.Status(http.StatusCreated).JSON(fiber.Map{"n": n})
+	})
+
+	alice := send(t, app, "key-1", "alice-key", `{"name":"a"}`)
+	bob := send(t, app, "key-1", "bob-key", `{"name":"a"}`)
+	assert.Empty(t, bob.replayed)
+	assert.NotEqual(t, alice.body, bob.body)
+
+	bobRetry := send(t, app, "key-1", "bob-key", `{"name":"a"}`)
+	assert.Equal(t, "true", bobRetry.replayed)
+	assert.Equal(t, bob.body, bobRetry.body)
+}
+
+func TestMiddlewareRejectsLongKeys(t *testing.T) {
+	app := newApp(func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	resp := send(t, app, strings.Repeat("k", 256), "", `{}`)
+	assert.Equal(t, http.StatusBadRequest, resp.status)
+	assert.Contains(t, resp.body, fmt.Sprint(cerr.InvalidIdempotencyKeyErrCode))
+}
+
+func newApp(handler fiber.Handler) *fiber.App {
+	logger, _ := test.NewNullLogger()
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: server.NewErrorHandler(&server.NewServerOpts{L: logger}),
+	})
+	app.Use(auth.New(&auth.NewMiddlewareOpts{Keys: map[string]auth.Principal{
+		"alice-key": {Name: "alice"},
+		"bob-key":   {Name: "bob"},
+	}}))
+	app.Post("/items", idempotency.New(&idempotency.NewMiddlewareOpts{
+		Store: idempotency.NewMemoryStore(),
+		L:     logger,
+	}), handler)
+
+	return app
+}
+
+type response struct {
+	status      int
+	contentType string
+	replayed    string
+	body        string
+}
+
+func send(t *testing.T, app *fiber.App, key, apiKey, body string) response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(idempotency.HeaderIdempotencyKey, key)
+	}
+	if apiKey != "" {
+		req.Header.Set(auth.HeaderAPIKey, apiKey)
+	}
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return response{
+		status:      resp.StatusCode,
+		contentType: resp.Header.Get(fiber.HeaderContentType),
+		replayed:    resp.Header.Get(idempotency.HeaderReplayed),
+		body:        string(data),
+	}
+}
@@ -0,0 +1,134 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type postgresStore struct {
+	db     *sql.DB
+	logger *logrus.Logger
+
+	mu sync.Mutex
+	// purgedAt is when the expired records were deleted last.
+	purgedAt time.Time
+}
+
+type NewPostgresStoreOpts struct {
+	DB *sql.DB
+	L  *logrus.Logger
+}
+
+// NewPostgresStore returns a store keeping the records in the
+// idempotency_keys table.
+func NewPostgresStore(opts *NewPostgresStoreOpts) Store {
+	return &postgresStore{
+		db:     opts.DB,
+		logger: opts.L,
+	}
+}
+
+func (ps *postgresStore) Reserve(ctx context.Context, record Record) (*Record, error) {
+	ps.purge(ctx)
+
+	// an expired record is taken over as if it did not exist.
+	row := ps.db.QueryRowContext(
+		ctx,
+		`INSERT INTO idempotency_keys (key, request_hash, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET request_hash = EXCLUDED.request_hash,
+		completed = FALSE, status_code = 0, content_type = '', body = NULL,
+		expires_at = EXCLUDED.expires_at, created_at = NOW()
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING key`,
+		record.Key,
+		record.RequestHash,
+		record.ExpiresAt,
+	)
+
+	var key string
+	err := row.Scan(&key)
+	if err == nil {
+		return nil, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		ps.logger.Errorf("could not reserve idempotency key :%v", err)
+		return nil, err
+	}
+
+	row = ps.db.QueryRowContext(
+		ctx,
+		`SELECT key, request_hash, completed, status_code, content_type, body, expires_at
+		FROM idempotency_keys WHERE key = $1`,
+		record.Key,
+	)
+
+	var existing Record
+	if err = row.Scan(
+		&existing.Key,
+		&existing.RequestHash,
+		&existing.Completed,
+		&existing.StatusCode,
+		&existing.ContentType,
+		&existing.Body,
+		&existing.ExpiresAt,
+	); err != nil {
+		ps.logger.Errorf("could not scan idempotency key :%v", err)
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+func (ps *postgresStore) Complete(ctx context.Context, record Record) error {
+	_, err := ps.db.ExecContext(
+		ctx,
+		`UPDATE idempotency_keys SET completed = TRUE, status_code = $2,
+		content_type = $3, body = $4
+		WHERE key = $1`,
+		record.Key,
+		record.StatusCode,
+		record.ContentType,
+		record.Body,
+	)
+	if err != nil {
+		ps.logger.Errorf("could not complete idempotency key :%v", err)
+	}
+
+	return err
+}
+
+func (ps *postgresStore) Release(ctx context.Context, key string) error {
+	_, err := ps.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1`, key)
+	if err != nil {
+		ps.logger.Errorf("could not release idempotency key :%v", err)
+	}
+
+	return err
+}
+
+// purge deletes the expired records, at most once per purgeInterval. A
+// failure only leaves them for the next purge, so it is only logged.
+func (ps *postgresStore) purge(ctx context.Context) {
+	ps.mu.Lock()
+	due := time.Since(ps.purgedAt) >= purgeInterval
+	if due {
+		ps.purgedAt = time.Now()
+	}
+	ps.mu.Unlock()
+
+	if !due {
+		return
+	}
+
+	if _, err := ps.db.ExecContext(
+		ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`); err != nil {
+		ps.logger.Errorf("could not purge idempotency keys :%v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Record is the stored outcome of a request sent with an idempotency key.
+// Records are created incomplete while the first request is being processed.
+type Record struct {
+	// Key is the idempotency key scoped to the caller sending it.
+	Key         string
+	RequestHash string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// purgeInterval is how often the stores delete the expired records while
+// reserving keys, so that the records of keys never retried do not pile up.
+const purgeInterval = time.Minute
+
+type Store interface {
+	// Reserve saves record unless an unexpired record with the same key exists,
+	// in which case the existing record is returned instead.
+	Reserve(ctx context.Context, record Record) (*Record, error)
+	// Complete stores the response of a reserved record.
+	Complete(ctx context.Context, record Record) error
+	// Release removes a reserved record so that the key can be retried.
+	Release(ctx context.Context, key string) error
+}
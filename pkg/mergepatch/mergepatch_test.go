@@ -0,0 +1,54 @@
+package mergepatch_test
+
+import (
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/pkg/mergepatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{"replaces member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"adds member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null deletes member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"null deletes missing member", `{"a":"b"}`, `{"c":null}`, `{"a":"b"}`},
+		{"replaces array", `{"a":["b"]}`, `{"a":["c","d"]}`, `{"a":["c","d"]}`},
+		{"merges nested object", `{"a":{"b":"c","d":"e"}}`, `{"a":{"b":"f","d":null}}`,
+			`{"a":{"b":"f"}}`},
+		{"object replaces scalar", `{"a":"b"}`, `{"a":{"c":null,"d":"e"}}`, `{"a":{"d":"e"}}`},
+		{"scalar replaces object", `{"a":{"b":"c"}}`, `{"a":1}`, `{"a":1}`},
+		{"non-object patch replaces document", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"null patch replaces document", `{"a":"b"}`, `null`, `null`},
+		{"patch object replaces non-object document", `["a"]`, `{"b":"c"}`, `{"b":"c"}`},
+		{"empty patch keeps document", `{"a":"b"}`, `{}`, `{"a":"b"}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			patched, err := mergepatch.Apply([]byte(c.doc), []byte(c.patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, c.expected, string(patched))
+		})
+	}
+}
+
+func TestApplyKeepsNumbersExact(t *testing.T) {
+	patched, err := mergepatch.Apply([]byte(`{"price":"1"}`), []byte(`{"price":12.10}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"price":12.10}`, string(patched))
+}
+
+func TestApplyInvalidJSON(t *testing.T) {
+	_, err := mergepatch.Apply([]byte(`{"a":`), []byte(`{}`))
+	assert.Error(t, err)
+
+	_, err = mergepatch.Apply([]byte(`{}`), []byte(`{"a":`))
+	assert.Error(t, err)
+}
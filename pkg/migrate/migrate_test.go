@@ -0,0 +1,38 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/pact-cdc-example/product-service/pkg/migrate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	files := fstest.MapFS{
+		"0002_add_column.up.sql":     {Data: []byte("ALTER TABLE t ADD COLUMN c INT;")},
+		"0002_add_column.down.sql":   {Data: []byte("ALTER TABLE t DROP COLUMN c;")},
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (id INT);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"README.md":                  {Data: []byte("ignored")},
+	}
+
+	migrations, err := migrate.Load(files)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []migrate.Migration{
+		{Version: 1, Name: "create_table", Up: "CREATE TABLE t (id INT);", Down: "DROP TABLE t;"},
+		{Version: 2, Name: "add_column",
+			Up: "ALTER TABLE t ADD COLUMN c INT;", Down: "ALTER TABLE t DROP COLUMN c;"},
+	}, migrations)
+}
+
+func TestLoadRequiresDownScript(t *testing.T) {
+	files := fstest.MapFS{
+		"0001_create_table.up.sql": {Data: []byte("CREATE TABLE t (id INT);")},
+	}
+
+	_, err := migrate.Load(files)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package money
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Currency is an ISO 4217 alphabetic currency code.
+type Currency string
+
+// exponents maps the supported currencies to their number of minor unit
+// digits, as listed by ISO 4217.
+var exponents = map[Currency]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "AZN": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2,
+	"CHF": 2, "CLP": 0, "CNY": 2, "CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2,
+	"GEL": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JOD": 3,
+	"JPY": 0, "KRW": 0, "KWD": 3, "KZT": 2, "MXN": 2, "MYR": 2, "NOK": 2, "NZD": 2,
+	"OMR": 3, "PLN": 2, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "SAR": 2, "SEK": 2,
+	"SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "UAH": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// ParseCurrency returns the currency of the given code, ignoring its case.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if _, ok := exponents[c]; !ok {
+		return "", ErrUnknownCurrency
+	}
+
+	return c, nil
+}
+
+// Exponent is the number of minor unit digits of the currency, e.g. 2 for
+// EUR and 0 for JPY.
+func (c Currency) Exponent() int {
+	return exponents[c]
+}
+
+func (c Currency) Valid() bool {
+	_, ok := exponents[c]
+	return ok
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
@@ -0,0 +1,172 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrTooPrecise       = errors.New("amount has more fraction digits than its currency")
+	ErrCurrencyMismatch = errors.New("currencies do not match")
+)
+
+// decimalPattern matches plain and exponent decimal notation, the exponent
+// is bounded so that parsing can not be made to allocate huge numbers.
+var decimalPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]{1,3})?$`)
+
+// Money is an exact amount of a currency, kept in the minor units of the
+// currency so that arithmetic never drifts like floats do.
+type Money struct {
+	// Amount is in minor units, e.g. cents for EUR and yen for JPY.
+	Amount   int64
+	Currency Currency
+}
+
+func New(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal amount in major units, e.g. "12.50" EUR. Amounts
+// with more fraction digits than the currency has are rejected rather than
+// rounded.
+func Parse(amount string, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, ErrUnknownCurrency
+	}
+
+	amount = strings.TrimSpace(amount)
+	if !decimalPattern.MatchString(amount) {
+		return Money{}, ErrInvalidAmount
+	}
+
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return Money{}, ErrInvalidAmount
+	}
+
+	r.Mul(r, scale(currency))
+	if !r.IsInt() {
+		return Money{}, ErrTooPrecise
+	}
+
+	if !r.Num().IsInt64() {
+		return Money{}, ErrInvalidAmount
+	}
+
+	return Money{Amount: r.Num().Int64(), Currency: currency}, nil
+}
+
+// Decimal formats the amount in major units with all the minor unit digits
+// of the currency, e.g. "12.50".
+func (m Money) Decimal() string {
+	digits := strconv.FormatInt(m.Amount, 10)
+	sign := ""
+	if m.Amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	exponent := m.Currency.Exponent()
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Rat returns the amount in major units.
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), scale(m.Currency).Num())
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than o.
+func (m Money) Cmp(o Money) (int, error) {
+	if m.Currency != o.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// Convert returns the amount in the to currency, rate being the price of one
+// unit of the currency of m in the to currency. The result is rounded half
+// away from zero to the minor unit of the to currency.
+func (m Money) Convert(to Currency, rate *big.Rat) (Money, error) {
+	if !to.Valid() {
+		return Money{}, ErrUnknownCurrency
+	}
+
+	r := m.Rat()
+	r.Mul(r, rate)
+	r.Mul(r, scale(to))
+
+	amount, ok := round(r)
+	if !ok {
+		return Money{}, ErrInvalidAmount
+	}
+
+	return Money{Amount: amount, Currency: to}, nil
+}
+
+// scale is the number of minor units in one major unit of the currency.
+func scale(c Currency) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.Exponent())), nil))
+}
+
+// round rounds r half away from zero, reporting false when the result does
+// not fit an int64.
+func round(r *big.Rat) (int64, bool) {
+	num := new(big.Int).Abs(r.Num())
+	quotient, remainder := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(r.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+
+	if r.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+
+	return quotient.Int64(), quotient.IsInt64()
+}
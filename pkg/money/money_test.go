@@ -0,0 +1,99 @@
+package money_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		amount   string
+		currency money.Currency
+		expected int64
+		err      error
+	}{
+		{"12.5", "EUR", 1250, nil},
+		{"0.10", "TRY", 10, nil},
+		{"-3", "EUR", -300, nil},
+		{"1e2", "USD", 10000, nil},
+		{"1250", "JPY", 1250, nil},
+		{"1.234", "KWD", 1234, nil},
+		{"0.1", "JPY", 0, money.ErrTooPrecise},
+		{"12.345", "EUR", 0, money.ErrTooPrecise},
+		{"1/3", "EUR", 0, money.ErrInvalidAmount},
+		{"abc", "EUR", 0, money.ErrInvalidAmount},
+		{"1e300", "EUR", 0, money.ErrInvalidAmount},
+		{"1", "XXX", 0, money.ErrUnknownCurrency},
+	}
+
+	for _, c := range cases {
+		m, err := money.Parse(c.amount, c.currency)
+		if c.err != nil {
+			assert.ErrorIs(t, err, c.err, c.amount)
+			continue
+		}
+
+		require.NoError(t, err, c.amount)
+		assert.Equal(t, money.New(c.expected, c.currency), m, c.amount)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	assert.Equal(t, "12.50", money.New(1250, "EUR").Decimal())
+	assert.Equal(t, "0.05", money.New(5, "EUR").Decimal())
+	assert.Equal(t, "-0.05", money.New(-5, "EUR").Decimal())
+	assert.Equal(t, "1250", money.New(1250, "JPY").Decimal())
+	assert.Equal(t, "1.234 KWD", money.New(1234, "KWD").String())
+}
+
+func TestArithmeticIsExact(t *testing.T) {
+	total := money.New(0, "TRY")
+	for i := 0; i < 10; i++ {
+		var err error
+		total, err = total.Add(money.New(10, "TRY"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "1.00", total.Decimal())
+
+	_, err := total.Add(money.New(1, "EUR"))
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
+
+func TestConvertRoundsHalfAwayFromZero(t *testing.T) {
+	converted, err := money.New(1, "EUR").Convert("TRY", big.NewRat(1, 2))
+	require.NoError(t, err)
+	assert.Equal(t, money.New(1, "TRY"), converted)
+
+	converted, err = money.New(-1, "EUR").Convert("TRY", big.NewRat(1, 2))
+	require.NoError(t, err)
+	assert.Equal(t, money.New(-1, "TRY"), converted)
+
+	converted, err = money.New(1999, "EUR").Convert("JPY", big.NewRat(16250, 100))
+	require.NoError(t, err)
+	assert.Equal(t, money.New(3248, "JPY"), converted)
+}
+
+func TestStaticRates(t *testing.T) {
+	rates, err := money.NewStaticRates("TRY", map[string]string{"eur": "35", "USD": "32.5"})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	converted, err := money.Exchange(ctx, rates, money.New(1000, "TRY"), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, money.New(29, "EUR"), converted)
+
+	converted, err = money.Exchange(ctx, rates, money.New(6500, "USD"), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, money.New(6036, "EUR"), converted)
+
+	_, err = money.Exchange(ctx, rates, money.New(100, "TRY"), "GBP")
+	assert.ErrorIs(t, err, money.ErrRateNotFound)
+
+	_, err = money.NewStaticRates("TRY", map[string]string{"EUR": "-1"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package money
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// RateSource provides exchange rates, the price of one unit of from in the to
+// currency. Implementations may look the rates up from an external service.
+type RateSource interface {
+	Rate(ctx context.Context, from, to Currency) (*big.Rat, error)
+}
+
+// Exchange converts m to the given currency with a rate of source.
+func Exchange(ctx context.Context, source RateSource, m Money, to Currency) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	rate, err := source.Rate(ctx, m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return m.Convert(to, rate)
+}
+
+type staticRates struct {
+	base  Currency
+	rates map[Currency]*big.Rat
+}
+
+// NewStaticRates returns a RateSource of fixed rates, given as the price of
+// one unit of each currency in the base currency, e.g. {"EUR": "35.10"} for
+// a TRY base. Rates between two non base currencies are derived through the
+// base currency.
+func NewStaticRates(base Currency, rates map[string]string) (RateSource, error) {
+	if !base.Valid() {
+		return nil, ErrUnknownCurrency
+	}
+
+	s := &staticRates{base: base, rates: map[Currency]*big.Rat{base: big.NewRat(1, 1)}}
+	for code, value := range rates {
+		currency, err := ParseCurrency(code)
+		if err != nil {
+			return nil, fmt.Errorf("exchange rate of %q: %w", code, err)
+		}
+
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || !decimalPattern.MatchString(value) || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("exchange rate of %s: invalid rate %q", currency, value)
+		}
+
+		s.rates[currency] = rate
+	}
+
+	return s, nil
+}
+
+func (s *staticRates) Rate(_ context.Context, from, to Currency) (*big.Rat, error) {
+	fromRate, ok := s.rates[from]
+	if !ok {
+		return nil, ErrRateNotFound
+	}
+
+	toRate, ok := s.rates[to]
+	if !ok {
+		return nil, ErrRateNotFound
+	}
+
+	return new(big.Rat).Quo(fromRate, toRate), nil
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/sirupsen/logrus"
 )
 
 type Server interface {
@@ -16,7 +19,16 @@ type Server interface {
 }
 
 type NewServerOpts struct {
-	Port string
+	L           *logrus.Logger
+	Port        string
+	Middlewares []fiber.Handler
+	// ProblemDetails renders errors as RFC 7807 documents to the clients
+	// accepting application/problem+json.
+	ProblemDetails     bool
+	ProblemTypeBaseURI string
+	// BodyLimit is the largest request body in bytes, fiber's default of
+	// 4 MiB when zero.
+	BodyLimit int
 }
 
 type server struct {
@@ -29,10 +41,20 @@ type RouteHandler interface {
 }
 
 func New(opts *NewServerOpts, routeHandlers []RouteHandler) Server {
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: NewErrorHandler(opts),
+		BodyLimit:    opts.BodyLimit,
+		// params and bodies outlive the request when kept by the in-memory
+		// repositories, so they must not point into reused buffers.
+		Immutable: true,
+	})
 
 	app.Use(cors.New())
 
+	for _, middleware := range opts.Middlewares {
+		app.Use(middleware)
+	}
+
 	apiGroup := app.Group("/api")
 	v1Group := apiGroup.Group("/v1")
 
@@ -73,3 +95,36 @@ func (s *server) Run() error {
 
 	return s.app.Listen(fmt.Sprintf(":%s", s.opts.Port))
 }
+
+// NewErrorHandler returns the handler responding cerr.Bag errors returned from
+// handlers with their registered status code. Unknown errors are hidden behind
+// a processing error.
+func NewErrorHandler(opts *NewServerOpts) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		var bag cerr.Bag
+		var fiberErr *fiber.Error
+		switch {
+		case errors.As(err, &bag):
+		case errors.As(err, &fiberErr):
+			return fiber.DefaultErrorHandler(c, fiberErr)
+		default:
+			opts.L.WithFields(logrus.Fields{"method": c.Method(), "path": c.Path()}).
+				Errorf("unhandled error: %v", err)
+			bag = cerr.Processing()
+		}
+
+		c.Status(bag.Status())
+
+		if opts.ProblemDetails && c.Accepts(
+			fiber.MIMEApplicationJSON, cerr.MIMEApplicationProblemJSON) == cerr.MIMEApplicationProblemJSON {
+			if err = c.JSON(bag.Problem(opts.ProblemTypeBaseURI, c.OriginalURL())); err != nil {
+				return err
+			}
+
+			c.Set(fiber.HeaderContentType, cerr.MIMEApplicationProblemJSON)
+			return nil
+		}
+
+		return c.JSON(bag)
+	}
+}
@@ -0,0 +1,120 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+	"github.com/pact-cdc-example/product-service/pkg/server"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	cerr.RegisterStatus(http.StatusNotFound, 99404)
+}
+
+func TestErrorHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		accept   string
+		expected int
+		body     string
+	}{
+		{"registered bag", cerr.Bag{Code: 99404, Message: "Not found."}, "",
+			http.StatusNotFound, `{"code":99404,"message":"Not found."}`},
+		{"unregistered bag", cerr.Bag{Code: 99400, Message: "Bad."}, "",
+			http.StatusBadRequest, `{"code":99400,"message":"Bad."}`},
+		{"overridden status", cerr.Bag{Code: 99404, Message: "Not found."}.
+			WithStatus(http.StatusBadRequest), "",
+			http.StatusBadRequest, `{"code":99404,"message":"Not found."}`},
+		{"wrapped bag", fmt.Errorf("lookup: %w", cerr.Bag{Code: 99404}), "",
+			http.StatusNotFound, `{"code":99404,"message":""}`},
+		{"unknown error", errors.New("connection refused"), "",
+			http.StatusInternalServerError,
+			`{"code":10002,"message":"Error occurred when processing the request."}`},
+		{"problem document", cerr.Bag{Code: 99404, Message: "Not found."},
+			cerr.MIMEApplicationProblemJSON, http.StatusNotFound,
+			`{"type":"https://errors.example.com/99404","title":"Not Found","status":404,
+			"detail":"Not found.","instance":"/fail","code":99404}`},
+		{"problem document not accepted", cerr.Bag{Code: 99404, Message: "Not found."},
+			fiber.MIMEApplicationJSON, http.StatusNotFound,
+			`{"code":99404,"message":"Not found."}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			app := newApp(c.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			if c.accept != "" {
+				req.Header.Set(fiber.HeaderAccept, c.accept)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, c.body, string(body))
+		})
+	}
+}
+
+func TestErrorHandlerProblemContentType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(fiber.HeaderAccept, cerr.MIMEApplicationProblemJSON)
+
+	resp, err := newApp(cerr.Bag{Code: 99404}).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, cerr.MIMEApplicationProblemJSON, resp.Header.Get(fiber.HeaderContentType))
+}
+
+func TestErrorHandlerFiberError(t *testing.T) {
+	resp, err := newApp(fiber.ErrRequestEntityTooLarge).
+		Test(httptest.NewRequest(http.MethodGet, "/fail", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestErrorHandlerLogsUnknownErrors(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	app := fiber.New(fiber.Config{ErrorHandler: server.NewErrorHandler(&server.NewServerOpts{
+		L: logger,
+	})})
+	app.Get("/fail", func(c *fiber.Ctx) error { return errors.New("connection refused") })
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/fail", nil))
+	require.NoError(t, err)
+
+	var bag cerr.Bag
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&bag))
+	assert.Equal(t, cerr.ProcessingErrCode, bag.Code)
+
+	require.Len(t, hook.Entries, 1)
+	assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+	assert.Equal(t, "/fail", hook.LastEntry().Data["path"])
+	assert.Contains(t, hook.LastEntry().Message, "connection refused")
+}
+
+func newApp(handlerErr error) *fiber.App {
+	logger, _ := test.NewNullLogger()
+	app := fiber.New(fiber.Config{ErrorHandler: server.NewErrorHandler(&server.NewServerOpts{
+		L:                  logger,
+		ProblemDetails:     true,
+		ProblemTypeBaseURI: "https://errors.example.com/",
+	})})
+	app.Get("/fail", func(c *fiber.Ctx) error { return handlerErr })
+
+	return app
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Local is a Storage keeping the files in a directory of the local
+// filesystem. It serves them itself, see SetupRoutes.
+type Local interface {
+	Storage
+	SetupRoutes(fr fiber.Router)
+}
+
+type local struct {
+	dir     string
+	route   string
+	baseURL string
+}
+
+type NewLocalOpts struct {
+	// Dir is the directory the files are kept in, it is created when missing.
+	Dir string
+	// Route is the path the files are served under, e.g. "/files".
+	Route string
+	// BaseURL is the public url of Route, e.g.
+	// "http://localhost:9001/api/v1/files".
+	BaseURL string
+}
+
+func NewLocal(opts *NewLocalOpts) (Local, error) {
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &local{
+		dir:     opts.Dir,
+		route:   opts.Route,
+		baseURL: strings.TrimSuffix(opts.BaseURL, "/"),
+	}, nil
+}
+
+// path returns the path of the file of key, rejecting the keys that are not
+// a path within the directory.
+func (l *local) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes the content to a temporary file first, so that the file of key
+// is never served partially written.
+func (l *local) Put(ctx context.Context, key string, content io.Reader, _ string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func (l *local) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (l *local) URL(key string) string {
+	return l.baseURL + path.Clean("/"+key)
+}
+
+// SetupRoutes serves the files under the route of the storage.
+func (l *local) SetupRoutes(fr fiber.Router) {
+	fr.Static(l.route, l.dir, fiber.Static{
+		Browse: false,
+		MaxAge: 86400,
+	})
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrInvalidKey is returned for keys escaping the storage, e.g. "../x".
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage keeps files by their keys, slash separated paths such as
+// "products/1/images/2/original.jpg", and serves them at public urls.
+type Storage interface {
+	// Put stores the content under key, replacing the file already there.
+	Put(ctx context.Context, key string, content io.Reader, contentType string) error
+	// Delete removes the file of key, deleting a missing file is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the url the file of key is served at.
+	URL(key string) string
+}
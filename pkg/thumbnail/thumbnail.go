@@ -0,0 +1,73 @@
+package thumbnail
+
+import (
+	"image"
+	"image/color"
+)
+
+// Fit returns the image scaled down to fit in a size x size square, keeping
+// its aspect ratio. Every pixel of the thumbnail is the average of the source
+// pixels it covers. Images fitting already are returned as they are.
+func Fit(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := Dimensions(bounds.Dx(), bounds.Dy(), size)
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return src
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+			dst.Set(x, y, average(src, image.Rect(x0, y0, x1, y1)))
+		}
+	}
+
+	return dst
+}
+
+// Dimensions returns the dimensions of a width x height image scaled down to
+// fit in a size x size square, at least a pixel each.
+func Dimensions(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+
+	return max(1, width*size/height), size
+}
+
+// average returns the average color of the pixels of src within r, r
+// covering at least a pixel.
+func average(src image.Image, r image.Rectangle) color.Color {
+	var red, green, blue, alpha uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			pr, pg, pb, pa := src.At(x, y).RGBA()
+			red, green, blue, alpha = red+uint64(pr), green+uint64(pg), blue+uint64(pb), alpha+uint64(pa)
+		}
+	}
+
+	n := uint64(r.Dx() * r.Dy())
+	// the components are alpha premultiplied, so are their averages.
+	return color.RGBA64{
+		R: uint16(red / n),
+		G: uint16(green / n),
+		B: uint16(blue / n),
+		A: uint16(alpha / n),
+	}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
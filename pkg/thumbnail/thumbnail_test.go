@@ -0,0 +1,53 @@
+package thumbnail_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/pact-cdc-example/product-service/pkg/thumbnail"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDimensions(t *testing.T) {
+	cases := []struct {
+		width, height, size int
+		expectedWidth       int
+		expectedHeight      int
+	}{
+		{640, 480, 160, 160, 120},
+		{480, 640, 160, 120, 160},
+		{100, 50, 160, 100, 50},
+		{1000, 1, 100, 100, 1},
+		{500, 500, 100, 100, 100},
+	}
+
+	for _, c := range cases {
+		width, height := thumbnail.Dimensions(c.width, c.height, c.size)
+		assert.Equal(t, c.expectedWidth, width, "%dx%d in %d", c.width, c.height, c.size)
+		assert.Equal(t, c.expectedHeight, height, "%dx%d in %d", c.width, c.height, c.size)
+	}
+}
+
+func TestFit(t *testing.T) {
+	// the left half is black, the right half white.
+	src := image.NewGray(image.Rect(10, 10, 50, 30))
+	for y := 10; y < 30; y++ {
+		for x := 30; x < 50; x++ {
+			src.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+
+	thumb := thumbnail.Fit(src, 4)
+	assert.Equal(t, image.Rect(0, 0, 4, 2), thumb.Bounds())
+	assert.Equal(t, color.NRGBA{A: 255}, thumb.At(0, 0))
+	assert.Equal(t, color.NRGBA{A: 255}, thumb.At(1, 1))
+	assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, thumb.At(2, 0))
+
+	// a single pixel covering both halves is their average.
+	thumb = thumbnail.Fit(src, 1)
+	r, g, b, a := thumb.At(0, 0).RGBA()
+	assert.Equal(t, []uint32{0x7f7f, 0x7f7f, 0x7f7f, 0xffff}, []uint32{r, g, b, a})
+
+	assert.Same(t, src, thumbnail.Fit(src, 40))
+}
@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"net/url"
+
+	"github.com/pact-cdc-example/product-service/pkg/cerr"
+)
+
+// Validator collects every field violation of a request instead of stopping
+// at the first one.
+type Validator struct {
+	errors []cerr.FieldError
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+// Check records a violation on field unless ok holds.
+func (v *Validator) Check(ok bool, field string, code cerr.Code, message string) {
+	if !ok {
+		v.Add(field, code, message)
+	}
+}
+
+func (v *Validator) Add(field string, code cerr.Code, message string) {
+	v.errors = append(v.errors, cerr.FieldError{Field: field, Code: code, Message: message})
+}
+
+func (v *Validator) Valid() bool {
+	return len(v.errors) == 0
+}
+
+// Err returns a bag with the given code and message carrying the collected
+// violations, or nil when there are none.
+func (v *Validator) Err(code cerr.Code, message string) error {
+	if v.Valid() {
+		return nil
+	}
+
+	return cerr.Bag{Code: code, Message: message, Errors: v.errors}
+}
+
+// IsURL reports whether value is an absolute http or https url.
+func IsURL(value string) bool {
+	u, err := url.ParseRequestURI(value)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}